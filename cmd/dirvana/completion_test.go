@@ -7,6 +7,8 @@ import (
 	"os"
 	"testing"
 
+	dircli "github.com/NikitaCOEUR/dirvana/internal/cli"
+	"github.com/NikitaCOEUR/dirvana/internal/completion"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/urfave/cli/v3"
@@ -117,3 +119,46 @@ func TestCompletionFishScript(t *testing.T) {
 	assert.Contains(t, output, "dirvana")
 	assert.Contains(t, output, "complete")
 }
+
+func TestCompleteArgs(t *testing.T) {
+	t.Setenv("SHELL", "/bin/bash")
+	var gotArgs []string
+	complete := func(params dircli.SelfCompletionParams) []completion.Suggestion {
+		gotArgs = params.Args
+		return []completion.Suggestion{{Value: "alpha"}, {Value: "beta"}}
+	}
+
+	run := func(args ...string) string {
+		var buf bytes.Buffer
+		app := &cli.Command{
+			Name:                  "dirvana",
+			EnableShellCompletion: true,
+			Writer:                &buf,
+			Commands: []*cli.Command{
+				{
+					Name:          "allow",
+					Flags:         []cli.Flag{&cli.BoolFlag{Name: "auto-approve-shell"}},
+					ShellComplete: completeArgs("", "", complete),
+				},
+			},
+		}
+		require.NoError(t, app.Run(context.Background(), append([]string{"dirvana", "allow"}, args...)))
+		return buf.String()
+	}
+
+	t.Run("arguments from the state", func(t *testing.T) {
+		assert.Equal(t, "alpha\nbeta\n", run("--generate-shell-completion"))
+		assert.Empty(t, gotArgs)
+	})
+
+	t.Run("typed arguments are passed", func(t *testing.T) {
+		run("foo", "--generate-shell-completion")
+		assert.Equal(t, []string{"foo"}, gotArgs)
+	})
+
+	t.Run("flags when a flag is being typed", func(t *testing.T) {
+		output := run("-", "--generate-shell-completion")
+		assert.Contains(t, output, "--auto-approve-shell")
+		assert.NotContains(t, output, "alpha")
+	})
+}
@@ -6,9 +6,12 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	dircli "github.com/NikitaCOEUR/dirvana/internal/cli"
+	"github.com/NikitaCOEUR/dirvana/internal/completion"
 	"github.com/NikitaCOEUR/dirvana/internal/setup"
+	"github.com/NikitaCOEUR/dirvana/internal/shim"
 	"github.com/NikitaCOEUR/dirvana/internal/trace"
 	"github.com/NikitaCOEUR/dirvana/pkg/version"
 	"github.com/urfave/cli/v3"
@@ -78,6 +81,7 @@ func main() {
 						Usage: "Automatically approve shell commands in the config (useful for CI/CD)",
 					},
 				},
+				ShellComplete: completeArgs(cachePath, authPath, dircli.CompleteAllowArgs),
 				Action: func(_ context.Context, cmd *cli.Command) error {
 					currentDir, err := os.Getwd()
 					if err != nil {
@@ -104,8 +108,9 @@ func main() {
 				},
 			},
 			{
-				Name:  "revoke",
-				Usage: "Revoke authorization for a project",
+				Name:          "revoke",
+				Usage:         "Revoke authorization for a project",
+				ShellComplete: completeArgs(cachePath, authPath, dircli.CompleteRevokeArgs),
 				Action: func(_ context.Context, cmd *cli.Command) error {
 					currentDir, err := os.Getwd()
 					if err != nil {
@@ -213,7 +218,7 @@ func main() {
 					&cli.StringFlag{
 						Name:    "shell",
 						Value:   "auto",
-						Usage:   "Shell type: bash, zsh, or auto",
+						Usage:   "Shell type: bash, zsh, fish, nu, or auto",
 						Sources: cli.EnvVars("DIRVANA_SHELL"),
 					},
 				},
@@ -221,8 +226,13 @@ func main() {
 					shell := dircli.DetectShell(cmd.String("shell"))
 					hookCode := dircli.GenerateHookCode(shell)
 
+					rcFile := fmt.Sprintf("~/.%src", shell)
+					if shell == dircli.ShellNu {
+						rcFile = "$nu.config-path"
+					}
+
 					fmt.Println("# Add this to your shell config file:")
-					fmt.Printf("# For %s: add to ~/.%src\n\n", shell, shell)
+					fmt.Printf("# For %s: add to %s\n\n", shell, rcFile)
 					fmt.Println(hookCode)
 
 					return nil
@@ -235,7 +245,7 @@ func main() {
 					&cli.StringFlag{
 						Name:    "shell",
 						Value:   "auto",
-						Usage:   "Shell type: bash, zsh, or auto",
+						Usage:   "Shell type: bash, zsh, fish, nu, or auto",
 						Sources: cli.EnvVars("DIRVANA_SHELL"),
 					},
 					&cli.BoolFlag{
@@ -262,8 +272,13 @@ func main() {
 
 					fmt.Println(result.Message)
 					if result.Updated && !cmd.Bool("uninstall") {
-						fmt.Println("\nTo activate in current shell, run:")
-						fmt.Printf("  source %s\n", result.RCFile)
+						if shell == dircli.ShellNu {
+							// Nushell sources files when it parses its config
+							fmt.Println("\nTo activate, restart Nushell")
+						} else {
+							fmt.Println("\nTo activate in current shell, run:")
+							fmt.Printf("  source %s\n", result.RCFile)
+						}
 					}
 
 					return nil
@@ -279,6 +294,13 @@ func main() {
 						Usage:   "Clear all cache entries instead of just current directory hierarchy",
 					},
 				},
+				ShellComplete: func(ctx context.Context, cmd *cli.Command) {
+					// --all is the only argument of clean
+					if cmd.IsSet("all") {
+						return
+					}
+					completeArgs(cachePath, authPath, dircli.CompleteCleanArgs)(ctx, cmd)
+				},
 				Action: func(_ context.Context, cmd *cli.Command) error {
 					return dircli.Clean(dircli.CleanParams{
 						CachePath: cachePath,
@@ -287,6 +309,27 @@ func main() {
 					})
 				},
 			},
+			{
+				Name:      "run",
+				Usage:     "Run a command inside a directory's Dirvana environment (no shell hook required)",
+				ArgsUsage: "[--dir path] -- <command> [args...]",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:    "dir",
+						Aliases: []string{"C"},
+						Usage:   "Directory whose environment is loaded (defaults to current directory)",
+					},
+				},
+				Action: func(_ context.Context, cmd *cli.Command) error {
+					return dircli.Run(dircli.RunParams{
+						CachePath: cachePath,
+						AuthPath:  authPath,
+						LogLevel:  cmd.String("log-level"),
+						Dir:       cmd.String("dir"),
+						Command:   cmd.Args().Slice(),
+					})
+				},
+			},
 			{
 				Name:            "exec",
 				Usage:           "Execute a dirvana-managed alias or function",
@@ -294,6 +337,7 @@ func main() {
 				Hidden:          true, // Hidden from help - used internally by shell aliases
 				SkipFlagParsing: true, // Don't parse flags - pass them directly to the wrapped command
 				HideHelp:        true, // Don't show help for this internal command
+				ShellComplete:   completeArgs(cachePath, authPath, dircli.CompleteExecArgs),
 				Action: func(_ context.Context, cmd *cli.Command) error {
 					if cmd.Args().Len() == 0 {
 						return fmt.Errorf("alias name required")
@@ -303,21 +347,90 @@ func main() {
 					args := cmd.Args().Slice()[1:]
 
 					return dircli.Exec(dircli.ExecParams{
-						CachePath: cachePath,
-						AuthPath:  authPath,
-						LogLevel:  cmd.String("log-level"),
-						Alias:     alias,
-						Args:      args,
+						CachePath:  cachePath,
+						AuthPath:   authPath,
+						LogLevel:   cmd.String("log-level"),
+						Alias:      alias,
+						Args:       args,
+						ContextDir: os.Getenv(shim.ContextDirEnvVar),
+						Fallback:   os.Getenv("DIRVANA_SHELL") == dircli.ShellNu,
 					})
 				},
 			},
 			{
 				Name:            "completion",
-				Usage:           "Generate shell completions for dirvana-managed aliases",
-				ArgsUsage:       "[completion-args...]",
+				Usage:           "Generate shell completions for dirvana-managed aliases (--redetect <tool> detects the completion of a tool again; registry and bundle manage completion scripts)",
+				ArgsUsage:       "[completion-args...] | --redetect <tool> | registry list|update | bundle create|install",
 				Hidden:          true, // Hidden from help - used internally by completion functions
 				SkipFlagParsing: true, // Don't parse flags - pass them directly to the wrapped command
 				HideHelp:        true, // Don't show help for this internal command
+				Commands: []*cli.Command{
+					{
+						Name:  "registry",
+						Usage: "Manage the registries of completion scripts",
+						Commands: []*cli.Command{
+							{
+								Name:  "list",
+								Usage: "List the registries by precedence with their cached copy",
+								Action: func(_ context.Context, cmd *cli.Command) error {
+									return dircli.CompletionRegistryList(dircli.CompletionRegistryParams{
+										CachePath: cachePath,
+										LogLevel:  cmd.String("log-level"),
+									})
+								},
+							},
+							{
+								Name:  "update",
+								Usage: "Download the remote registries again",
+								Action: func(_ context.Context, cmd *cli.Command) error {
+									return dircli.CompletionRegistryUpdate(dircli.CompletionRegistryParams{
+										CachePath: cachePath,
+										LogLevel:  cmd.String("log-level"),
+									})
+								},
+							},
+						},
+					},
+					{
+						Name:  "bundle",
+						Usage: "Pack completion scripts for hosts without network access",
+						Commands: []*cli.Command{
+							{
+								Name:  "create",
+								Usage: "Pack the registries and all their scripts into an archive",
+								Flags: []cli.Flag{
+									&cli.StringFlag{
+										Name:    "output",
+										Aliases: []string{"o"},
+										Usage:   "Archive to write (default: " + completion.DefaultBundleName + ")",
+									},
+								},
+								Action: func(_ context.Context, cmd *cli.Command) error {
+									return dircli.CompletionBundleCreate(dircli.CompletionBundleParams{
+										CachePath: cachePath,
+										LogLevel:  cmd.String("log-level"),
+										File:      cmd.String("output"),
+									})
+								},
+							},
+							{
+								Name:      "install",
+								Usage:     "Install the completion scripts of an archive",
+								ArgsUsage: "<file>",
+								Action: func(_ context.Context, cmd *cli.Command) error {
+									if cmd.Args().Len() != 1 {
+										return fmt.Errorf("usage: dirvana completion bundle install <file>")
+									}
+									return dircli.CompletionBundleInstall(dircli.CompletionBundleParams{
+										CachePath: cachePath,
+										LogLevel:  cmd.String("log-level"),
+										File:      cmd.Args().First(),
+									})
+								},
+							},
+						},
+					},
+				},
 				Action: func(_ context.Context, cmd *cli.Command) error {
 					// Bash completion provides COMP_WORDS via args
 					// and COMP_CWORD via DIRVANA_COMP_CWORD env var
@@ -344,6 +457,18 @@ func main() {
 						}
 					}
 
+					// Forget the completion strategy cached for a tool and detect it again
+					if len(words) > 0 && words[0] == "--redetect" {
+						if len(words) != 2 {
+							return fmt.Errorf("usage: dirvana completion --redetect <tool>")
+						}
+						return dircli.CompletionRedetect(dircli.CompletionRedetectParams{
+							CachePath: cachePath,
+							LogLevel:  cmd.String("log-level"),
+							Tool:      words[1],
+						})
+					}
+
 					// Get COMP_CWORD from environment
 					cword := len(words) - 1 // default to last word
 					if cwordStr := os.Getenv("DIRVANA_COMP_CWORD"); cwordStr != "" {
@@ -356,6 +481,7 @@ func main() {
 						LogLevel:  cmd.String("log-level"),
 						Words:     words,
 						CWord:     cword,
+						Shell:     os.Getenv("DIRVANA_SHELL"),
 					})
 				},
 			},
@@ -367,3 +493,26 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// completeArgs returns the shell completion of a command completing its arguments from the
+// current state with complete, and its flags like urfave/cli when a flag is being typed
+func completeArgs(cachePath, authPath string, complete func(dircli.SelfCompletionParams) []completion.Suggestion) cli.ShellCompleteFunc {
+	return func(ctx context.Context, cmd *cli.Command) {
+		args := cmd.Args().Slice()
+		if len(args) > 0 && strings.HasPrefix(args[len(args)-1], "-") {
+			cli.DefaultCompleteWithFlags(ctx, cmd)
+			return
+		}
+
+		currentDir, err := os.Getwd()
+		if err != nil {
+			return
+		}
+		dircli.PrintSelfSuggestions(cmd.Root().Writer, complete(dircli.SelfCompletionParams{
+			CachePath: cachePath,
+			AuthPath:  authPath,
+			Dir:       currentDir,
+			Args:      args,
+		}))
+	}
+}
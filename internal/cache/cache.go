@@ -7,6 +7,8 @@ import (
 	"path/filepath"
 	"sync"
 	"time"
+
+	"github.com/NikitaCOEUR/dirvana/internal/config"
 )
 
 // Entry represents a cached configuration entry
@@ -31,6 +33,17 @@ type Entry struct {
 	// This stores the merged result after applying hierarchy, auth, global config, etc.
 	MergedCommandMap    map[string]string `json:"merged_command_map,omitempty"`
 	MergedCompletionMap map[string]string `json:"merged_completion_map,omitempty"`
+	// Map of alias name to its typed parameters (for completion of parameter values)
+	MergedParamsMap map[string][]config.AliasParam `json:"merged_params_map,omitempty"`
+	// Map of alias name to its custom completion sources (values, command, files, dirs)
+	MergedSourcesMap map[string]config.CompletionConfig `json:"merged_sources_map,omitempty"`
+	// Map of alias name to its completion matching mode (prefix, ignore-case, substring, fuzzy)
+	MergedMatchMap map[string]string `json:"merged_match_map,omitempty"`
+	// Maps of alias name to the arguments prepended, the environment and the working directory
+	// applied by dirvana exec, which completion applies too
+	MergedArgsMap map[string][]string          `json:"merged_args_map,omitempty"`
+	MergedEnvMap  map[string]map[string]string `json:"merged_env_map,omitempty"`
+	MergedCwdMap  map[string]string            `json:"merged_cwd_map,omitempty"`
 	// Hash of the full hierarchy (all config files that contributed to the merge)
 	// Format: "hash1:hash2:hash3:..." from root to leaf
 	HierarchyHash string `json:"hierarchy_hash,omitempty"`
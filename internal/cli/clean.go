@@ -3,9 +3,11 @@ package cli
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 
 	"github.com/NikitaCOEUR/dirvana/internal/cache"
 	"github.com/NikitaCOEUR/dirvana/internal/logger"
+	"github.com/NikitaCOEUR/dirvana/internal/shim"
 )
 
 // CleanParams holds parameters for the Clean function
@@ -30,6 +32,10 @@ func Clean(params CleanParams) error {
 		if err := c.Clear(); err != nil {
 			return fmt.Errorf("failed to clear cache: %w", err)
 		}
+		// Shims are regenerated on the next export
+		if err := shim.RemoveAll(filepath.Dir(params.CachePath)); err != nil {
+			return fmt.Errorf("failed to remove alias shims: %w", err)
+		}
 		log.Info().Msg("All cache entries cleared")
 		fmt.Println("✓ All cache entries cleared")
 	} else {
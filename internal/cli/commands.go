@@ -5,6 +5,8 @@ package cli
 // - allow_cmd.go: Allow, Revoke, and List commands
 // - init_cmd.go: Init command
 // - exec.go: Exec command (already existed)
+// - exec_direct.go: Direct (shell-less) execution of simple alias commands
+// - run.go: Run command (environment for non-interactive callers)
 // - edit.go: Edit command (already existed)
 // - completion.go: Completion command (already existed)
 // - validate.go: Validate command (already existed)
@@ -6,14 +6,20 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/NikitaCOEUR/dirvana/internal/completion"
+	"github.com/NikitaCOEUR/dirvana/internal/config"
 	"github.com/NikitaCOEUR/dirvana/internal/logger"
 	"github.com/NikitaCOEUR/dirvana/internal/trace"
 )
 
+// completionRefreshEnvVar marks a completion run in the background to refresh an expired cached result
+const completionRefreshEnvVar = "DIRVANA_COMPLETION_REFRESH"
+
 // CompletionParams contains parameters for the Completion command
 type CompletionParams struct {
 	CachePath string
@@ -21,47 +27,184 @@ type CompletionParams struct {
 	LogLevel  string
 	Words     []string // Words in the command line (COMP_WORDS)
 	CWord     int      // Index of word being completed (COMP_CWORD)
+	Shell     string   // Shell asking for completions (DIRVANA_SHELL), bash if empty
+}
+
+// aliasCompletion holds what is needed to complete an alias
+type aliasCompletion struct {
+	Command       string                   // Command the alias executes
+	CompletionCmd string                   // Command completed instead (completion override)
+	Params        []config.AliasParam      // Typed parameters
+	Source        *config.CompletionConfig // Custom completion sources, if declared
+	Match         string                   // Matching mode of the alias, the global one if empty
+	Args          []string                 // Arguments prepended to the user's (args option)
+	Env           map[string]string        // Environment of the command (env option)
+	Cwd           string                   // Working directory of the command (cwd option)
 }
 
-// resolveCompletionCommand looks up the actual command for an alias and its completion override
-func resolveCompletionCommand(aliasName, currentDir, cachePath, authPath string, log *logger.Logger) (command, completionCmd string, err error) {
+// resolveCompletionCommand looks up the actual command for an alias, its completion override,
+// its parameters and its custom completion sources
+func resolveCompletionCommand(aliasName, currentDir, cachePath, authPath string, log *logger.Logger) (*aliasCompletion, error) {
 	ctx := context.Background()
 
 	// Get merged command maps from the full hierarchy
-	var commandMap, completionMap map[string]string
+	var maps *commandMaps
+	var err error
 	trace.WithRegion(ctx, "getMergedCommandMaps", func() {
-		commandMap, completionMap, err = getMergedCommandMaps(currentDir, cachePath, authPath)
+		maps, err = getMergedCommandMaps(currentDir, cachePath, authPath)
 	})
 	if err != nil {
-		return "", "", err
+		return nil, err
 	}
 
-	if len(commandMap) == 0 {
-		return "", "", fmt.Errorf("no dirvana context")
+	if len(maps.Commands) == 0 {
+		return nil, fmt.Errorf("no dirvana context")
 	}
 
 	// Look up the actual command for this alias
-	var found bool
-	command, found = commandMap[aliasName]
+	command, found := maps.Commands[aliasName]
 	if !found {
-		return "", "", fmt.Errorf("not a dirvana-managed alias")
+		return nil, fmt.Errorf("not a dirvana-managed alias")
+	}
+
+	result := &aliasCompletion{
+		Command:       command,
+		CompletionCmd: command,
+		Params:        maps.Params[aliasName],
+		Match:         maps.Match[aliasName],
+		Args:          maps.Args[aliasName],
+		Env:           maps.Env[aliasName],
+		Cwd:           maps.Cwd[aliasName],
 	}
 
 	// Check if there's a completion override
-	completionCmd = command
-	if completionMap != nil {
-		if override, ok := completionMap[aliasName]; ok {
-			completionCmd = override
-		}
+	if override, ok := maps.Completions[aliasName]; ok {
+		result.CompletionCmd = override
+	}
+	if source, ok := maps.Sources[aliasName]; ok {
+		result.Source = &source
 	}
 
 	log.Debug().
 		Str("alias", aliasName).
-		Str("command", command).
-		Str("completion_cmd", completionCmd).
+		Str("command", result.Command).
+		Str("completion_cmd", result.CompletionCmd).
+		Bool("custom_source", result.Source != nil).
+		Str("match", result.Match).
 		Msg("Resolving completion command")
 
-	return command, completionCmd, nil
+	return result, nil
+}
+
+// completionSource converts config completion sources for the completion engine
+func completionSource(compCfg config.CompletionConfig) completion.Source {
+	source := completion.Source{
+		Command: compCfg.Command,
+		Files:   compCfg.Files,
+		Dirs:    compCfg.Dirs,
+	}
+	for _, item := range compCfg.Values {
+		source.Values = append(source.Values, completion.Suggestion{Value: item.Value, Description: item.Description})
+	}
+	return source
+}
+
+// matchMode returns the matching mode of an alias: its own, or the one of the global config
+func matchMode(aliasMatch string, settings config.CompletionSettings) completion.MatchMode {
+	if aliasMatch != "" {
+		return completion.ParseMatchMode(aliasMatch)
+	}
+	return completion.ParseMatchMode(settings.Match)
+}
+
+// completeCustomSource completes an alias with its custom completion sources.
+// Suggestions keep the order of the sources (values as declared, then command output, then paths).
+func completeCustomSource(aliasName string, compCfg config.CompletionConfig, params CompletionParams, mode completion.MatchMode, log *logger.Logger) []completion.Suggestion {
+	completer := completion.NewCustomCompleter(map[string]completion.Source{aliasName: completionSource(compCfg)})
+
+	suggestions, err := completer.Complete(aliasName, prepareCompletionArgs(params, log))
+	if err != nil {
+		log.Debug().Err(err).Msg("Custom completion failed")
+		return nil
+	}
+
+	return completion.Match(suggestions, getCurrentWord(params), mode, true)
+}
+
+// completionTarget splits a completion command into the tool to complete and the
+// arguments the alias always passes to it, which are prepended to the user's words.
+// For wrappers like 'task terraform --', which hand the words after '--' to another
+// program, the wrapped program is completed when it is installed.
+func completionTarget(completionCmd string) (string, []string) {
+	words, err := splitCommand(completionCmd)
+	if err != nil {
+		words = strings.Fields(completionCmd)
+	}
+
+	// Template placeholders are only known at execution time
+	for i, word := range words {
+		if strings.Contains(word, "{{") {
+			words = words[:i]
+			break
+		}
+	}
+
+	// Skip leading VAR=value assignments
+	for len(words) > 0 && strings.Contains(words[0], "=") {
+		words = words[1:]
+	}
+
+	if len(words) == 0 {
+		return "", nil
+	}
+
+	if sep := slices.Index(words, "--"); sep > 1 {
+		wrapped := words[sep-1]
+		if wrapped != words[0] && !strings.HasPrefix(wrapped, "-") {
+			if _, err := exec.LookPath(wrapped); err == nil {
+				return wrapped, words[sep+1:]
+			}
+		}
+	}
+
+	return words[0], words[1:]
+}
+
+// resolveCompletionTarget returns the tool to complete and the arguments to prepend
+// to the user's words: the words of the command, then the arguments of the alias args option.
+// A single-word completion override only changes the tool, so
+// 'gco: {command: git checkout, completion: git}' still completes 'git checkout <args>'.
+func resolveCompletionTarget(command, completionCmd string, aliasArgs []string) (string, []string) {
+	tool, prefixArgs := completionTarget(command)
+	if completionCmd != command {
+		overrideTool, overrideArgs := completionTarget(completionCmd)
+		if len(overrideArgs) > 0 {
+			return overrideTool, overrideArgs
+		}
+		tool = overrideTool
+	}
+
+	return tool, append(prefixArgs, aliasArgs...)
+}
+
+// applyAliasCompletionOptions applies the env and cwd options of an alias before its command
+// is asked for completions, as 'dirvana exec' does before running it. It returns the directory
+// the command is completed from.
+func applyAliasCompletionOptions(target *aliasCompletion, currentDir string) (string, error) {
+	aliasConf := config.AliasConfig{Env: target.Env, Cwd: target.Cwd}
+	for _, key := range sortedStringKeys(aliasConf.Env) {
+		if err := os.Setenv(key, aliasConf.Env[key]); err != nil {
+			return "", fmt.Errorf("failed to set environment variable %s: %w", key, err)
+		}
+	}
+
+	dir := aliasWorkDir(aliasConf, currentDir)
+	if dir != currentDir {
+		if err := os.Chdir(dir); err != nil {
+			return "", fmt.Errorf("failed to change directory to %s: %w", dir, err)
+		}
+	}
+	return dir, nil
 }
 
 // prepareCompletionArgs prepares the arguments for completion based on shell state
@@ -122,28 +265,50 @@ func Completion(params CompletionParams) error {
 	}
 
 	// Resolve the command and completion command for this alias
-	command, completionCmd, err := resolveCompletionCommand(aliasName, currentDir, params.CachePath, params.AuthPath, log)
+	target, err := resolveCompletionCommand(aliasName, currentDir, params.CachePath, params.AuthPath, log)
 	if err != nil {
 		// Failed to resolve or not a dirvana alias
 		return nil
 	}
 
-	// For functions, we don't have smart completions
-	if strings.HasPrefix(command, "__dirvana_function__") {
+	// Typed parameters are completed by dirvana itself
+	if suggestions, handled := completeAliasParam(target.Params, params); handled {
+		printSuggestions(suggestions)
+		return nil
+	}
+
+	// Custom sources declared in the config replace the tool's completion
+	if target.Source != nil {
+		mode := matchMode(target.Match, loadCompletionSettings(log))
+		printSuggestions(completeCustomSource(aliasName, *target.Source, params, mode, log))
+		return nil
+	}
+
+	// Functions are shell code: they are only completed through their completion setting
+	if strings.HasPrefix(target.Command, functionCommandPrefix) && target.CompletionCmd == target.Command {
 		return nil
 	}
 
-	// Parse the base command
-	cmdParts := strings.Fields(completionCmd)
-	if len(cmdParts) == 0 {
+	// Parse the base command and the arguments the alias always passes to it
+	baseCmd, prefixArgs := resolveCompletionTarget(target.Command, target.CompletionCmd, target.Args)
+	if baseCmd == "" {
 		return nil
 	}
 
-	baseCmd := cmdParts[0]
+	// The command is completed with the environment and in the directory it runs with
+	currentDir, err = applyAliasCompletionOptions(target, currentDir)
+	if err != nil {
+		log.Debug().Err(err).Msg("Failed to apply alias options")
+		return nil
+	}
 
 	// Create completion engine and verify command exists
 	cacheDir := filepath.Dir(params.CachePath)
+	settings := loadCompletionSettings(log)
 	engine := completion.NewEngine(cacheDir)
+	configureEngine(engine, settings)
+	engine.SetShell(params.Shell)
+	warnModifiedScript(cacheDir, baseCmd, params.Shell, settings.Scripts.Strict, log)
 
 	if !engine.HasCachedDetection(baseCmd) {
 		var lookPathErr error
@@ -158,20 +323,22 @@ func Completion(params CompletionParams) error {
 		log.Debug().Str("cmd", baseCmd).Msg("Skipping LookPath (detection cache hit)")
 	}
 
-	// Prepare completion arguments
-	args := prepareCompletionArgs(params, log)
+	// Prepare completion arguments, after the words already in the alias command
+	// (e.g. 'kgp: kubectl get pods' completes as 'kubectl get pods <args>')
+	args := append(append([]string{}, prefixArgs...), prepareCompletionArgs(params, log)...)
 	currentWord := getCurrentWord(params)
 
 	log.Debug().
 		Str("base_cmd", baseCmd).
+		Str("prefix_args", fmt.Sprintf("%q", prefixArgs)).
 		Str("current_word", currentWord).
 		Int("args_count", len(args)).
 		Msg("Starting completion")
 
-	// Get suggestions from the completion engine
+	// Get suggestions from the completion engine, or from the result cache for slow tools
 	var result *completion.Result
 	trace.WithRegion(ctx, "engine.Complete", func() {
-		result, err = engine.Complete(baseCmd, args)
+		result, err = completeWithResultCache(engine, baseCmd, params.Shell, args, currentDir, cacheDir, settings.Cache.TTLFor(baseCmd), log)
 	})
 	if err != nil {
 		log.Debug().Err(err).Msg("Completion failed")
@@ -183,26 +350,378 @@ func Completion(params CompletionParams) error {
 		Str("source", result.Source).
 		Msg("Got completions")
 
-	// Filter and sort suggestions
-	filtered := engine.Filter(result.Suggestions, currentWord)
+	// Match and rank suggestions, unless the tool asked to keep its order
+	mode := matchMode(target.Match, settings)
+	filtered := engine.Match(result, currentWord, mode)
 
 	log.Debug().
 		Int("filtered_count", len(filtered)).
 		Str("prefix", currentWord).
+		Str("match", string(mode)).
+		Bool("keep_order", result.KeepOrder()).
 		Msg("Filtered completions")
 
-	sort.Slice(filtered, func(i, j int) bool {
-		return filtered[i].Value < filtered[j].Value
+	printSuggestions(filtered)
+	printDirective(result.ShellDirective())
+
+	return nil
+}
+
+// completeWithResultCache completes with the engine, through the result cache when the
+// global config gives the tool a time to live (ttl). Results are cached without the end of the
+// current word (after the last '/' or '='), filtered afterwards, so typing more letters
+// reuses them. Expired results are returned while a background run refreshes them.
+func completeWithResultCache(engine *completion.Engine, tool, shell string, args []string, dir, cacheDir string, ttl time.Duration, log *logger.Logger) (*completion.Result, error) {
+	if ttl == 0 {
+		return engine.Complete(tool, args)
+	}
+
+	cache, err := completion.NewResultCache(filepath.Join(cacheDir, "completion-results.json"))
+	if err != nil {
+		log.Debug().Err(err).Msg("Failed to load completion result cache")
+		return engine.Complete(tool, args)
+	}
+	defer func() { _ = cache.Save() }()
+
+	cachedArgs := resultCacheArgs(args)
+	key := completion.ResultCacheKey(tool, shell, dir, cachedArgs)
+
+	if os.Getenv(completionRefreshEnvVar) == "" {
+		entry, state := cache.Get(key, ttl)
+		switch state {
+		case completion.ResultFresh:
+			return &completion.Result{Suggestions: entry.Suggestions, Source: entry.Source + " (result cache)", Directive: entry.Directive}, nil
+		case completion.ResultStale:
+			log.Debug().Str("cmd", tool).Dur("age", time.Since(entry.Timestamp)).Msg("Refreshing expired completion result")
+			if err := startCompletionRefresh(); err != nil {
+				log.Debug().Err(err).Msg("Failed to start completion refresh")
+			}
+			return &completion.Result{Suggestions: entry.Suggestions, Source: entry.Source + " (stale result cache)", Directive: entry.Directive}, nil
+		}
+	}
+
+	result, err := engine.Complete(tool, cachedArgs)
+	if err != nil {
+		return nil, err
+	}
+	cache.Set(key, result)
+	return result, nil
+}
+
+// resultCacheArgs returns the completion arguments with the current word cut after its
+// last '/' or '=', so that results for 'dir/' or '--flag=' are shared by all prefixes
+func resultCacheArgs(args []string) []string {
+	if len(args) == 0 {
+		return args
+	}
+
+	cachedArgs := append([]string{}, args...)
+	current := cachedArgs[len(cachedArgs)-1]
+	cachedArgs[len(cachedArgs)-1] = current[:strings.LastIndexAny(current, "/=")+1]
+	return cachedArgs
+}
+
+// loadCompletionSettings returns the completion settings of the global config. Registries and
+// external completers that are not valid are skipped with a warning: their names end up in
+// file names of the cache directory and in names of executables.
+func loadCompletionSettings(log *logger.Logger) config.CompletionSettings {
+	globalCfg, err := config.New().LoadGlobal()
+	if err != nil || globalCfg == nil {
+		return config.CompletionSettings{}
+	}
+
+	settings := globalCfg.Completion
+	settings.Registries = slices.DeleteFunc(slices.Clone(settings.Registries), func(registry config.CompletionRegistry) bool {
+		err := registry.Validate()
+		if err != nil {
+			log.Warn().Err(err).Msg("Skipping invalid completion registry")
+		}
+		return err != nil
+	})
+	settings.Plugins = slices.DeleteFunc(slices.Clone(settings.Plugins), func(plugin config.CompletionPlugin) bool {
+		err := plugin.Validate()
+		if err != nil {
+			log.Warn().Err(err).Msg("Skipping invalid external completer")
+		}
+		return err != nil
 	})
+	return settings
+}
+
+// configureEngine applies the settings of the global config to the engine: registries, strict
+// mode and sandbox of completion scripts, and external completers
+func configureEngine(engine *completion.Engine, settings config.CompletionSettings) {
+	engine.SetPlugins(completionPlugins(settings))
+	engine.SetRegistries(completionRegistries(settings))
+	engine.SetStrictScripts(settings.Scripts.Strict)
+
+	sandbox := settings.Scripts.Sandbox
+	engine.SetScriptSandbox(completion.ScriptSandbox{
+		Disabled:  sandbox.Disabled,
+		ReadOnly:  sandbox.ReadOnly,
+		Timeout:   sandbox.TimeoutDuration(),
+		MaxOutput: sandbox.MaxOutput,
+		Env:       sandbox.Env,
+		Tools:     sandbox.Tools,
+	})
+}
 
-	// Output suggestions
-	for _, suggestion := range filtered {
+// completionPlugins returns the external completers declared in the settings
+func completionPlugins(settings config.CompletionSettings) []completion.PluginSource {
+	plugins := make([]completion.PluginSource, 0, len(settings.Plugins))
+	for _, plugin := range settings.Plugins {
+		plugins = append(plugins, completion.PluginSource{
+			Name:    plugin.Name,
+			Path:    plugin.Path,
+			Timeout: plugin.TimeoutDuration(),
+		})
+	}
+	return plugins
+}
+
+// completionRegistries returns the registries of completion scripts by precedence.
+// The default registry is used last unless the settings list it.
+func completionRegistries(settings config.CompletionSettings) []completion.RegistrySource {
+	sources := make([]completion.RegistrySource, 0, len(settings.Registries)+1)
+	hasDefault := false
+	for _, registry := range settings.Registries {
+		source := completion.RegistrySource{Name: registry.Name, URL: registry.URL, TTL: registry.TTLDuration()}
+		if registry.Name == config.DefaultCompletionRegistry {
+			hasDefault = true
+			if source.URL == "" {
+				source.URL = completion.RegistryBaseURL
+			}
+		}
+		sources = append(sources, source)
+	}
+	if !hasDefault {
+		sources = append(sources, completion.DefaultRegistrySource())
+	}
+	return sources
+}
+
+// warnModifiedScript warns when the completion scripts downloaded for a tool changed on disk
+// since they were installed: the bash script, and the native script of a zsh or fish shell.
+// In strict mode the script is not used.
+func warnModifiedScript(cacheDir, tool, shell string, strict bool, log *logger.Logger) {
+	shells := []string{"bash"}
+	if shell == ShellZsh || shell == ShellFish {
+		shells = append(shells, shell)
+	}
+
+	for _, scriptShell := range shells {
+		integrity, err := completion.VerifyShellScript(cacheDir, filepath.Base(tool), scriptShell)
+		if err != nil {
+			log.Warn().Err(err).Msg("Failed to check completion scripts")
+			return
+		}
+		if integrity.Status != completion.ScriptModified {
+			continue
+		}
+
+		msg := "Completion script modified since it was installed"
+		if strict {
+			msg += ", not used in strict mode"
+		}
+		log.Warn().
+			Str("tool", integrity.Tool).
+			Str("shell", scriptShell).
+			Str("path", integrity.Path).
+			Str("expected", integrity.Expected).
+			Str("actual", integrity.Actual).
+			Msg(msg)
+	}
+}
+
+// startCompletionRefresh runs the same completion again in the background to refresh the
+// result cache. It is not waited for, and its output is discarded.
+var startCompletionRefresh = func() error {
+	executable, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.Command(executable, os.Args[1:]...)
+	cmd.Env = append(os.Environ(), completionRefreshEnvVar+"=1")
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	return cmd.Process.Release()
+}
+
+// CompletionRedetectParams contains parameters for the CompletionRedetect command
+type CompletionRedetectParams struct {
+	CachePath string
+	LogLevel  string
+	Tool      string
+}
+
+// CompletionRedetect forgets the completion strategy detected for a tool and detects it again
+func CompletionRedetect(params CompletionRedetectParams) error {
+	log := logger.New(params.LogLevel, os.Stderr)
+
+	path, err := exec.LookPath(params.Tool)
+	if err != nil {
+		return fmt.Errorf("command not found: %s", params.Tool)
+	}
+
+	settings := loadCompletionSettings(log)
+	engine := completion.NewEngine(filepath.Dir(params.CachePath))
+	configureEngine(engine, settings)
+	source, err := engine.Redetect(params.Tool)
+	if err != nil {
+		return err
+	}
+	log.Debug().Str("cmd", params.Tool).Str("path", path).Str("source", source).Msg("Completion detected again")
+
+	if source == "none" {
+		fmt.Printf("✗ No completion found for %s (%s)\n", params.Tool, path)
+		return nil
+	}
+	fmt.Printf("✓ %s (%s): %s\n", params.Tool, path, source)
+	return nil
+}
+
+// CompletionRegistryParams contains parameters for the completion registry commands
+type CompletionRegistryParams struct {
+	CachePath string
+	LogLevel  string
+}
+
+// CompletionRegistryList prints the registries of completion scripts by precedence,
+// with the state of their local copy. Nothing is downloaded.
+func CompletionRegistryList(params CompletionRegistryParams) error {
+	log := logger.New(params.LogLevel, os.Stderr)
+
+	sources := completionRegistries(loadCompletionSettings(log))
+	printRegistryStates(completion.RegistryStates(filepath.Dir(params.CachePath), sources))
+	return nil
+}
+
+// CompletionRegistryUpdate downloads all remote registries again, ignoring their time to live
+func CompletionRegistryUpdate(params CompletionRegistryParams) error {
+	log := logger.New(params.LogLevel, os.Stderr)
+
+	sources := completionRegistries(loadCompletionSettings(log))
+	states := completion.UpdateRegistries(filepath.Dir(params.CachePath), sources)
+	printRegistryStates(states)
+
+	failed := 0
+	for _, state := range states {
+		if state.Err != nil {
+			log.Debug().Str("registry", state.Source.Name).Err(state.Err).Msg("Failed to update registry")
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d registries could not be updated", failed, len(states))
+	}
+	return nil
+}
+
+// CompletionBundleParams contains parameters for the completion bundle commands
+type CompletionBundleParams struct {
+	CachePath string
+	LogLevel  string
+	File      string // Bundle to create or install
+}
+
+// CompletionBundleCreate packs the registries and their completion scripts into a bundle
+// that can be installed on hosts without network access
+func CompletionBundleCreate(params CompletionBundleParams) error {
+	log := logger.New(params.LogLevel, os.Stderr)
+
+	output := params.File
+	if output == "" {
+		output = completion.DefaultBundleName
+	}
+
+	settings := loadCompletionSettings(log)
+	result, err := completion.CreateBundle(filepath.Dir(params.CachePath), completionRegistries(settings), output, settings.Scripts.Strict)
+	if err != nil {
+		return err
+	}
+	log.Debug().Str("bundle", output).Int("scripts", len(result.Tools)).Msg("Completion bundle created")
+
+	for _, name := range sortedKeys(result.Registries) {
+		fmt.Printf("✗ registry %s: %v\n", name, result.Registries[name])
+	}
+	printSkippedTools(result.Skipped)
+	fmt.Printf("✓ Bundle created: %s (%d scripts)\n", output, len(result.Tools))
+	return nil
+}
+
+// CompletionBundleInstall installs the completion scripts of a bundle in the cache directory
+func CompletionBundleInstall(params CompletionBundleParams) error {
+	log := logger.New(params.LogLevel, os.Stderr)
+
+	settings := loadCompletionSettings(log)
+	result, err := completion.InstallBundle(filepath.Dir(params.CachePath), params.File, settings.Scripts.Strict)
+	if err != nil {
+		return err
+	}
+	log.Debug().Str("bundle", params.File).Int("scripts", len(result.Tools)).Msg("Completion bundle installed")
+
+	printSkippedTools(result.Skipped)
+	fmt.Printf("✓ Installed %d completion scripts from %s\n", len(result.Tools), params.File)
+	return nil
+}
+
+// printSkippedTools prints the tools left out of a bundle operation, sorted
+func printSkippedTools(skipped map[string]error) {
+	for _, tool := range sortedKeys(skipped) {
+		fmt.Printf("✗ %s: %v\n", tool, skipped[tool])
+	}
+}
+
+// sortedKeys returns the keys of a map of errors, sorted
+func sortedKeys(errs map[string]error) []string {
+	keys := make([]string, 0, len(errs))
+	for key := range errs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// printRegistryStates prints one line per registry, in precedence order
+func printRegistryStates(states []completion.RegistryState) {
+	for i, state := range states {
+		source := state.Source
+		if state.Err != nil {
+			fmt.Printf("✗ %d. %s (%s): %v\n", i+1, source.Name, source.URL, state.Err)
+			continue
+		}
+
+		fmt.Printf("✓ %d. %s (%s): %d tools\n", i+1, source.Name, source.URL, state.Tools)
+		if source.IsLocal() {
+			fmt.Printf("     %s\n", state.Path)
+			continue
+		}
+		ttl := source.TTL
+		if ttl == 0 {
+			ttl = completion.RegistryTTL
+		}
+		fmt.Printf("     %s, updated %s (ttl %s)\n", state.Path, state.UpdatedAt.Format("2006-01-02 15:04"), ttl)
+	}
+}
+
+// printDirective outputs the Cobra directive of the tool as the last line, ':<directive>' like
+// Cobra's __complete, for the shell scripts to apply (no space, no file fallback, keep order).
+// Nothing is printed without directive.
+func printDirective(directive int) {
+	if directive != 0 {
+		fmt.Printf(":%d\n", directive)
+	}
+}
+
+// printSuggestions outputs suggestions in the format expected by the shell scripts
+func printSuggestions(suggestions []completion.Suggestion) {
+	for _, suggestion := range suggestions {
 		if suggestion.Description != "" {
 			fmt.Printf("%s\t%s\n", suggestion.Value, suggestion.Description)
 		} else {
 			fmt.Println(suggestion.Value)
 		}
 	}
-
-	return nil
 }
@@ -2,14 +2,21 @@ package cli
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/NikitaCOEUR/dirvana/internal/cache"
+	"github.com/NikitaCOEUR/dirvana/internal/completion"
+	"github.com/NikitaCOEUR/dirvana/internal/config"
+	"github.com/NikitaCOEUR/dirvana/internal/logger"
 	"github.com/NikitaCOEUR/dirvana/pkg/version"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -552,3 +559,730 @@ printf ":4\n"
 	assert.Contains(t, output, "create\tCreate a resource from a file")
 	assert.Contains(t, output, "delete\tDelete resources by filenames")
 }
+
+func TestCompletion_OutputsDirective(t *testing.T) {
+	tmpDir := t.TempDir()
+	cachePath := filepath.Join(tmpDir, "cache.json")
+	workDir := filepath.Join(tmpDir, "work")
+	require.NoError(t, os.MkdirAll(filepath.Join(workDir, "charts"), 0755))
+
+	// Mock Cobra command: keep order and no space for 'set', directories for 'cd'
+	mockScript := `#!/bin/bash
+[ "$1" = "__complete" ] || exit 1
+case "$2" in
+  set) printf 'zeta=\nalpha=\n:38\n' ;;
+  cd) printf ':16\n' ;;
+  *) printf 'get\n:0\n' ;;
+esac
+`
+	scriptPath := filepath.Join(tmpDir, "mockdirective")
+	require.NoError(t, os.WriteFile(scriptPath, []byte(mockScript), 0755))
+
+	c, err := cache.New(cachePath)
+	require.NoError(t, err)
+	require.NoError(t, c.Set(&cache.Entry{
+		Path:             workDir,
+		Hash:             "hash1",
+		Timestamp:        time.Now(),
+		Version:          version.Version,
+		HierarchyHash:    "hash1",
+		MergedCommandMap: map[string]string{"md": scriptPath},
+	}))
+	t.Chdir(workDir)
+
+	complete := func(words ...string) string {
+		return captureOutput(t, func() error {
+			return Completion(CompletionParams{
+				CachePath: cachePath,
+				LogLevel:  "error",
+				Words:     append([]string{"md"}, words...),
+				CWord:     len(words),
+			})
+		})
+	}
+
+	// The order of the tool is kept and the directive is the last line
+	assert.Equal(t, "zeta=\nalpha=\n:38\n", complete("set", ""))
+	// Directories are listed by dirvana: the filter is not passed to the shell
+	assert.Equal(t, "charts/\n", complete("cd", ""))
+	// Nothing is printed without directive
+	assert.Equal(t, "get\n", complete("other", ""))
+}
+
+func TestCompletionTarget(t *testing.T) {
+	// Fake wrapped program on PATH
+	binDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(binDir, "terraform"), []byte("#!/bin/sh\n"), 0755))
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	tests := []struct {
+		command      string
+		expectedTool string
+		expectedArgs []string
+	}{
+		{"kubectl", "kubectl", []string{}},
+		{"kubectl get pods -n prod", "kubectl", []string{"get", "pods", "-n", "prod"}},
+		{`git commit -m "wip: x"`, "git", []string{"commit", "-m", "wip: x"}},
+		{"KUBECONFIG=/tmp/k kubectl get", "kubectl", []string{"get"}},
+		{"helm upgrade {{.Args.0}} ./charts", "helm", []string{"upgrade"}},
+		{"task terraform --", "terraform", []string{}},
+		{"task terraform -- plan -var-file=prod.tfvars", "terraform", []string{"plan", "-var-file=prod.tfvars"}},
+		{"task dirvana-missing-tool --", "task", []string{"dirvana-missing-tool", "--"}},
+		{"kubectl exec --", "kubectl", []string{"exec", "--"}},
+		{"", "", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			tool, args := completionTarget(tt.command)
+			assert.Equal(t, tt.expectedTool, tool)
+			if tt.expectedArgs == nil {
+				assert.Empty(t, args)
+			} else {
+				assert.Equal(t, tt.expectedArgs, append([]string{}, args...))
+			}
+		})
+	}
+}
+
+func TestCompletion_PrependsAliasWords(t *testing.T) {
+	tmpDir := t.TempDir()
+	cachePath := filepath.Join(tmpDir, "cache.json")
+	workDir := filepath.Join(tmpDir, "work")
+	require.NoError(t, os.MkdirAll(workDir, 0755))
+
+	// Env protocol tool echoing the command line it is asked to complete
+	mockScript := `#!/bin/bash
+if [ -n "$COMP_LINE" ]; then
+    echo "line=${COMP_LINE#* }"
+fi
+`
+	scriptPath := filepath.Join(tmpDir, "mockctl")
+	require.NoError(t, os.WriteFile(scriptPath, []byte(mockScript), 0755))
+
+	c, err := cache.New(cachePath)
+	require.NoError(t, err)
+	require.NoError(t, c.Set(&cache.Entry{
+		Path:             workDir,
+		Hash:             "hash1",
+		Timestamp:        time.Now(),
+		Version:          version.Version,
+		HierarchyHash:    "hash1",
+		MergedCommandMap: map[string]string{"kgp": scriptPath + " get pods -n prod"},
+	}))
+
+	t.Chdir(workDir)
+
+	output := captureOutput(t, func() error {
+		return Completion(CompletionParams{
+			CachePath: cachePath,
+			LogLevel:  "error",
+			Words:     []string{"kgp", "--watch", ""},
+			CWord:     2,
+		})
+	})
+
+	assert.Equal(t, "line=get pods -n prod --watch\n", output)
+}
+
+func TestCompletion_AliasExecOptions(t *testing.T) {
+	tmpDir := t.TempDir()
+	cachePath := filepath.Join(tmpDir, "cache.json")
+	workDir := filepath.Join(tmpDir, "work")
+	require.NoError(t, os.MkdirAll(filepath.Join(workDir, "infra"), 0755))
+
+	// Env protocol tool echoing the command line, its environment and directory
+	mockScript := `#!/bin/bash
+if [ -n "$COMP_LINE" ]; then
+    echo "line=${COMP_LINE#* }"
+    echo "kubeconfig=$KUBECONFIG"
+    echo "cwd=$(basename "$PWD")"
+fi
+`
+	scriptPath := filepath.Join(tmpDir, "mockctl")
+	require.NoError(t, os.WriteFile(scriptPath, []byte(mockScript), 0755))
+
+	c, err := cache.New(cachePath)
+	require.NoError(t, err)
+	require.NoError(t, c.Set(&cache.Entry{
+		Path:             workDir,
+		Hash:             "hash1",
+		Timestamp:        time.Now(),
+		Version:          version.Version,
+		HierarchyHash:    "hash1",
+		MergedCommandMap: map[string]string{"kprod": scriptPath},
+		MergedArgsMap:    map[string][]string{"kprod": {"--context", "prod"}},
+		MergedEnvMap:     map[string]map[string]string{"kprod": {"KUBECONFIG": "/etc/prod.yaml"}},
+		MergedCwdMap:     map[string]string{"kprod": "infra"},
+	}))
+
+	t.Chdir(workDir)
+	t.Setenv("KUBECONFIG", "")
+
+	output := captureOutput(t, func() error {
+		return Completion(CompletionParams{
+			CachePath: cachePath,
+			LogLevel:  "error",
+			Words:     []string{"kprod", "get", ""},
+			CWord:     2,
+		})
+	})
+
+	assert.Equal(t, "cwd=infra\nkubeconfig=/etc/prod.yaml\nline=--context prod get\n", output)
+}
+
+func TestCompletion_CustomSource(t *testing.T) {
+	tmpDir := t.TempDir()
+	cachePath := filepath.Join(tmpDir, "cache.json")
+	workDir := filepath.Join(tmpDir, "work")
+	require.NoError(t, os.MkdirAll(filepath.Join(workDir, "envs"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(workDir, "prod.yml"), []byte("x"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(workDir, "notes.txt"), []byte("x"), 0644))
+
+	c, err := cache.New(cachePath)
+	require.NoError(t, err)
+	require.NoError(t, c.Set(&cache.Entry{
+		Path:             workDir,
+		Hash:             "hash1",
+		Timestamp:        time.Now(),
+		Version:          version.Version,
+		HierarchyHash:    "hash1",
+		MergedCommandMap: map[string]string{"deploy": "./deploy.sh"},
+		MergedSourcesMap: map[string]config.CompletionConfig{
+			"deploy": {
+				Values:  []config.CompletionItem{{Value: "staging"}, {Value: "production", Description: "Live"}},
+				Command: "echo preview",
+				Files:   []string{"*.yml"},
+			},
+		},
+	}))
+
+	t.Chdir(workDir)
+
+	output := captureOutput(t, func() error {
+		return Completion(CompletionParams{
+			CachePath: cachePath,
+			LogLevel:  "error",
+			Words:     []string{"deploy", ""},
+			CWord:     1,
+		})
+	})
+	assert.Equal(t, "staging\nproduction\tLive\npreview\nenvs/\nprod.yml\n", output)
+
+	output = captureOutput(t, func() error {
+		return Completion(CompletionParams{
+			CachePath: cachePath,
+			LogLevel:  "error",
+			Words:     []string{"deploy", "pr"},
+			CWord:     1,
+		})
+	})
+	assert.Equal(t, "production\tLive\npreview\nprod.yml\n", output)
+
+	// The sources are completed the same way for every shell, whose template renders them
+	for _, shell := range []string{"bash", "zsh", "fish"} {
+		output = captureOutput(t, func() error {
+			return Completion(CompletionParams{
+				CachePath: cachePath,
+				LogLevel:  "error",
+				Shell:     shell,
+				Words:     []string{"deploy", ""},
+				CWord:     1,
+			})
+		})
+		assert.Equal(t, "staging\nproduction\tLive\npreview\nenvs/\nprod.yml\n", output, shell)
+	}
+}
+
+func TestCompletion_MatchMode(t *testing.T) {
+	tmpDir := t.TempDir()
+	cachePath := filepath.Join(tmpDir, "cache.json")
+	workDir := filepath.Join(tmpDir, "work")
+	require.NoError(t, os.MkdirAll(workDir, 0755))
+
+	// Env protocol tool
+	mockScript := `#!/bin/bash
+if [ -n "$COMP_LINE" ]; then
+    echo "port-forward"
+    echo "rollout"
+    echo "get-pods"
+fi
+`
+	scriptPath := filepath.Join(tmpDir, "mockmatch")
+	require.NoError(t, os.WriteFile(scriptPath, []byte(mockScript), 0755))
+
+	configHome := filepath.Join(tmpDir, "config")
+	require.NoError(t, os.MkdirAll(filepath.Join(configHome, "dirvana"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(configHome, "dirvana", "global.yml"), []byte(`completion:
+  match: substring
+`), 0644))
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+
+	c, err := cache.New(cachePath)
+	require.NoError(t, err)
+	require.NoError(t, c.Set(&cache.Entry{
+		Path:             workDir,
+		Hash:             "hash1",
+		Timestamp:        time.Now(),
+		Version:          version.Version,
+		HierarchyHash:    "hash1",
+		MergedCommandMap: map[string]string{"m": scriptPath, "fm": scriptPath, "deploy": "./deploy.sh"},
+		MergedMatchMap:   map[string]string{"fm": "fuzzy", "deploy": "fuzzy"},
+		MergedSourcesMap: map[string]config.CompletionConfig{
+			"deploy": {Values: []config.CompletionItem{{Value: "staging"}, {Value: "production"}, {Value: "preview"}}},
+		},
+	}))
+	t.Chdir(workDir)
+
+	complete := func(words ...string) string {
+		return captureOutput(t, func() error {
+			return Completion(CompletionParams{
+				CachePath: cachePath,
+				LogLevel:  "error",
+				Words:     words,
+				CWord:     len(words) - 1,
+			})
+		})
+	}
+
+	// Global mode: prefixes first, then word starts
+	assert.Equal(t, "rollout\nport-forward\n", complete("m", "r"))
+	assert.Equal(t, "get-pods\n", complete("m", "pod"))
+
+	// Mode of the alias
+	assert.Equal(t, "port-forward\n", complete("fm", "pf"))
+	assert.Equal(t, "", complete("fm", "rox"))
+
+	// Custom sources keep their declared order
+	assert.Equal(t, "staging\nproduction\npreview\n", complete("deploy", "i"))
+	assert.Equal(t, "preview\n", complete("deploy", "pv"))
+}
+
+func TestCompletion_Function(t *testing.T) {
+	tmpDir := t.TempDir()
+	cachePath := filepath.Join(tmpDir, "cache.json")
+	workDir := filepath.Join(tmpDir, "work")
+	require.NoError(t, os.MkdirAll(workDir, 0755))
+
+	// Env protocol tool echoing the command line it is asked to complete
+	mockScript := `#!/bin/bash
+if [ -n "$COMP_LINE" ]; then
+    echo "main@${COMP_LINE#* }"
+fi
+`
+	scriptPath := filepath.Join(tmpDir, "mockgit")
+	require.NoError(t, os.WriteFile(scriptPath, []byte(mockScript), 0755))
+
+	c, err := cache.New(cachePath)
+	require.NoError(t, err)
+	require.NoError(t, c.Set(&cache.Entry{
+		Path:          workDir,
+		Hash:          "hash1",
+		Timestamp:     time.Now(),
+		Version:       version.Version,
+		HierarchyHash: "hash1",
+		MergedCommandMap: map[string]string{
+			"gsw":    functionCommandPrefix + "gsw",
+			"greet":  functionCommandPrefix + "greet",
+			"deploy": functionCommandPrefix + "deploy",
+		},
+		MergedCompletionMap: map[string]string{
+			"gsw":    scriptPath + " switch",
+			"deploy": functionCommandPrefix + "deploy",
+		},
+		MergedSourcesMap: map[string]config.CompletionConfig{
+			"deploy": {Values: []config.CompletionItem{{Value: "prod"}}},
+		},
+	}))
+
+	t.Chdir(workDir)
+
+	complete := func(words ...string) string {
+		return captureOutput(t, func() error {
+			return Completion(CompletionParams{
+				CachePath: cachePath,
+				LogLevel:  "error",
+				Words:     words,
+				CWord:     len(words) - 1,
+			})
+		})
+	}
+
+	// Completion inherited from a tool
+	assert.Equal(t, "main@switch main\n", complete("gsw", "main"))
+	// Custom source
+	assert.Equal(t, "prod\n", complete("deploy", ""))
+	// No completion setting
+	assert.Empty(t, complete("greet", ""))
+}
+
+func TestResolveCompletionTarget(t *testing.T) {
+	tests := []struct {
+		name          string
+		command       string
+		completionCmd string
+		aliasArgs     []string
+		expectedTool  string
+		expectedArgs  []string
+	}{
+		{"no override", "kubectl get pods", "kubectl get pods", nil, "kubectl", []string{"get", "pods"}},
+		{"single word override keeps command words", "git checkout", "git", nil, "git", []string{"checkout"}},
+		{"override of a different tool", "kubecolor get pods", "kubectl", nil, "kubectl", []string{"get", "pods"}},
+		{"multi word override replaces command words", "kubecolor get", "kubectl get pods", nil, "kubectl", []string{"get", "pods"}},
+		{"alias args after command words", "kubectl", "kubectl", []string{"--context", "prod"}, "kubectl", []string{"--context", "prod"}},
+		{"alias args with single word override", "kubecolor get", "kubectl", []string{"-n", "prod"}, "kubectl", []string{"get", "-n", "prod"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tool, args := resolveCompletionTarget(tt.command, tt.completionCmd, tt.aliasArgs)
+			assert.Equal(t, tt.expectedTool, tool)
+			assert.Equal(t, tt.expectedArgs, args)
+		})
+	}
+}
+
+func TestCompletion_ResultCache(t *testing.T) {
+	tmpDir := t.TempDir()
+	cachePath := filepath.Join(tmpDir, "cache.json")
+	workDir := filepath.Join(tmpDir, "work")
+	require.NoError(t, os.MkdirAll(workDir, 0755))
+
+	// Env protocol tool counting its runs
+	calls := filepath.Join(tmpDir, "calls")
+	mockScript := `#!/bin/bash
+if [ -n "$COMP_LINE" ]; then
+    echo run >> "` + calls + `"
+    echo "line=${COMP_LINE#* }"
+    echo "charts/api"
+fi
+`
+	scriptPath := filepath.Join(tmpDir, "mockslow")
+	require.NoError(t, os.WriteFile(scriptPath, []byte(mockScript), 0755))
+
+	configHome := filepath.Join(tmpDir, "config")
+	require.NoError(t, os.MkdirAll(filepath.Join(configHome, "dirvana"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(configHome, "dirvana", "global.yml"), []byte(`completion:
+  cache:
+    tools:
+      mockslow: 1h
+`), 0644))
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+
+	c, err := cache.New(cachePath)
+	require.NoError(t, err)
+	require.NoError(t, c.Set(&cache.Entry{
+		Path:             workDir,
+		Hash:             "hash1",
+		Timestamp:        time.Now(),
+		Version:          version.Version,
+		HierarchyHash:    "hash1",
+		MergedCommandMap: map[string]string{"ms": scriptPath},
+	}))
+	t.Chdir(workDir)
+
+	complete := func(words ...string) string {
+		return captureOutput(t, func() error {
+			return Completion(CompletionParams{
+				CachePath: cachePath,
+				LogLevel:  "error",
+				Words:     append([]string{"ms"}, words...),
+				CWord:     len(words),
+			})
+		})
+	}
+	countCalls := func() int {
+		data, _ := os.ReadFile(calls)
+		return strings.Count(string(data), "run")
+	}
+
+	assert.Equal(t, "charts/api\nline=deploy\n", complete("deploy", ""))
+	runs := countCalls()
+	assert.Positive(t, runs)
+
+	// Served from the cache
+	assert.Equal(t, "charts/api\nline=deploy\n", complete("deploy", ""))
+	assert.Equal(t, runs, countCalls())
+
+	// The current word is cut after its last '/': the tool is asked for 'charts/'
+	assert.Equal(t, "charts/api\n", complete("deploy", "charts/a"))
+	assert.Equal(t, runs+1, countCalls())
+	assert.Equal(t, "charts/api\n", complete("deploy", "charts/"))
+	assert.Equal(t, runs+1, countCalls())
+
+	// Different previous words
+	assert.Equal(t, "line=status\n", complete("status", "l"))
+	assert.Equal(t, runs+2, countCalls())
+
+	results, err := completion.GetResultCacheInfo(tmpDir)
+	require.NoError(t, err)
+	require.NotNil(t, results)
+	assert.Equal(t, 3, results.Entries)
+	assert.Equal(t, completion.ResultCacheStats{Hits: 2, Misses: 3}, results.Stats)
+}
+
+func TestCompletion_ResultCacheStale(t *testing.T) {
+	tmpDir := t.TempDir()
+	cachePath := filepath.Join(tmpDir, "cache.json")
+	workDir := filepath.Join(tmpDir, "work")
+	require.NoError(t, os.MkdirAll(workDir, 0755))
+
+	scriptPath := filepath.Join(tmpDir, "mockslow")
+	require.NoError(t, os.WriteFile(scriptPath, []byte("#!/bin/bash\n[ -n \"$COMP_LINE\" ] && echo fresh\n"), 0755))
+
+	configHome := filepath.Join(tmpDir, "config")
+	require.NoError(t, os.MkdirAll(filepath.Join(configHome, "dirvana"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(configHome, "dirvana", "global.yml"), []byte(`completion:
+  cache:
+    enabled: true
+    ttl: 1m
+`), 0644))
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+
+	c, err := cache.New(cachePath)
+	require.NoError(t, err)
+	require.NoError(t, c.Set(&cache.Entry{
+		Path:             workDir,
+		Hash:             "hash1",
+		Timestamp:        time.Now(),
+		Version:          version.Version,
+		HierarchyHash:    "hash1",
+		MergedCommandMap: map[string]string{"ms": scriptPath},
+	}))
+	t.Chdir(workDir)
+
+	// Expired result
+	resultCache, err := completion.NewResultCache(filepath.Join(tmpDir, "completion-results.json"))
+	require.NoError(t, err)
+	key := completion.ResultCacheKey(scriptPath, "bash", workDir, []string{""})
+	resultCache.Set(key, &completion.Result{Suggestions: []completion.Suggestion{{Value: "stale"}}, Source: "Env"})
+	require.NoError(t, resultCache.Save())
+	data, err := os.ReadFile(filepath.Join(tmpDir, "completion-results.json"))
+	require.NoError(t, err)
+	old := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+	data = regexp.MustCompile(`"timestamp":"[^"]+"`).ReplaceAll(data, []byte(`"timestamp":"`+old+`"`))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "completion-results.json"), data, 0644))
+
+	refreshes := 0
+	original := startCompletionRefresh
+	startCompletionRefresh = func() error {
+		refreshes++
+		return nil
+	}
+	defer func() { startCompletionRefresh = original }()
+
+	complete := func() string {
+		return captureOutput(t, func() error {
+			return Completion(CompletionParams{
+				CachePath: cachePath,
+				LogLevel:  "error",
+				Words:     []string{"ms", ""},
+				CWord:     1,
+			})
+		})
+	}
+
+	// The expired result is returned and refreshed in the background
+	assert.Equal(t, "stale\n", complete())
+	assert.Equal(t, 1, refreshes)
+
+	// The refresh run completes with the tool and updates the cache
+	t.Setenv(completionRefreshEnvVar, "1")
+	assert.Equal(t, "fresh\n", complete())
+	assert.Equal(t, 1, refreshes)
+
+	t.Setenv(completionRefreshEnvVar, "")
+	assert.Equal(t, "fresh\n", complete())
+	assert.Equal(t, 1, refreshes)
+}
+
+func TestResultCacheArgs(t *testing.T) {
+	assert.Equal(t, []string{"get", ""}, resultCacheArgs([]string{"get", "po"}))
+	assert.Equal(t, []string{"charts/"}, resultCacheArgs([]string{"charts/ap"}))
+	assert.Equal(t, []string{"--namespace="}, resultCacheArgs([]string{"--namespace=ku"}))
+	assert.Empty(t, resultCacheArgs(nil))
+}
+
+func TestCompletionRedetect(t *testing.T) {
+	tmpDir := t.TempDir()
+	cachePath := filepath.Join(tmpDir, "cache.json")
+
+	// urfave/cli tool
+	scriptPath := filepath.Join(tmpDir, "mockflag")
+	require.NoError(t, os.WriteFile(scriptPath, []byte("#!/bin/bash\n[ \"${@: -1}\" = \"--generate-shell-completion\" ] && echo deploy\n"), 0755))
+
+	engine := completion.NewEngine(tmpDir)
+	_, err := engine.Complete(scriptPath, []string{""})
+	require.NoError(t, err)
+
+	output := captureOutput(t, func() error {
+		return CompletionRedetect(CompletionRedetectParams{CachePath: cachePath, LogLevel: "error", Tool: scriptPath})
+	})
+	assert.Equal(t, "✓ "+scriptPath+" ("+scriptPath+"): Flag\n", output)
+
+	err = CompletionRedetect(CompletionRedetectParams{CachePath: cachePath, LogLevel: "error", Tool: "nonexistent-command-xyz"})
+	assert.ErrorContains(t, err, "command not found")
+}
+
+func TestCompletionRegistries(t *testing.T) {
+	sources := completionRegistries(config.CompletionSettings{})
+	assert.Equal(t, []completion.RegistrySource{completion.DefaultRegistrySource()}, sources)
+
+	sources = completionRegistries(config.CompletionSettings{Registries: []config.CompletionRegistry{
+		{Name: "corp", URL: "https://tools.example.com/dirvana", TTL: "24h"},
+	}})
+	assert.Equal(t, []completion.RegistrySource{
+		{Name: "corp", URL: "https://tools.example.com/dirvana", TTL: 24 * time.Hour},
+		completion.DefaultRegistrySource(),
+	}, sources)
+
+	// Listing the default registry sets its position and TTL
+	sources = completionRegistries(config.CompletionSettings{Registries: []config.CompletionRegistry{
+		{Name: "default", TTL: "1h"},
+		{Name: "shared", URL: "/srv/completions"},
+	}})
+	assert.Equal(t, []completion.RegistrySource{
+		{Name: "default", URL: completion.RegistryBaseURL, TTL: time.Hour},
+		{Name: "shared", URL: "/srv/completions"},
+	}, sources)
+}
+
+func TestCompletionPlugins(t *testing.T) {
+	assert.Empty(t, completionPlugins(config.CompletionSettings{}))
+
+	plugins := completionPlugins(config.CompletionSettings{Plugins: []config.CompletionPlugin{
+		{Name: "corp", Path: "/opt/corp/bin/corp-completer", Timeout: "2s"},
+		{Name: "team"},
+	}})
+	assert.Equal(t, []completion.PluginSource{
+		{Name: "corp", Path: "/opt/corp/bin/corp-completer", Timeout: 2 * time.Second},
+		{Name: "team"},
+	}, plugins)
+}
+
+func TestLoadCompletionSettings_SkipsInvalidNames(t *testing.T) {
+	configHome := filepath.Join(t.TempDir(), "config")
+	require.NoError(t, os.MkdirAll(filepath.Join(configHome, "dirvana"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(configHome, "dirvana", "global.yml"), []byte(`completion:
+  registries:
+    - name: ../x
+      url: https://tools.example.com/dirvana
+    - name: team
+      url: /srv/completions
+  plugins:
+    - name: ../corp
+    - name: corp
+`), 0644))
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+
+	settings := loadCompletionSettings(logger.New("error", io.Discard))
+	assert.Equal(t, []config.CompletionRegistry{{Name: "team", URL: "/srv/completions"}}, settings.Registries)
+	assert.Equal(t, []config.CompletionPlugin{{Name: "corp"}}, settings.Plugins)
+}
+
+func TestCompletionRegistryList(t *testing.T) {
+	tmpDir := t.TempDir()
+	cachePath := filepath.Join(tmpDir, "cache.json")
+
+	registryDir := filepath.Join(tmpDir, "registry")
+	require.NoError(t, os.MkdirAll(filepath.Join(registryDir, "v1"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(registryDir, "v1", "completion-scripts.yml"), []byte("tools:\n  foo: {}\n  bar: {}\n"), 0644))
+
+	configHome := filepath.Join(tmpDir, "config")
+	require.NoError(t, os.MkdirAll(filepath.Join(configHome, "dirvana"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(configHome, "dirvana", "global.yml"), []byte(`completion:
+  registries:
+    - name: team
+      url: `+registryDir+`
+`), 0644))
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+
+	output := captureOutput(t, func() error {
+		return CompletionRegistryList(CompletionRegistryParams{CachePath: cachePath, LogLevel: "error"})
+	})
+	assert.Equal(t, "✓ 1. team ("+registryDir+"): 2 tools\n"+
+		"     "+filepath.Join(registryDir, "v1", "completion-scripts.yml")+"\n"+
+		"✗ 2. default ("+completion.RegistryBaseURL+"): not downloaded yet\n", output)
+}
+
+func TestWarnModifiedScript(t *testing.T) {
+	cacheDir := t.TempDir()
+	scriptPath := completion.GetCompletionScriptPath(cacheDir, "mytool", "bash")
+	require.NoError(t, os.MkdirAll(filepath.Dir(scriptPath), 0755))
+	require.NoError(t, os.WriteFile(scriptPath, []byte("complete -W 'a' mytool\n"), 0644))
+
+	lock, err := completion.LoadScriptLock(cacheDir)
+	require.NoError(t, err)
+	lock.Record("mytool", completion.ScriptLockEntry{SHA256: "0000"})
+	require.NoError(t, lock.Save())
+
+	var buf bytes.Buffer
+	warnModifiedScript(cacheDir, "/usr/bin/mytool", "bash", true, logger.New("warn", &buf))
+	assert.Contains(t, buf.String(), "Completion script modified since it was installed, not used in strict mode")
+	assert.Contains(t, buf.String(), scriptPath)
+
+	// Unknown and intact scripts are silent
+	buf.Reset()
+	warnModifiedScript(cacheDir, "othertool", "bash", false, logger.New("warn", &buf))
+	lock.Record("mytool", completion.ScriptLockEntry{SHA256: completionTestHash("complete -W 'a' mytool\n")})
+	require.NoError(t, lock.Save())
+	warnModifiedScript(cacheDir, "mytool", "bash", false, logger.New("warn", &buf))
+	assert.Empty(t, buf.String())
+
+	// Native script of the shell of the user
+	nativePath := completion.GetCompletionScriptPath(cacheDir, "mytool", "zsh")
+	require.NoError(t, os.MkdirAll(filepath.Dir(nativePath), 0755))
+	require.NoError(t, os.WriteFile(nativePath, []byte("#compdef mytool\n"), 0644))
+	lock.Record("zsh/mytool", completion.ScriptLockEntry{SHA256: "0000"})
+	require.NoError(t, lock.Save())
+
+	warnModifiedScript(cacheDir, "mytool", "bash", false, logger.New("warn", &buf))
+	assert.Empty(t, buf.String())
+	warnModifiedScript(cacheDir, "mytool", "zsh", false, logger.New("warn", &buf))
+	assert.Contains(t, buf.String(), "Completion script modified since it was installed")
+	assert.Contains(t, buf.String(), nativePath)
+}
+
+// completionTestHash returns the SHA256 of a script, as recorded in the lockfile
+func completionTestHash(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestCompletionBundle(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	registryDir := filepath.Join(tmpDir, "registry")
+	require.NoError(t, os.MkdirAll(filepath.Join(registryDir, "v1"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(registryDir, "v1", "completion-scripts.yml"), []byte("tools:\n  foo:\n    script:\n      url: foo.bash\n  bar:\n    script:\n      url: bar.bash\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(registryDir, "v1", "foo.bash"), []byte("complete -W 'a' foo\n"), 0644))
+
+	configHome := filepath.Join(tmpDir, "config")
+	require.NoError(t, os.MkdirAll(filepath.Join(configHome, "dirvana"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(configHome, "dirvana", "global.yml"), []byte(`completion:
+  registries:
+    - name: team
+      url: `+registryDir+`
+    - name: default
+      url: `+filepath.Join(tmpDir, "offline")+`
+`), 0644))
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+
+	bundlePath := filepath.Join(tmpDir, "bundle.tar.gz")
+	output := captureOutput(t, func() error {
+		return CompletionBundleCreate(CompletionBundleParams{CachePath: filepath.Join(tmpDir, "online", "cache.json"), LogLevel: "error", File: bundlePath})
+	})
+	assert.Contains(t, output, "✗ registry default: ")
+	assert.Contains(t, output, "✗ bar: failed to read script for bar")
+	assert.Contains(t, output, "✓ Bundle created: "+bundlePath+" (1 scripts)\n")
+
+	offlineCache := filepath.Join(tmpDir, "airgap", "cache.json")
+	output = captureOutput(t, func() error {
+		return CompletionBundleInstall(CompletionBundleParams{CachePath: offlineCache, LogLevel: "error", File: bundlePath})
+	})
+	assert.Equal(t, "✓ Installed 1 completion scripts from "+bundlePath+"\n", output)
+	assert.FileExists(t, completion.GetCompletionScriptPath(filepath.Dir(offlineCache), "foo", "bash"))
+
+	err := CompletionBundleInstall(CompletionBundleParams{CachePath: offlineCache, LogLevel: "error", File: filepath.Join(tmpDir, "missing.tar.gz")})
+	assert.ErrorContains(t, err, "failed to open bundle")
+}
@@ -5,6 +5,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"syscall"
 
 	"github.com/NikitaCOEUR/dirvana/internal/cache"
@@ -12,6 +13,7 @@ import (
 	"github.com/NikitaCOEUR/dirvana/internal/config"
 	"github.com/NikitaCOEUR/dirvana/internal/derrors"
 	"github.com/NikitaCOEUR/dirvana/internal/logger"
+	"github.com/NikitaCOEUR/dirvana/internal/shim"
 )
 
 // ExecParams contains parameters for the Exec command
@@ -21,6 +23,11 @@ type ExecParams struct {
 	LogLevel  string
 	Alias     string
 	Args      []string
+	// ContextDir overrides the directory aliases are resolved from (set by alias shims)
+	ContextDir string
+	// Fallback runs the command named like the alias when the alias is not defined: Nushell
+	// cannot remove its aliases when leaving their directory
+	Fallback bool
 }
 
 // Exec resolves and executes an alias or function defined by Dirvana
@@ -33,10 +40,27 @@ func Exec(params ExecParams) error {
 		return derrors.NewExecutionError(params.Alias, "failed to get current directory", err)
 	}
 
+	// Shims resolve aliases from their project directory, wherever they are invoked from
+	contextDir := currentDir
+	if params.ContextDir != "" {
+		contextDir = params.ContextDir
+		// Don't leak the override to the executed command
+		_ = os.Unsetenv(shim.ContextDirEnvVar)
+	}
+	bypassAliasShim(params.Alias)
+	unexportAliasFunction(params.Alias)
+
 	// Get merged alias configs and functions from the full hierarchy
-	aliases, functions, err := getMergedAliasConfigs(currentDir, params.CachePath, params.AuthPath)
+	aliases, functions, err := getMergedAliasConfigs(contextDir, params.CachePath, params.AuthPath)
 	if err != nil {
-		return derrors.NewConfigurationError(currentDir, "failed to load configuration", err)
+		return derrors.NewConfigurationError(contextDir, "failed to load configuration", err)
+	}
+
+	_, foundAlias := aliases[params.Alias]
+	_, foundFunction := functions[params.Alias]
+	if params.Fallback && !foundAlias && !foundFunction {
+		log.Debug().Str("alias", params.Alias).Msg("Alias not defined, running the command of the same name")
+		return executeDirect(params, append([]string{params.Alias}, params.Args...), log)
 	}
 
 	if len(aliases) == 0 && len(functions) == 0 {
@@ -49,10 +73,80 @@ func Exec(params ExecParams) error {
 		return err
 	}
 
+	// Apply alias params, env, cwd, default args and template
+	mode := config.ExecModeShell
+	if aliasConf, ok := aliases[params.Alias]; ok {
+		mode = aliasConf.Exec
+		var helpShown bool
+		command, helpShown, err = prepareAliasExecution(&params, aliasConf, command, currentDir, log)
+		if err != nil || helpShown {
+			return err
+		}
+	}
+
+	// Simple commands skip the shell entirely
+	argv, err := resolveDirectArgv(mode, command, params.Args)
+	if err != nil {
+		return err
+	}
+	if argv != nil {
+		return executeDirect(params, argv, log)
+	}
+
 	// Execute the command via shell
 	return executeCommand(params, command, log)
 }
 
+// bypassAliasShim makes the shim of the alias run the real command while the command of the
+// alias runs: an alias with the name of its own command (ls: ls -1) would otherwise find its
+// shim again and call itself forever. The other shims stay on PATH for the command.
+func bypassAliasShim(alias string) {
+	_ = os.Setenv(shim.BypassEnvVar, shim.AddBypass(os.Getenv(shim.BypassEnvVar), alias))
+}
+
+// unexportAliasFunction removes the function exported for the alias by 'dirvana run': bash
+// imports it in the shell running the command, where an alias with the name of its own
+// command would call itself forever
+func unexportAliasFunction(alias string) {
+	_ = os.Unsetenv("BASH_FUNC_" + alias + "%%")
+	// Format of bash releases before the Shellshock fixes
+	_ = os.Unsetenv("BASH_FUNC_" + alias + "()")
+}
+
+// prepareAliasExecution validates the alias parameters and applies the alias options.
+// It returns the command to execute, or true if the alias help was printed instead.
+func prepareAliasExecution(params *ExecParams, aliasConf config.AliasConfig, command, currentDir string, log *logger.Logger) (string, bool, error) {
+	var err error
+
+	if len(aliasConf.Params) > 0 && !isCompletionRequest(params.Args) {
+		if isHelpRequest(params.Args) {
+			fmt.Print(aliasHelp(params.Alias, aliasConf))
+			return "", true, nil
+		}
+		params.Args, err = applyAliasParams(params.Alias, aliasConf.Params, params.Args, aliasWorkDir(aliasConf, currentDir))
+		if err != nil {
+			return "", false, err
+		}
+	}
+
+	params.Args, err = applyAliasOptions(*params, aliasConf, currentDir, log)
+	if err != nil {
+		return "", false, err
+	}
+
+	// Template aliases place their arguments themselves
+	if aliasConf.Template && !isCompletionRequest(params.Args) {
+		command, err = renderAliasTemplate(params.Alias, aliasConf, command, params.Args, currentDir)
+		if err != nil {
+			return "", false, err
+		}
+		params.Args = nil
+		log.Debug().Str("alias", params.Alias).Str("command", command).Msg("Rendered alias template")
+	}
+
+	return command, false, nil
+}
+
 // resolveCommand resolves an alias or function and handles conditions/completion
 func resolveCommand(params ExecParams, aliases map[string]config.AliasConfig, functions map[string]string, currentDir string, log *logger.Logger) (string, error) {
 	// Check if alias exists
@@ -69,7 +163,7 @@ func resolveCommand(params ExecParams, aliases map[string]config.AliasConfig, fu
 		command = resolveAliasCommand(params, aliasConf, currentDir, log)
 	} else {
 		// Handle function
-		command = "__dirvana_function__" + functionBody
+		command = functionCommandPrefix + functionBody
 		log.Debug().Str("function", params.Alias).Msg("Resolving function")
 	}
 
@@ -125,7 +219,7 @@ func resolveAliasCommand(params ExecParams, aliasConf config.AliasConfig, curren
 	}
 
 	// Check if this is a completion call
-	if len(params.Args) > 0 && (params.Args[0] == "__complete" || params.Args[0] == "completion") {
+	if isCompletionRequest(params.Args) {
 		if aliasConf.Completion != nil {
 			if s, ok := aliasConf.Completion.(string); ok && s != "" {
 				command = s
@@ -138,10 +232,67 @@ func resolveAliasCommand(params ExecParams, aliasConf config.AliasConfig, curren
 	return command
 }
 
+// applyAliasOptions sets the alias environment variables and working directory
+// for the command about to be executed, and returns the arguments with the alias
+// default arguments around them
+func applyAliasOptions(params ExecParams, aliasConf config.AliasConfig, currentDir string, log *logger.Logger) ([]string, error) {
+	for _, key := range sortedStringKeys(aliasConf.Env) {
+		if err := os.Setenv(key, aliasConf.Env[key]); err != nil {
+			return nil, derrors.NewExecutionError(params.Alias, fmt.Sprintf("failed to set environment variable %s", key), err)
+		}
+	}
+
+	if aliasConf.Cwd != "" {
+		dir := aliasWorkDir(aliasConf, currentDir)
+		if err := os.Chdir(dir); err != nil {
+			return nil, derrors.NewExecutionError(params.Alias, fmt.Sprintf("failed to change directory to %s", dir), err)
+		}
+		log.Debug().Str("alias", params.Alias).Str("cwd", dir).Msg("Changed working directory")
+	}
+
+	// Completion requests must keep their protocol arguments first
+	if isCompletionRequest(params.Args) {
+		return params.Args, nil
+	}
+
+	args := make([]string, 0, len(aliasConf.Args.Prepend)+len(params.Args)+len(aliasConf.Args.Append))
+	args = append(args, aliasConf.Args.Prepend...)
+	args = append(args, params.Args...)
+	args = append(args, aliasConf.Args.Append...)
+	return args, nil
+}
+
+// aliasWorkDir returns the directory the command of an alias runs in: its cwd option, which
+// may use the variables of its env option, or the current directory
+func aliasWorkDir(aliasConf config.AliasConfig, currentDir string) string {
+	if aliasConf.Cwd == "" {
+		return currentDir
+	}
+	dir := os.Expand(aliasConf.Cwd, func(key string) string {
+		if value, ok := aliasConf.Env[key]; ok {
+			return value
+		}
+		return os.Getenv(key)
+	})
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(currentDir, dir)
+	}
+	return dir
+}
+
+// isCompletionRequest checks if the arguments are a completion protocol call
+func isCompletionRequest(args []string) bool {
+	return len(args) > 0 && (args[0] == "__complete" || args[0] == "completion")
+}
+
 // executeCommand executes the resolved command via shell
 func executeCommand(params ExecParams, command string, log *logger.Logger) error {
 	// Detect shell type
 	shellType := DetectShell("auto")
+	if shellType == ShellNu {
+		// Alias commands are POSIX shell code, which Nushell does not run
+		shellType = ShellBash
+	}
 
 	// Map shell type to executable name
 	shell := getShellExecutable(shellType)
@@ -211,7 +362,9 @@ func needsExtraShellArg(shellType string) bool {
 	return shellType != ShellFish
 }
 
-// buildShellArgs builds the argument list for shell execution
+// buildShellArgs builds the argument list for shell execution.
+// Function bodies (Nushell runs its functions through 'dirvana exec') get the arguments
+// as positional parameters instead of having them appended.
 func buildShellArgs(shell, shellType, command string, args []string) []string {
 	flags := getShellFlags(shellType)
 	argSyntax := getArgSyntax(shellType)
@@ -221,6 +374,14 @@ func buildShellArgs(shell, shellType, command string, args []string) []string {
 	argv = append(argv, shell)
 	argv = append(argv, flags...)
 
+	if body, isFunction := strings.CutPrefix(command, functionCommandPrefix); isFunction {
+		argv = append(argv, "-c", body)
+		if needsExtra {
+			argv = append(argv, shell)
+		}
+		return append(argv, args...)
+	}
+
 	if len(args) > 0 {
 		argv = append(argv, "-c", command+argSyntax)
 		if needsExtra {
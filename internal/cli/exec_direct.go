@@ -0,0 +1,155 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+
+	"github.com/NikitaCOEUR/dirvana/internal/config"
+	"github.com/NikitaCOEUR/dirvana/internal/derrors"
+	"github.com/NikitaCOEUR/dirvana/internal/logger"
+)
+
+// shellMetachars are characters that need a shell to be interpreted
+// (pipes, redirections, expansions, globs, quoting, comments, ...)
+const shellMetachars = "|&;<>()$`\\\"'*?[]{}~#!\n"
+
+// shellOnlyCommands are builtins and keywords that either don't exist as binaries
+// or behave differently when executed outside of a shell
+var shellOnlyCommands = map[string]bool{
+	"cd": true, "source": true, ".": true, "export": true, "unset": true, "set": true,
+	"eval": true, "exec": true, "alias": true, "builtin": true, "command": true,
+	"type": true, "time": true, "ulimit": true, "umask": true, "read": true,
+	"if": true, "for": true, "while": true, "until": true, "case": true,
+}
+
+// hasShellMetachars reports whether a command needs a shell to be interpreted
+func hasShellMetachars(command string) bool {
+	if strings.ContainsAny(command, shellMetachars) {
+		return true
+	}
+
+	// Leading VAR=value assignments are handled by the shell
+	fields := strings.Fields(command)
+	return len(fields) > 0 && strings.Contains(fields[0], "=")
+}
+
+// splitCommand splits a command into words like a POSIX shell would,
+// honoring single quotes, double quotes and backslash escapes but without any expansion
+func splitCommand(command string) ([]string, error) {
+	var words []string
+	var current strings.Builder
+	inWord := false
+	var quote rune
+	runes := []rune(command)
+
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '\\':
+			if i+1 >= len(runes) {
+				return nil, fmt.Errorf("trailing backslash in command: %s", command)
+			}
+			next := runes[i+1]
+			// Inside double quotes, backslash only escapes a few characters
+			if quote == '"' && !strings.ContainsRune("$`\"\\\n", next) {
+				current.WriteRune(r)
+				continue
+			}
+			current.WriteRune(next)
+			inWord = true
+			i++
+		case quote == '"':
+			if r == '"' {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inWord = true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inWord {
+				words = append(words, current.String())
+				current.Reset()
+				inWord = false
+			}
+		default:
+			current.WriteRune(r)
+			inWord = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated quote in command: %s", command)
+	}
+	if inWord {
+		words = append(words, current.String())
+	}
+
+	return words, nil
+}
+
+// resolveDirectArgv returns the argv to execute directly, or nil if the command
+// must go through the shell. In auto mode, commands with shell metacharacters,
+// shell builtins and commands not found in PATH fall back to the shell.
+func resolveDirectArgv(mode, command string, args []string) ([]string, error) {
+	switch mode {
+	case config.ExecModeShell:
+		return nil, nil
+	case config.ExecModeDirect:
+		words, err := splitCommand(command)
+		if err != nil {
+			return nil, derrors.NewValidationError("exec", "cannot execute command directly", err)
+		}
+		if len(words) == 0 {
+			return nil, derrors.NewValidationError("exec", "empty command", nil)
+		}
+		return append(words, args...), nil
+	}
+
+	if !config.IsValidExecMode(mode) {
+		return nil, derrors.NewValidationError("exec", fmt.Sprintf("invalid execution mode '%s' (expected auto, shell or direct)", mode), nil)
+	}
+
+	// Auto mode
+	if hasShellMetachars(command) {
+		return nil, nil
+	}
+
+	words := strings.Fields(command)
+	if len(words) == 0 || shellOnlyCommands[words[0]] {
+		return nil, nil
+	}
+	if _, err := exec.LookPath(words[0]); err != nil {
+		return nil, nil
+	}
+
+	return append(words, args...), nil
+}
+
+// executeDirect replaces the current process with the command, without a shell
+func executeDirect(params ExecParams, argv []string, log *logger.Logger) error {
+	execPath, err := exec.LookPath(argv[0])
+	if err != nil {
+		return derrors.NewExecutionError(params.Alias, fmt.Sprintf("command not found: %s", argv[0]), err)
+	}
+
+	log.Debug().
+		Str("path", execPath).
+		Str("argv", fmt.Sprintf("%q", argv)).
+		Msg("Executing command directly")
+
+	err = syscall.Exec(execPath, argv, os.Environ())
+
+	// If we reach here, syscall.Exec failed
+	return derrors.NewExecutionError(argv[0], "failed to execute command", err)
+}
@@ -0,0 +1,102 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/NikitaCOEUR/dirvana/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHasShellMetachars(t *testing.T) {
+	tests := []struct {
+		command  string
+		expected bool
+	}{
+		{"kubectl get pods", false},
+		{"terraform plan -var-file=prod.tfvars", false},
+		{"ls | grep foo", true},
+		{"echo $HOME", true},
+		{"ls *.go", true},
+		{"make build && make test", true},
+		{"echo 'quoted'", true},
+		{"cat < file", true},
+		{"FOO=bar make", true},
+		{"ls ~/src", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			assert.Equal(t, tt.expected, hasShellMetachars(tt.command))
+		})
+	}
+}
+
+func TestSplitCommand(t *testing.T) {
+	tests := []struct {
+		command  string
+		expected []string
+	}{
+		{"kubectl get pods", []string{"kubectl", "get", "pods"}},
+		{"  spaced   out  ", []string{"spaced", "out"}},
+		{`echo 'hello world' "$HOME"`, []string{"echo", "hello world", "$HOME"}},
+		{`echo "a \"b\" \c"`, []string{"echo", `a "b" \c`}},
+		{`echo a\ b`, []string{"echo", "a b"}},
+		{`echo '' x`, []string{"echo", "", "x"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			words, err := splitCommand(tt.command)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, words)
+		})
+	}
+}
+
+func TestSplitCommand_Unterminated(t *testing.T) {
+	_, err := splitCommand(`echo "unterminated`)
+	assert.Error(t, err)
+
+	_, err = splitCommand(`echo trailing\`)
+	assert.Error(t, err)
+}
+
+func TestResolveDirectArgv(t *testing.T) {
+	// Shell mode never executes directly
+	argv, err := resolveDirectArgv(config.ExecModeShell, "ls -la", nil)
+	require.NoError(t, err)
+	assert.Nil(t, argv)
+
+	// Auto mode with a simple command
+	argv, err = resolveDirectArgv(config.ExecModeAuto, "ls -la", []string{"/tmp"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ls", "-la", "/tmp"}, argv)
+
+	// Empty mode behaves like auto
+	argv, err = resolveDirectArgv("", "ls", nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ls"}, argv)
+
+	// Auto mode falls back to the shell for pipelines, builtins and unknown commands
+	for _, command := range []string{"ls | wc -l", "cd /tmp", "dirvana-nonexistent-command-xyz"} {
+		argv, err = resolveDirectArgv(config.ExecModeAuto, command, nil)
+		require.NoError(t, err)
+		assert.Nil(t, argv, command)
+	}
+
+	// Direct mode splits quotes without expansion
+	argv, err = resolveDirectArgv(config.ExecModeDirect, `echo "$HOME" 'a b'`, []string{"c"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"echo", "$HOME", "a b", "c"}, argv)
+
+	// Direct mode reports invalid commands
+	_, err = resolveDirectArgv(config.ExecModeDirect, `echo "oops`, nil)
+	assert.Error(t, err)
+	_, err = resolveDirectArgv(config.ExecModeDirect, "   ", nil)
+	assert.Error(t, err)
+
+	// Unknown modes are rejected instead of behaving like auto
+	_, err = resolveDirectArgv("sometimes", "ls", nil)
+	assert.Error(t, err)
+}
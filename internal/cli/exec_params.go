@@ -0,0 +1,193 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/NikitaCOEUR/dirvana/internal/completion"
+	"github.com/NikitaCOEUR/dirvana/internal/config"
+	"github.com/NikitaCOEUR/dirvana/internal/derrors"
+)
+
+// isHelpRequest checks if the alias was called with --help
+func isHelpRequest(args []string) bool {
+	return len(args) == 1 && (args[0] == "--help" || args[0] == "-h")
+}
+
+// applyAliasParams validates arguments against the alias parameters
+// and fills omitted trailing parameters with their defaults.
+// Flags are passed to the command as is: only positional arguments are parameters.
+// File and directory values are relative to dir, the directory the command runs in.
+func applyAliasParams(aliasName string, params []config.AliasParam, args []string, dir string) ([]string, error) {
+	result := append([]string(nil), args...)
+	canFill := true
+
+	var positional []string
+	for _, arg := range args {
+		if !strings.HasPrefix(arg, "-") {
+			positional = append(positional, arg)
+		}
+	}
+
+	for i, param := range params {
+		if i < len(positional) {
+			if err := validateParamValue(param, positional[i], dir); err != nil {
+				return nil, derrors.NewValidationError(param.Name,
+					fmt.Sprintf("invalid value for <%s>: %v (see '%s --help')", param.Name, err, aliasName), nil)
+			}
+			continue
+		}
+
+		if param.Required {
+			return nil, derrors.NewValidationError(param.Name,
+				fmt.Sprintf("missing required argument <%s> (see '%s --help')", param.Name, aliasName), nil)
+		}
+
+		// Parameters are positional: defaults can't be applied after a gap
+		if param.Default == "" {
+			canFill = false
+		} else if canFill {
+			result = append(result, param.Default)
+		}
+	}
+
+	return result, nil
+}
+
+// validateParamValue checks a value against the parameter type; relative paths are
+// resolved from dir
+func validateParamValue(param config.AliasParam, value, dir string) error {
+	path := value
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+
+	switch param.TypeName() {
+	case config.ParamTypeEnum:
+		if !slices.Contains(param.Values, value) {
+			return fmt.Errorf("'%s' is not one of %s", value, strings.Join(param.Values, ", "))
+		}
+	case config.ParamTypeInt:
+		if _, err := strconv.Atoi(value); err != nil {
+			return fmt.Errorf("'%s' is not an integer", value)
+		}
+	case config.ParamTypeFile:
+		info, err := os.Stat(path)
+		if err != nil || info.IsDir() {
+			return fmt.Errorf("file not found: %s", value)
+		}
+	case config.ParamTypeDir:
+		info, err := os.Stat(path)
+		if err != nil || !info.IsDir() {
+			return fmt.Errorf("directory not found: %s", value)
+		}
+	}
+	return nil
+}
+
+// aliasHelp generates the --help text of an alias with parameters
+func aliasHelp(aliasName string, aliasConf config.AliasConfig) string {
+	var sb strings.Builder
+
+	usage := []string{aliasName}
+	for _, param := range aliasConf.Params {
+		if param.Required {
+			usage = append(usage, "<"+param.Name+">")
+		} else {
+			usage = append(usage, "["+param.Name+"]")
+		}
+	}
+
+	fmt.Fprintf(&sb, "Usage: %s\n\n", strings.Join(usage, " "))
+	fmt.Fprintf(&sb, "Runs: %s\n\n", aliasConf.Command)
+	sb.WriteString("Arguments:\n")
+
+	width := 0
+	for _, param := range aliasConf.Params {
+		width = max(width, len(param.Name))
+	}
+
+	for _, param := range aliasConf.Params {
+		var details []string
+		if param.Description != "" {
+			details = append(details, param.Description)
+		}
+		details = append(details, "("+paramTypeHelp(param)+")")
+		if param.Required {
+			details = append(details, "[required]")
+		}
+		if param.Default != "" {
+			details = append(details, "[default: "+param.Default+"]")
+		}
+		fmt.Fprintf(&sb, "  %-*s  %s\n", width, param.Name, strings.Join(details, " "))
+	}
+
+	return sb.String()
+}
+
+// paramTypeHelp describes a parameter type for --help
+func paramTypeHelp(param config.AliasParam) string {
+	switch param.TypeName() {
+	case config.ParamTypeEnum:
+		return "one of: " + strings.Join(param.Values, ", ")
+	case config.ParamTypeFile:
+		if param.Pattern != "" {
+			return "file matching " + param.Pattern
+		}
+		return "file"
+	case config.ParamTypeDir:
+		return "directory"
+	case config.ParamTypeInt:
+		return "integer"
+	default:
+		return "string"
+	}
+}
+
+// completeAliasParam completes the value of a typed alias parameter.
+// Returns false when the word being completed is not a declared parameter,
+// so the underlying tool's completion is used.
+func completeAliasParam(params []config.AliasParam, compParams CompletionParams) ([]completion.Suggestion, bool) {
+	if len(params) == 0 {
+		return nil, false
+	}
+
+	currentWord := getCurrentWord(compParams)
+	if strings.HasPrefix(currentWord, "-") {
+		return nil, false
+	}
+
+	// Position among positional arguments (flags are not counted)
+	position := 0
+	for i := 1; i < compParams.CWord && i < len(compParams.Words); i++ {
+		if !strings.HasPrefix(compParams.Words[i], "-") {
+			position++
+		}
+	}
+	if position >= len(params) {
+		return nil, false
+	}
+
+	param := params[position]
+	switch param.TypeName() {
+	case config.ParamTypeEnum:
+		var suggestions []completion.Suggestion
+		for _, value := range param.Values {
+			if strings.HasPrefix(value, currentWord) {
+				suggestions = append(suggestions, completion.Suggestion{Value: value, Description: param.Description})
+			}
+		}
+		return suggestions, true
+	case config.ParamTypeFile:
+		return completion.CompletePaths(currentWord, param.Pattern, false), true
+	case config.ParamTypeDir:
+		return completion.CompletePaths(currentWord, "", true), true
+	default:
+		// Free values: nothing to suggest, but don't offer the tool's subcommands either
+		return nil, true
+	}
+}
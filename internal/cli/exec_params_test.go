@@ -0,0 +1,148 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/NikitaCOEUR/dirvana/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var deployParams = []config.AliasParam{
+	{Name: "env", Type: config.ParamTypeEnum, Values: []string{"dev", "staging", "prod"}, Required: true, Description: "Target environment"},
+	{Name: "replicas", Type: config.ParamTypeInt, Default: "1"},
+	{Name: "chart", Type: config.ParamTypeDir, Default: "."},
+}
+
+func TestIsHelpRequest(t *testing.T) {
+	assert.True(t, isHelpRequest([]string{"--help"}))
+	assert.True(t, isHelpRequest([]string{"-h"}))
+	assert.False(t, isHelpRequest(nil))
+	assert.False(t, isHelpRequest([]string{"prod", "--help"}))
+}
+
+func TestApplyAliasParams(t *testing.T) {
+	args, err := applyAliasParams("deploy", deployParams, []string{"prod"}, ".")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"prod", "1", "."}, args)
+
+	args, err = applyAliasParams("deploy", deployParams, []string{"dev", "3", ".", "--dry-run"}, ".")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"dev", "3", ".", "--dry-run"}, args)
+}
+
+func TestApplyAliasParams_Errors(t *testing.T) {
+	_, err := applyAliasParams("deploy", deployParams, nil, ".")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "missing required argument <env>")
+
+	_, err = applyAliasParams("deploy", deployParams, []string{"qa"}, ".")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "'qa' is not one of dev, staging, prod")
+
+	_, err = applyAliasParams("deploy", deployParams, []string{"dev", "many"}, ".")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not an integer")
+
+	_, err = applyAliasParams("deploy", deployParams, []string{"dev", "1", filepath.Join(t.TempDir(), "missing")}, ".")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "directory not found")
+}
+
+func TestApplyAliasParams_NoDefaultAfterGap(t *testing.T) {
+	params := []config.AliasParam{
+		{Name: "a"},
+		{Name: "b", Default: "x"},
+	}
+
+	args, err := applyAliasParams("alias", params, nil, ".")
+	require.NoError(t, err)
+	assert.Empty(t, args)
+}
+
+func TestValidateParamValue_File(t *testing.T) {
+	tmpDir := t.TempDir()
+	file := filepath.Join(tmpDir, "values.yaml")
+	require.NoError(t, os.WriteFile(file, []byte("x"), 0644))
+
+	param := config.AliasParam{Name: "values", Type: config.ParamTypeFile}
+	assert.NoError(t, validateParamValue(param, file, "."))
+	assert.Error(t, validateParamValue(param, tmpDir, "."))
+	assert.Error(t, validateParamValue(param, filepath.Join(tmpDir, "missing.yaml"), "."))
+
+	// Relative paths are resolved from the directory the command runs in
+	assert.NoError(t, validateParamValue(param, "values.yaml", tmpDir))
+	assert.Error(t, validateParamValue(param, "values.yaml", t.TempDir()))
+}
+
+func TestApplyAliasParams_SkipsFlags(t *testing.T) {
+	args, err := applyAliasParams("deploy", deployParams, []string{"--dry-run", "prod", "-v", "2"}, ".")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"--dry-run", "prod", "-v", "2", "."}, args)
+
+	_, err = applyAliasParams("deploy", deployParams, []string{"--dry-run"}, ".")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "missing required argument <env>")
+}
+
+func TestAliasWorkDir(t *testing.T) {
+	t.Setenv("DIRVANA_TEST_ROOT", "/srv")
+
+	assert.Equal(t, "/work", aliasWorkDir(config.AliasConfig{}, "/work"))
+	assert.Equal(t, filepath.Join("/work", "charts"), aliasWorkDir(config.AliasConfig{Cwd: "charts"}, "/work"))
+	assert.Equal(t, filepath.Join("/srv", "app"), aliasWorkDir(config.AliasConfig{Cwd: "$DIRVANA_TEST_ROOT/app"}, "/work"))
+	assert.Equal(t, filepath.Join("/opt", "app"), aliasWorkDir(config.AliasConfig{
+		Cwd: "$DIRVANA_TEST_ROOT/app",
+		Env: map[string]string{"DIRVANA_TEST_ROOT": "/opt"},
+	}, "/work"))
+}
+
+func TestAliasHelp(t *testing.T) {
+	help := aliasHelp("deploy", config.AliasConfig{Command: "helm upgrade", Params: deployParams})
+
+	assert.Contains(t, help, "Usage: deploy <env> [replicas] [chart]")
+	assert.Contains(t, help, "Runs: helm upgrade")
+	assert.Contains(t, help, "  env       Target environment (one of: dev, staging, prod) [required]")
+	assert.Contains(t, help, "  replicas  (integer) [default: 1]")
+	assert.Contains(t, help, "  chart     (directory) [default: .]")
+}
+
+func TestCompleteAliasParam(t *testing.T) {
+	// First positional parameter: enum values
+	suggestions, handled := completeAliasParam(deployParams, CompletionParams{Words: []string{"deploy", "st"}, CWord: 1})
+	require.True(t, handled)
+	require.Len(t, suggestions, 1)
+	assert.Equal(t, "staging", suggestions[0].Value)
+
+	// Flags are not counted as positional arguments
+	suggestions, handled = completeAliasParam(deployParams, CompletionParams{Words: []string{"deploy", "--debug", ""}, CWord: 2})
+	require.True(t, handled)
+	assert.Len(t, suggestions, 3)
+
+	// Free values: handled without suggestions
+	suggestions, handled = completeAliasParam(deployParams, CompletionParams{Words: []string{"deploy", "prod", ""}, CWord: 2})
+	assert.True(t, handled)
+	assert.Empty(t, suggestions)
+
+	// Beyond declared parameters and flags: use the tool's completion
+	_, handled = completeAliasParam(deployParams, CompletionParams{Words: []string{"deploy", "prod", "1", ".", ""}, CWord: 4})
+	assert.False(t, handled)
+	_, handled = completeAliasParam(deployParams, CompletionParams{Words: []string{"deploy", "--"}, CWord: 1})
+	assert.False(t, handled)
+	_, handled = completeAliasParam(nil, CompletionParams{Words: []string{"deploy", ""}, CWord: 1})
+	assert.False(t, handled)
+}
+
+func TestCompleteAliasParam_Dir(t *testing.T) {
+	tmpDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, "charts"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "chart.txt"), []byte("x"), 0644))
+	t.Chdir(tmpDir)
+
+	suggestions, handled := completeAliasParam(deployParams, CompletionParams{Words: []string{"deploy", "prod", "1", "ch"}, CWord: 3})
+	require.True(t, handled)
+	require.Len(t, suggestions, 1)
+	assert.Equal(t, "charts/", suggestions[0].Value)
+}
@@ -0,0 +1,119 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/Masterminds/sprig/v3"
+	"github.com/NikitaCOEUR/dirvana/internal/config"
+	"github.com/NikitaCOEUR/dirvana/internal/derrors"
+)
+
+// safeShellWord matches words that don't need quoting
+var safeShellWord = regexp.MustCompile(`^[a-zA-Z0-9_@%+=:,./-]+$`)
+
+// argsIndexSyntax matches the {{.Args.0}} shorthand, which Go templates don't support
+var argsIndexSyntax = regexp.MustCompile(`\.Args\.(\d+)\b`)
+
+// shellWord is a template value that prints itself shell-quoted,
+// so values injected in alias templates can't break the command
+type shellWord string
+
+// String returns the word quoted for POSIX shells (unchanged if it is safe as is)
+func (w shellWord) String() string {
+	s := string(w)
+	if safeShellWord.MatchString(s) {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// aliasTemplateData is the data available to alias command templates
+type aliasTemplateData struct {
+	Args             []shellWord          // Arguments given to the alias
+	Env              map[string]shellWord // Current environment
+	Cwd              shellWord            // Directory the command runs from
+	DIRVANA_DIR      shellWord            //nolint:revive,staticcheck // Same name as in load-time templates
+	USER_WORKING_DIR shellWord            //nolint:revive,staticcheck // Same name as in load-time templates
+
+	gitBranch *shellWord
+}
+
+// GitBranch returns the current git branch, computed only when the template uses it
+func (d *aliasTemplateData) GitBranch() shellWord {
+	if d.gitBranch == nil {
+		branch := ""
+		if output, err := exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD").Output(); err == nil {
+			branch = strings.TrimSpace(string(output))
+		}
+		word := shellWord(branch)
+		d.gitBranch = &word
+	}
+	return *d.gitBranch
+}
+
+// aliasTemplateFuncs returns Sprig functions with shell-safe overrides
+func aliasTemplateFuncs() template.FuncMap {
+	funcs := sprig.TxtFuncMap()
+	funcs["env"] = func(key string) shellWord {
+		return shellWord(os.Getenv(key))
+	}
+	// raw disables quoting for values that must be interpreted by the shell
+	funcs["raw"] = func(v interface{}) string {
+		switch w := v.(type) {
+		case shellWord:
+			return string(w)
+		case []shellWord:
+			words := make([]string, len(w))
+			for i, word := range w {
+				words[i] = string(word)
+			}
+			return strings.Join(words, " ")
+		default:
+			return fmt.Sprint(v)
+		}
+	}
+	return funcs
+}
+
+// renderAliasTemplate renders a template alias command with its arguments.
+// Every injected value is shell-quoted unless wrapped with 'raw'.
+func renderAliasTemplate(aliasName string, aliasConf config.AliasConfig, command string, args []string, userWorkingDir string) (string, error) {
+	command = argsIndexSyntax.ReplaceAllString(command, "(index .Args $1)")
+
+	tmpl, err := template.New(aliasName).Option("missingkey=zero").Funcs(aliasTemplateFuncs()).Parse(command)
+	if err != nil {
+		return "", derrors.NewConfigurationError(aliasName, "invalid alias template", err)
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		cwd = userWorkingDir
+	}
+
+	data := &aliasTemplateData{
+		Args:             make([]shellWord, len(args)),
+		Env:              make(map[string]shellWord),
+		Cwd:              shellWord(cwd),
+		DIRVANA_DIR:      shellWord(aliasConf.ConfigDir),
+		USER_WORKING_DIR: shellWord(userWorkingDir),
+	}
+	for i, arg := range args {
+		data.Args[i] = shellWord(arg)
+	}
+	for key, value := range buildEnvMap() {
+		data.Env[key] = shellWord(value)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", derrors.NewExecutionError(aliasName, "failed to render alias template", err)
+	}
+
+	return buf.String(), nil
+}
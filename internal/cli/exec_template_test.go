@@ -0,0 +1,82 @@
+package cli
+
+import (
+	"os/exec"
+	"testing"
+
+	"github.com/NikitaCOEUR/dirvana/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShellWord_String(t *testing.T) {
+	assert.Equal(t, "prod", shellWord("prod").String())
+	assert.Equal(t, "./charts/api-v1.2", shellWord("./charts/api-v1.2").String())
+	assert.Equal(t, "''", shellWord("").String())
+	assert.Equal(t, "'a b'", shellWord("a b").String())
+	assert.Equal(t, `'$(rm -rf /)'`, shellWord("$(rm -rf /)").String())
+	assert.Equal(t, `'it'\''s'`, shellWord("it's").String())
+}
+
+func TestRenderAliasTemplate(t *testing.T) {
+	t.Setenv("NS", "staging")
+
+	command, err := renderAliasTemplate("deploy", config.AliasConfig{},
+		`helm upgrade {{.Args.0}} ./charts/{{.Args.0}} -n {{env "NS"}}`, []string{"api"}, "/work")
+	require.NoError(t, err)
+	assert.Equal(t, "helm upgrade api ./charts/api -n staging", command)
+}
+
+func TestRenderAliasTemplate_Quoting(t *testing.T) {
+	command, err := renderAliasTemplate("say", config.AliasConfig{},
+		`echo {{index .Args 0}} {{.Args | join " "}}`, []string{"a b", "$(id)"}, "/work")
+	require.NoError(t, err)
+	assert.Equal(t, `echo 'a b' 'a b' '$(id)'`, command)
+
+	// The rendered command must pass arguments through the shell unchanged
+	output, err := exec.Command("bash", "--norc", "--noprofile", "-c", command).Output()
+	require.NoError(t, err)
+	assert.Equal(t, "a b a b $(id)\n", string(output))
+}
+
+func TestRenderAliasTemplate_Raw(t *testing.T) {
+	command, err := renderAliasTemplate("run", config.AliasConfig{},
+		`{{raw .Args.0}} | wc -l`, []string{"ls *.go"}, "/work")
+	require.NoError(t, err)
+	assert.Equal(t, "ls *.go | wc -l", command)
+}
+
+func TestRenderAliasTemplate_Variables(t *testing.T) {
+	tmpDir := resolveSymlinks(t, t.TempDir())
+	t.Chdir(tmpDir)
+	t.Setenv("DIRVANA_TEMPLATE_TEST", "value")
+
+	command, err := renderAliasTemplate("vars", config.AliasConfig{ConfigDir: "/project"},
+		`{{.DIRVANA_DIR}} {{.USER_WORKING_DIR}} {{.Cwd}} {{.Env.DIRVANA_TEMPLATE_TEST}} {{.Env.DIRVANA_MISSING}} [{{.GitBranch}}]`,
+		nil, "/work")
+	require.NoError(t, err)
+	assert.Equal(t, "/project /work "+tmpDir+" value '' ['']", command)
+}
+
+func TestRenderAliasTemplate_Conditionals(t *testing.T) {
+	tmpl := `{{if eq (index .Args 0) "prod"}}deploy --confirm{{else}}deploy{{end}}`
+
+	command, err := renderAliasTemplate("d", config.AliasConfig{}, tmpl, []string{"prod"}, "/work")
+	require.NoError(t, err)
+	assert.Equal(t, "deploy --confirm", command)
+
+	command, err = renderAliasTemplate("d", config.AliasConfig{}, tmpl, []string{"dev"}, "/work")
+	require.NoError(t, err)
+	assert.Equal(t, "deploy", command)
+}
+
+func TestRenderAliasTemplate_Errors(t *testing.T) {
+	_, err := renderAliasTemplate("bad", config.AliasConfig{}, `{{.Args`, nil, "/work")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid alias template")
+
+	// Missing positional argument
+	_, err = renderAliasTemplate("deploy", config.AliasConfig{}, `helm upgrade {{.Args.0}}`, nil, "/work")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to render alias template")
+}
@@ -1,13 +1,19 @@
 package cli
 
 import (
+	"context"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/NikitaCOEUR/dirvana/internal/auth"
 	"github.com/NikitaCOEUR/dirvana/internal/cache"
+	"github.com/NikitaCOEUR/dirvana/internal/config"
+	"github.com/NikitaCOEUR/dirvana/internal/logger"
+	"github.com/NikitaCOEUR/dirvana/internal/shim"
 	"github.com/NikitaCOEUR/dirvana/pkg/version"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -145,6 +151,37 @@ func TestExec_AliasNotFound(t *testing.T) {
 	assert.Contains(t, err.Error(), "alias 'nonexistent' not found")
 }
 
+func TestExec_Fallback(t *testing.T) {
+	tmpDir := t.TempDir()
+	cachePath := filepath.Join(tmpDir, "cache.json")
+	authPath := filepath.Join(tmpDir, "auth.json")
+	workDir := filepath.Join(tmpDir, "work")
+	require.NoError(t, os.MkdirAll(workDir, 0755))
+
+	// No configuration: the aliases of the directory left by Nushell are not defined
+	origDir, err := os.Getwd()
+	require.NoError(t, err)
+	defer func() { _ = os.Chdir(origDir) }()
+	require.NoError(t, os.Chdir(workDir))
+
+	params := ExecParams{
+		CachePath: cachePath,
+		AuthPath:  authPath,
+		LogLevel:  "error",
+		Alias:     "dirvana-nonexistent-command-xyz",
+	}
+
+	err = Exec(params)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no dirvana context found")
+
+	// The command of the same name is run instead
+	params.Fallback = true
+	err = Exec(params)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "command not found: dirvana-nonexistent-command-xyz")
+}
+
 func TestExec_EmptyCommand(t *testing.T) {
 	tmpDir := t.TempDir()
 	cachePath := filepath.Join(tmpDir, "cache.json")
@@ -390,3 +427,145 @@ func TestExec_CacheLoadFailure(t *testing.T) {
 	assert.Error(t, err)
 	// Should fail at cache loading
 }
+
+func TestApplyAliasOptions(t *testing.T) {
+	tmpDir := resolveSymlinks(t, t.TempDir())
+	subDir := filepath.Join(tmpDir, "sub")
+	require.NoError(t, os.MkdirAll(subDir, 0755))
+
+	t.Chdir(tmpDir)
+	t.Setenv("DIRVANA_TEST_ALIAS_ENV", "")
+
+	aliasConf := config.AliasConfig{
+		Command: "terraform",
+		Env:     map[string]string{"DIRVANA_TEST_ALIAS_ENV": "set"},
+		Cwd:     "sub",
+		Args: config.AliasArgs{
+			Prepend: []string{"-chdir=infra"},
+			Append:  []string{"-no-color"},
+		},
+	}
+
+	args, err := applyAliasOptions(ExecParams{Alias: "tf", Args: []string{"plan"}}, aliasConf, tmpDir, logger.New("error", nil))
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"-chdir=infra", "plan", "-no-color"}, args)
+	assert.Equal(t, "set", os.Getenv("DIRVANA_TEST_ALIAS_ENV"))
+
+	cwd, err := os.Getwd()
+	require.NoError(t, err)
+	assert.Equal(t, subDir, cwd)
+}
+
+func TestApplyAliasOptions_CompletionKeepsArgs(t *testing.T) {
+	aliasConf := config.AliasConfig{
+		Command: "kubectl",
+		Args:    config.AliasArgs{Prepend: []string{"--context", "prod"}},
+	}
+
+	args, err := applyAliasOptions(ExecParams{Alias: "k", Args: []string{"__complete", "get", ""}}, aliasConf, "", logger.New("error", nil))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"__complete", "get", ""}, args)
+}
+
+func TestApplyAliasOptions_InvalidCwd(t *testing.T) {
+	aliasConf := config.AliasConfig{
+		Command: "ls",
+		Cwd:     filepath.Join(t.TempDir(), "missing"),
+	}
+
+	_, err := applyAliasOptions(ExecParams{Alias: "ls"}, aliasConf, "", logger.New("error", nil))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to change directory")
+}
+
+func TestBypassAliasShim(t *testing.T) {
+	tmpDir := t.TempDir()
+	projectDir := filepath.Join(tmpDir, "project")
+	realDir := filepath.Join(tmpDir, "bin")
+	require.NoError(t, os.MkdirAll(realDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(realDir, "mytool"), []byte("#!/bin/sh\necho real\n"), 0755))
+
+	// Shims named like the command of the alias and like another alias
+	shimDir := shim.Dir(tmpDir, projectDir)
+	dirvana := filepath.Join(tmpDir, "dirvana")
+	require.NoError(t, os.WriteFile(dirvana, []byte("#!/bin/sh\necho \"alias $2\"\n"), 0755))
+	_, err := shim.Ensure(shimDir, projectDir, "hash", dirvana, []string{"mytool", "other"})
+	require.NoError(t, err)
+
+	path := shimDir + string(os.PathListSeparator) + realDir + string(os.PathListSeparator) + os.Getenv("PATH")
+	t.Setenv("PATH", path)
+	t.Setenv(shim.BypassEnvVar, "")
+
+	runTool := func(name string) string {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		out, err := exec.CommandContext(ctx, "sh", "-c", name).Output()
+		require.NoError(t, err)
+		return strings.TrimSpace(string(out))
+	}
+	require.Equal(t, "alias mytool", runTool("mytool"))
+
+	bypassAliasShim("mytool")
+
+	// The alias command finds the real command, and still sees the other aliases
+	assert.Equal(t, path, os.Getenv("PATH"))
+	argv, err := resolveDirectArgv(config.ExecModeAuto, "mytool", nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"mytool"}, argv)
+	assert.Equal(t, "real", runTool("mytool"))
+	assert.Equal(t, "alias other", runTool("other"))
+
+	// Nested aliases are all bypassed
+	bypassAliasShim("other")
+	assert.Equal(t, "mytool:other", os.Getenv(shim.BypassEnvVar))
+}
+
+func TestUnexportAliasFunction(t *testing.T) {
+	if _, err := exec.LookPath("bash"); err != nil {
+		t.Skip("bash not installed")
+	}
+
+	realDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(realDir, "mytool"), []byte("#!/bin/sh\necho real\n"), 0755))
+	t.Setenv("PATH", realDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	// Function exported by 'dirvana run' for an alias named like its command
+	t.Setenv("BASH_FUNC_mytool%%", "() {  echo exported\n}")
+	t.Setenv("BASH_FUNC_other%%", "() {  echo other\n}")
+
+	runBash := func(command string) string {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		out, err := exec.CommandContext(ctx, "bash", "-c", command).Output()
+		require.NoError(t, err)
+		return strings.TrimSpace(string(out))
+	}
+	require.Equal(t, "exported", runBash("mytool"))
+
+	unexportAliasFunction("mytool")
+
+	assert.Equal(t, "real", runBash("mytool"))
+	// Other exported functions are still available to the command
+	assert.Equal(t, "other", runBash("other"))
+}
+
+func TestBuildShellArgs_Function(t *testing.T) {
+	if _, err := exec.LookPath("bash"); err != nil {
+		t.Skip("bash not installed")
+	}
+
+	// Function bodies get the arguments as positional parameters
+	argv := buildShellArgs("bash", ShellBash, functionCommandPrefix+`echo "first=$1 count=$#"`, []string{"a b", "c"})
+	assert.Equal(t, []string{"bash", "--norc", "--noprofile", "-c", `echo "first=$1 count=$#"`, "bash", "a b", "c"}, argv)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, argv[0], argv[1:]...).Output()
+	require.NoError(t, err)
+	assert.Equal(t, "first=a b count=2", strings.TrimSpace(string(out)))
+
+	// Alias commands get the arguments appended
+	argv = buildShellArgs("bash", ShellBash, "ls -la", []string{"/tmp"})
+	assert.Equal(t, []string{"bash", "--norc", "--noprofile", "-c", `ls -la "$@"`, "bash", "/tmp"}, argv)
+}
@@ -11,7 +11,9 @@ import (
 	"github.com/NikitaCOEUR/dirvana/internal/config"
 	"github.com/NikitaCOEUR/dirvana/internal/derrors"
 	"github.com/NikitaCOEUR/dirvana/internal/logger"
+	shellpkg "github.com/NikitaCOEUR/dirvana/internal/shell"
 	"github.com/NikitaCOEUR/dirvana/internal/shellctx"
+	"github.com/NikitaCOEUR/dirvana/internal/shim"
 	"github.com/NikitaCOEUR/dirvana/internal/timing"
 	"github.com/NikitaCOEUR/dirvana/pkg/version"
 )
@@ -112,7 +114,10 @@ func checkUnauthorizedConfig(currentDir string, currentActiveChain []string, tar
 	if !isInActiveChain {
 		// Current directory has local config but is not authorized
 		suggestion := "dirvana allow " + currentDir
-		if targetShell != "" {
+		if targetShell == ShellNu {
+			// The Nushell hook only runs when the directory changes
+			suggestion += "\n💡 Then leave and re-enter the directory to reload"
+		} else if targetShell != "" {
 			suggestion += "\n💡 Then reload with: eval \"$(DIRVANA_SHELL=" + targetShell + " dirvana export)\""
 		} else {
 			suggestion += "\n💡 Then reload with: eval \"$(dirvana export)\""
@@ -166,7 +171,7 @@ func loadAndMergeConfigs(currentActiveChain []string, comps *components, log *lo
 		functions := keysFromMap(cfg.Functions)
 		envVars := mergeTwoKeyLists(staticEnv, shellEnv)
 		commandMap := buildCommandMap(aliases, cfg.Functions)
-		completionMap := buildCompletionMap(aliases)
+		completionMap := buildCompletionMap(withFunctionCompletions(aliases, cfg.FunctionCompletions))
 
 		entry := &cache.Entry{
 			Path:          configDir,
@@ -217,6 +222,12 @@ func cacheMergedConfig(currentDir string, hierarchyHash string, hierarchyPaths [
 		Version:             version.Version,
 		MergedCommandMap:    mergedCommandMap,
 		MergedCompletionMap: mergedCompletionMap,
+		MergedParamsMap:     buildParamsMap(aliases),
+		MergedSourcesMap:    buildSourcesMap(withFunctionCompletions(aliases, mergedConfig.FunctionCompletions)),
+		MergedMatchMap:      buildMatchMap(withFunctionCompletions(aliases, mergedConfig.FunctionCompletions)),
+		MergedArgsMap:       buildArgsMap(aliases),
+		MergedEnvMap:        buildAliasEnvMap(aliases),
+		MergedCwdMap:        buildCwdMap(aliases),
 		HierarchyHash:       hierarchyHash,
 		HierarchyPaths:      hierarchyPaths,
 		// Store cleanup data only for directories with local config
@@ -247,6 +258,39 @@ func cacheMergedConfig(currentDir string, hierarchyHash string, hierarchyPaths [
 	}
 }
 
+// generateShimCode keeps the alias shim directory on PATH in sync with the active context.
+// Shims live in a per-project directory (keyed by the deepest active config) and are
+// regenerated when the hierarchy hash changes. When shims are disabled or no context is
+// active, the previous shim directory is removed from PATH.
+func generateShimCode(mergedConfig *config.Config, activeChain []string, hierarchyHash, cachePath, shell string, log *logger.Logger) string {
+	prevShimDir := os.Getenv(shim.DirEnvVar)
+	newShimDir := ""
+
+	if mergedConfig != nil && mergedConfig.Shims && len(activeChain) > 0 {
+		projectDir := activeChain[len(activeChain)-1]
+		shimDir := shim.Dir(filepath.Dir(cachePath), projectDir)
+		aliases := keysFromAliasMap(mergedConfig.GetAliases())
+
+		regenerated, err := shim.Ensure(shimDir, projectDir, hierarchyHash, getExecutablePath(), aliases)
+		if err != nil {
+			log.Warn().Err(err).Str("dir", shimDir).Msg("Failed to generate alias shims")
+		} else {
+			newShimDir = shimDir
+			log.Debug().
+				Str("shim_dir", shimDir).
+				Bool("regenerated", regenerated).
+				Int("shims", len(aliases)).
+				Msg("Alias shims ready")
+		}
+	}
+
+	if prevShimDir == newShimDir {
+		return ""
+	}
+
+	return shellpkg.GenerateShimPathCode(prevShimDir, newShimDir, shell)
+}
+
 // Export generates and outputs shell code for the current directory
 func Export(params ExportParams) error {
 	// Check if Dirvana is disabled via environment variable
@@ -288,6 +332,7 @@ func Export(params ExportParams) error {
 
 	// If no active configs in current directory, just output cleanup and return
 	if len(chains.current) == 0 {
+		cleanupCode += generateShimCode(nil, nil, "", params.CachePath, targetShell, log)
 		if cleanupCode != "" {
 			fmt.Print(cleanupCode)
 		} else {
@@ -305,6 +350,7 @@ func Export(params ExportParams) error {
 
 	// If no valid configs loaded, output cleanup and return
 	if mergedConfig == nil {
+		cleanupCode += generateShimCode(nil, nil, "", params.CachePath, targetShell, log)
 		if cleanupCode != "" {
 			fmt.Print(cleanupCode)
 		} else {
@@ -318,7 +364,7 @@ func Export(params ExportParams) error {
 	// Build merged command and completion maps from the final merged config
 	aliases := mergedConfig.GetAliases()
 	mergedCommandMap := buildCommandMap(aliases, mergedConfig.Functions)
-	mergedCompletionMap := buildCompletionMap(aliases)
+	mergedCompletionMap := buildCompletionMap(withFunctionCompletions(aliases, mergedConfig.FunctionCompletions))
 
 	// Compute hierarchy hash from all active config paths
 	hierarchyHash, hierarchyPaths, err := computeHierarchyHash(chains.current, comps.config)
@@ -372,6 +418,10 @@ func Export(params ExportParams) error {
 	shellCode := comps.shell.Generate(aliases, mergedConfig.Functions, staticEnv, shellEnv)
 	timer.Mark("generate_shell")
 
+	// Keep alias shims on PATH for non-interactive tools
+	shellCode += generateShimCode(mergedConfig, chains.current, hierarchyHash, params.CachePath, targetShell, log)
+	timer.Mark("shims")
+
 	// Prepend cleanup code if needed
 	if cleanupCode != "" {
 		shellCode = cleanupCode + "\n" + shellCode
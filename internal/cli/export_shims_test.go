@@ -0,0 +1,58 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/NikitaCOEUR/dirvana/internal/config"
+	"github.com/NikitaCOEUR/dirvana/internal/logger"
+	"github.com/NikitaCOEUR/dirvana/internal/shim"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateShimCode_Enabled(t *testing.T) {
+	tmpDir := t.TempDir()
+	cachePath := filepath.Join(tmpDir, "cache.json")
+	projectDir := filepath.Join(tmpDir, "project")
+	t.Setenv(shim.DirEnvVar, "")
+
+	cfg := &config.Config{
+		Shims:   true,
+		Aliases: map[string]interface{}{"k": "kubectl"},
+	}
+
+	code := generateShimCode(cfg, []string{projectDir}, "hash", cachePath, "bash", logger.New("error", nil))
+
+	shimDir := shim.Dir(tmpDir, projectDir)
+	assert.Contains(t, code, "export PATH='"+shimDir+"'")
+	assert.FileExists(t, filepath.Join(shimDir, "k"))
+
+	// Already on PATH: nothing to emit
+	t.Setenv(shim.DirEnvVar, shimDir)
+	code = generateShimCode(cfg, []string{projectDir}, "hash", cachePath, "bash", logger.New("error", nil))
+	assert.Empty(t, code)
+}
+
+func TestGenerateShimCode_Leaving(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv(shim.DirEnvVar, "/old/shims")
+
+	code := generateShimCode(nil, nil, "", filepath.Join(tmpDir, "cache.json"), "bash", logger.New("error", nil))
+
+	assert.Contains(t, code, "/old/shims")
+	assert.Contains(t, code, "unset DIRVANA_SHIM_DIR")
+}
+
+func TestGenerateShimCode_Disabled(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv(shim.DirEnvVar, "")
+
+	cfg := &config.Config{Aliases: map[string]interface{}{"k": "kubectl"}}
+	code := generateShimCode(cfg, []string{tmpDir}, "hash", filepath.Join(tmpDir, "cache.json"), "bash", logger.New("error", nil))
+
+	assert.Empty(t, code)
+	_, err := os.Stat(shim.RootDir(tmpDir))
+	require.True(t, os.IsNotExist(err))
+}
@@ -76,6 +76,9 @@ func mergeTwoKeyLists(map1, map2 map[string]string) []string {
 	return keys
 }
 
+// functionCommandPrefix marks functions in command maps, as they are shell code, not commands
+const functionCommandPrefix = "__dirvana_function__"
+
 // buildCommandMap creates a map of alias/function names to their commands
 // This is used by dirvana exec to resolve aliases
 func buildCommandMap(aliases map[string]config.AliasConfig, functions map[string]string) map[string]string {
@@ -90,12 +93,95 @@ func buildCommandMap(aliases map[string]config.AliasConfig, functions map[string
 	// For now, functions are stored but will need special handling in exec
 	for name := range functions {
 		// Mark functions with a special prefix so we know to handle them differently
-		commandMap[name] = "__dirvana_function__" + name
+		commandMap[name] = functionCommandPrefix + name
 	}
 
 	return commandMap
 }
 
+// buildParamsMap creates a map of alias names to their typed parameters
+func buildParamsMap(aliases map[string]config.AliasConfig) map[string][]config.AliasParam {
+	paramsMap := make(map[string][]config.AliasParam)
+	for name, aliasConf := range aliases {
+		if len(aliasConf.Params) > 0 {
+			paramsMap[name] = aliasConf.Params
+		}
+	}
+	return paramsMap
+}
+
+// buildArgsMap creates a map of alias names to the arguments prepended to the user's arguments
+func buildArgsMap(aliases map[string]config.AliasConfig) map[string][]string {
+	argsMap := make(map[string][]string)
+	for name, aliasConf := range aliases {
+		if len(aliasConf.Args.Prepend) > 0 {
+			argsMap[name] = aliasConf.Args.Prepend
+		}
+	}
+	return argsMap
+}
+
+// buildAliasEnvMap creates a map of alias names to the environment variables of their command
+func buildAliasEnvMap(aliases map[string]config.AliasConfig) map[string]map[string]string {
+	envMap := make(map[string]map[string]string)
+	for name, aliasConf := range aliases {
+		if len(aliasConf.Env) > 0 {
+			envMap[name] = aliasConf.Env
+		}
+	}
+	return envMap
+}
+
+// buildCwdMap creates a map of alias names to the working directory of their command
+func buildCwdMap(aliases map[string]config.AliasConfig) map[string]string {
+	cwdMap := make(map[string]string)
+	for name, aliasConf := range aliases {
+		if aliasConf.Cwd != "" {
+			cwdMap[name] = aliasConf.Cwd
+		}
+	}
+	return cwdMap
+}
+
+// withFunctionCompletions returns the aliases together with the functions declaring a completion,
+// so that completion maps are built the same way for both. Functions keep their marker command.
+func withFunctionCompletions(aliases map[string]config.AliasConfig, functionCompletions map[string]interface{}) map[string]config.AliasConfig {
+	if len(functionCompletions) == 0 {
+		return aliases
+	}
+
+	result := make(map[string]config.AliasConfig, len(aliases)+len(functionCompletions))
+	for name, aliasConf := range aliases {
+		result[name] = aliasConf
+	}
+	for name, completion := range functionCompletions {
+		result[name] = config.AliasConfig{Command: functionCommandPrefix + name, Completion: completion}
+	}
+	return result
+}
+
+// buildSourcesMap creates a map of alias names to their custom completion sources
+func buildSourcesMap(aliases map[string]config.AliasConfig) map[string]config.CompletionConfig {
+	sourcesMap := make(map[string]config.CompletionConfig)
+	for name, aliasConf := range aliases {
+		if compCfg, ok := aliasConf.Completion.(config.CompletionConfig); ok && compCfg.HasSource() {
+			sourcesMap[name] = compCfg
+		}
+	}
+	return sourcesMap
+}
+
+// buildMatchMap creates a map of alias names to their completion matching mode
+func buildMatchMap(aliases map[string]config.AliasConfig) map[string]string {
+	matchMap := make(map[string]string)
+	for name, aliasConf := range aliases {
+		if compCfg, ok := aliasConf.Completion.(config.CompletionConfig); ok && compCfg.Match != "" {
+			matchMap[name] = compCfg.Match
+		}
+	}
+	return matchMap
+}
+
 // buildCompletionMap creates a map of alias names to completion commands
 // Uses explicit completion if specified, otherwise uses the command
 func buildCompletionMap(aliases map[string]config.AliasConfig) map[string]string {
@@ -150,10 +236,36 @@ func getMergedAliasConfigs(currentDir string, cachePath string, authPath string)
 	return aliases, functions, nil
 }
 
+// commandMaps holds the merged per-alias data used by completion
+type commandMaps struct {
+	Commands    map[string]string                  // Alias/function name to command
+	Completions map[string]string                  // Alias name to completion command override
+	Params      map[string][]config.AliasParam     // Alias name to typed parameters
+	Sources     map[string]config.CompletionConfig // Alias name to custom completion sources
+	Match       map[string]string                  // Alias name to completion matching mode
+	Args        map[string][]string                // Alias name to arguments prepended to the user's
+	Env         map[string]map[string]string       // Alias name to environment of the command
+	Cwd         map[string]string                  // Alias name to working directory of the command
+}
+
+// commandMapsFromEntry extracts the merged maps of a cache entry
+func commandMapsFromEntry(entry *cache.Entry) *commandMaps {
+	return &commandMaps{
+		Commands:    entry.MergedCommandMap,
+		Completions: entry.MergedCompletionMap,
+		Params:      entry.MergedParamsMap,
+		Sources:     entry.MergedSourcesMap,
+		Match:       entry.MergedMatchMap,
+		Args:        entry.MergedArgsMap,
+		Env:         entry.MergedEnvMap,
+		Cwd:         entry.MergedCwdMap,
+	}
+}
+
 // getMergedCommandMaps returns merged CommandMaps and CompletionMaps for a directory.
 // Respects the full config hierarchy including global config, ignore_global, local_only, and authorization.
-// Returns nil maps if no context is found or not authorized.
-func getMergedCommandMaps(currentDir string, cachePath string, authPath string) (commandMap, completionMap map[string]string, err error) {
+// Returns empty maps if no context is found or not authorized.
+func getMergedCommandMaps(currentDir string, cachePath string, authPath string) (maps *commandMaps, err error) {
 	ctx := context.Background()
 	defer trace.Region(ctx, "getMergedCommandMaps")()
 
@@ -164,14 +276,14 @@ func getMergedCommandMaps(currentDir string, cachePath string, authPath string)
 		cacheStore, err = cache.New(cachePath)
 	})
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
 	if cachedEntry, found := cacheStore.Get(currentDir); found {
 		// Quick validation: check version and TTL only (no file I/O)
 		if isCacheValidFast(cachedEntry, version.Version) {
 			trace.Log(ctx, "cache", "hit-fast")
-			return cachedEntry.MergedCommandMap, cachedEntry.MergedCompletionMap, nil
+			return commandMapsFromEntry(cachedEntry), nil
 		}
 	}
 
@@ -182,7 +294,7 @@ func getMergedCommandMaps(currentDir string, cachePath string, authPath string)
 		comps, err = initializeComponentsWithCache(cachePath, authPath, cacheStore)
 	})
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
 	// Try full cache validation (with hash check)
@@ -195,7 +307,7 @@ func getMergedCommandMaps(currentDir string, cachePath string, authPath string)
 		if isValid {
 			// Cache hit after full validation
 			trace.Log(ctx, "cache", "hit-validated")
-			return validEntry.MergedCommandMap, validEntry.MergedCompletionMap, nil
+			return commandMapsFromEntry(validEntry), nil
 		}
 		// Cache invalid, fall through to hierarchy load
 		trace.Log(ctx, "cache", "invalid")
@@ -210,20 +322,27 @@ func getMergedCommandMaps(currentDir string, cachePath string, authPath string)
 		mergedConfig, _, err = comps.config.LoadHierarchyWithAuth(currentDir, comps.auth)
 	})
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
 	// If no config was loaded, return empty maps
 	if mergedConfig == nil {
-		return make(map[string]string), make(map[string]string), nil
+		return &commandMaps{Commands: make(map[string]string), Completions: make(map[string]string)}, nil
 	}
 
 	// Build command maps from the merged config
 	aliases := mergedConfig.GetAliases()
-	commandMap = buildCommandMap(aliases, mergedConfig.Functions)
-	completionMap = buildCompletionMap(aliases)
-
-	return commandMap, completionMap, nil
+	completable := withFunctionCompletions(aliases, mergedConfig.FunctionCompletions)
+	return &commandMaps{
+		Commands:    buildCommandMap(aliases, mergedConfig.Functions),
+		Completions: buildCompletionMap(completable),
+		Params:      buildParamsMap(aliases),
+		Sources:     buildSourcesMap(completable),
+		Match:       buildMatchMap(completable),
+		Args:        buildArgsMap(aliases),
+		Env:         buildAliasEnvMap(aliases),
+		Cwd:         buildCwdMap(aliases),
+	}, nil
 }
 
 // isCacheValidFast performs quick cache validation without file I/O
@@ -536,3 +536,75 @@ func TestBuildCompletionMap_EmptyCompletionString(t *testing.T) {
 	// Empty string should use command
 	assert.Equal(t, "echo test", completionMap["empty_completion"])
 }
+
+func TestBuildParamsMap(t *testing.T) {
+	params := []config.AliasParam{{Name: "env", Type: config.ParamTypeEnum, Values: []string{"dev", "prod"}}}
+	aliases := map[string]config.AliasConfig{
+		"deploy": {Command: "helm upgrade", Params: params},
+		"ll":     {Command: "ls -la"},
+	}
+
+	paramsMap := buildParamsMap(aliases)
+
+	assert.Equal(t, map[string][]config.AliasParam{"deploy": params}, paramsMap)
+}
+
+func TestBuildAliasExecOptionMaps(t *testing.T) {
+	aliases := map[string]config.AliasConfig{
+		"kprod": {Command: "kubectl", Args: config.AliasArgs{Prepend: []string{"--context", "prod"}, Append: []string{"-o", "wide"}}},
+		"tf":    {Command: "terraform", Env: map[string]string{"TF_IN_AUTOMATION": "1"}, Cwd: "infra"},
+		"ll":    {Command: "ls -la"},
+	}
+
+	assert.Equal(t, map[string][]string{"kprod": {"--context", "prod"}}, buildArgsMap(aliases))
+	assert.Equal(t, map[string]map[string]string{"tf": {"TF_IN_AUTOMATION": "1"}}, buildAliasEnvMap(aliases))
+	assert.Equal(t, map[string]string{"tf": "infra"}, buildCwdMap(aliases))
+}
+
+func TestBuildSourcesMap(t *testing.T) {
+	aliases := map[string]config.AliasConfig{
+		"deploy": {Command: "./deploy.sh", Completion: config.CompletionConfig{Values: []config.CompletionItem{{Value: "prod"}}}},
+		"legacy": {Command: "./legacy.sh", Completion: config.CompletionConfig{Bash: "complete -W 'a' legacy"}},
+		"k":      {Command: "kubectl", Completion: "kubectl"},
+	}
+
+	sourcesMap := buildSourcesMap(aliases)
+
+	assert.Len(t, sourcesMap, 1)
+	assert.Equal(t, "prod", sourcesMap["deploy"].Values[0].Value)
+}
+
+func TestBuildMatchMap(t *testing.T) {
+	aliases := map[string]config.AliasConfig{
+		"k":      {Command: "kubectl", Completion: config.CompletionConfig{Match: "fuzzy"}},
+		"deploy": {Command: "./deploy.sh", Completion: config.CompletionConfig{Values: []config.CompletionItem{{Value: "prod"}}}},
+		"g":      {Command: "git"},
+	}
+
+	assert.Equal(t, map[string]string{"k": "fuzzy"}, buildMatchMap(aliases))
+}
+
+func TestWithFunctionCompletions(t *testing.T) {
+	aliases := map[string]config.AliasConfig{"k": {Command: "kubectl"}}
+	functionCompletions := map[string]interface{}{
+		"gsw":    "git switch",
+		"deploy": config.CompletionConfig{Dirs: true},
+		"quiet":  false,
+	}
+
+	completable := withFunctionCompletions(aliases, functionCompletions)
+
+	assert.Len(t, completable, 4)
+	assert.Len(t, aliases, 1, "aliases must not be modified")
+
+	completionMap := buildCompletionMap(completable)
+	assert.Equal(t, "kubectl", completionMap["k"])
+	assert.Equal(t, "git switch", completionMap["gsw"])
+	assert.NotContains(t, completionMap, "quiet")
+
+	sourcesMap := buildSourcesMap(completable)
+	assert.True(t, sourcesMap["deploy"].Dirs)
+
+	// Without function completions, the aliases are returned as is
+	assert.Equal(t, aliases, withFunctionCompletions(aliases, nil))
+}
@@ -4,6 +4,7 @@ package cli
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 
 	shellpkg "github.com/NikitaCOEUR/dirvana/internal/shell"
@@ -17,13 +18,15 @@ const (
 	ShellZsh = "zsh"
 	// ShellFish represents fish shell
 	ShellFish = "fish"
+	// ShellNu represents Nushell
+	ShellNu = "nu"
 )
 
 // DetectShell determines the shell type based on the flag or environment.
 // Detection priority:
 // 1. Explicit shell flag (if not "auto")
 // 2. DIRVANA_SHELL env var (set by hook, most reliable)
-// 3. Shell-specific version variables (FISH_VERSION, ZSH_VERSION, BASH_VERSION)
+// 3. Shell-specific version variables (NU_VERSION, FISH_VERSION, ZSH_VERSION, BASH_VERSION)
 // 4. Parent process detection (Linux/macOS via /proc)
 // 5. SHELL env var (login shell, less reliable)
 // 6. Default to bash
@@ -38,6 +41,10 @@ func DetectShell(shellFlag string) string {
 	}
 
 	// Try shell-specific version variables (most reliable runtime detection)
+	// Nushell exports NU_VERSION to the commands it runs
+	if os.Getenv("NU_VERSION") != "" {
+		return ShellNu
+	}
 	if os.Getenv("FISH_VERSION") != "" {
 		return ShellFish
 	}
@@ -65,6 +72,9 @@ func DetectShell(shellFlag string) string {
 // parseShellFromPath extracts shell type from a path like "/bin/zsh" or "/usr/bin/fish"
 func parseShellFromPath(path string) string {
 	path = strings.ToLower(path)
+	if isNuExecutable(path) {
+		return ShellNu
+	}
 	if strings.Contains(path, "fish") {
 		return ShellFish
 	}
@@ -81,6 +91,10 @@ func parseShellFromPath(path string) string {
 // This is a pure function that can be easily tested
 func parseShellFromCmdline(cmdline string) string {
 	cmdline = strings.ToLower(cmdline)
+	// Arguments are separated by NUL bytes, the executable comes first
+	if executable, _, _ := strings.Cut(cmdline, "\x00"); isNuExecutable(executable) {
+		return ShellNu
+	}
 	if strings.Contains(cmdline, "fish") {
 		return ShellFish
 	}
@@ -93,6 +107,13 @@ func parseShellFromCmdline(cmdline string) string {
 	return ""
 }
 
+// isNuExecutable reports whether a lowercase path is the Nushell executable. Its name is too
+// short to be searched in the whole path like the other shells ("gnu", "menu").
+func isNuExecutable(path string) bool {
+	name := filepath.Base(path)
+	return name == "nu" || name == "nu.exe"
+}
+
 // detectShellFromParentProcess tries to detect the shell by reading the parent process name
 func detectShellFromParentProcess() string {
 	// This works on Linux and macOS
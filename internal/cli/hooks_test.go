@@ -112,6 +112,14 @@ func TestGenerateHookCode_NotEmpty(t *testing.T) {
 	}
 }
 
+func TestGenerateHookCode_Nu(t *testing.T) {
+	code := GenerateHookCode(ShellNu)
+	assert.Contains(t, code, "$env.config.hooks.env_change.PWD")
+	assert.Contains(t, code, "dirvana export")
+	assert.Contains(t, code, "$env.DIRVANA_SHELL = 'nu'")
+	assert.NotContains(t, code, "PROMPT_COMMAND")
+}
+
 func TestGenerateHookCode_DefaultShell(t *testing.T) {
 	// Test with an unknown shell - should default to bash behavior
 	code := GenerateHookCode("unknown")
@@ -128,7 +136,7 @@ func TestDetectShellFromParentProcess(t *testing.T) {
 	result := detectShellFromParentProcess()
 
 	// Should return either a valid shell name or empty string
-	assert.Contains(t, []string{"", ShellBash, ShellZsh, ShellFish}, result)
+	assert.Contains(t, []string{"", ShellBash, ShellZsh, ShellFish, ShellNu}, result)
 }
 
 func TestDetectShell_WithDirvanaShellEnv(t *testing.T) {
@@ -144,6 +152,7 @@ func TestDetectShell_FallbackOrder(t *testing.T) {
 	_ = os.Unsetenv("DIRVANA_SHELL")
 	_ = os.Unsetenv("SHELL")
 	_ = os.Unsetenv("PSModulePath")
+	_ = os.Unsetenv("NU_VERSION")
 	_ = os.Unsetenv("FISH_VERSION")
 	_ = os.Unsetenv("ZSH_VERSION")
 	_ = os.Unsetenv("BASH_VERSION")
@@ -178,12 +187,19 @@ func TestDetectShell_VersionVariables(t *testing.T) {
 			envVal: "5.1.16",
 			want:   ShellBash,
 		},
+		{
+			name:   "detect nu via NU_VERSION",
+			envVar: "NU_VERSION",
+			envVal: "0.101.0",
+			want:   ShellNu,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			// Clear all detection env vars
 			_ = os.Unsetenv("DIRVANA_SHELL")
+			_ = os.Unsetenv("NU_VERSION")
 			_ = os.Unsetenv("FISH_VERSION")
 			_ = os.Unsetenv("ZSH_VERSION")
 			_ = os.Unsetenv("BASH_VERSION")
@@ -202,6 +218,7 @@ func TestDetectShell_PriorityOrder(t *testing.T) {
 	// DIRVANA_SHELL should take priority over version variables
 	t.Run("DIRVANA_SHELL overrides version variables", func(t *testing.T) {
 		_ = os.Unsetenv("DIRVANA_SHELL")
+		_ = os.Unsetenv("NU_VERSION")
 		_ = os.Unsetenv("FISH_VERSION")
 		_ = os.Unsetenv("ZSH_VERSION")
 		_ = os.Unsetenv("BASH_VERSION")
@@ -217,6 +234,7 @@ func TestDetectShell_PriorityOrder(t *testing.T) {
 	// Version variables should take priority over SHELL env var
 	t.Run("version variables override SHELL env var", func(t *testing.T) {
 		_ = os.Unsetenv("DIRVANA_SHELL")
+		_ = os.Unsetenv("NU_VERSION")
 		_ = os.Unsetenv("FISH_VERSION")
 		_ = os.Unsetenv("ZSH_VERSION")
 		_ = os.Unsetenv("BASH_VERSION")
@@ -270,6 +288,16 @@ func TestParseShellFromPath(t *testing.T) {
 			path: "/usr/bin/ZsH",
 			want: ShellZsh,
 		},
+		{
+			name: "nu absolute path",
+			path: "/home/user/.cargo/bin/nu",
+			want: ShellNu,
+		},
+		{
+			name: "nu not matched inside another name",
+			path: "/usr/bin/gnu-sh",
+			want: "",
+		},
 		{
 			name: "unknown shell",
 			path: "/bin/sh",
@@ -326,6 +354,16 @@ func TestParseShellFromCmdline(t *testing.T) {
 			cmdline: "/usr/bin/fish",
 			want:    ShellFish,
 		},
+		{
+			name:    "nu with arguments",
+			cmdline: "/usr/bin/nu\x00--login",
+			want:    ShellNu,
+		},
+		{
+			name:    "nu as an argument of another command",
+			cmdline: "/usr/bin/menu\x00nu",
+			want:    "",
+		},
 		{
 			name:    "empty cmdline",
 			cmdline: "",
@@ -380,6 +418,7 @@ func TestDetectShell_ParentProcessDetection(t *testing.T) {
 	// Clear DIRVANA_SHELL, SHELL, and version variables to force parent process detection
 	_ = os.Unsetenv("DIRVANA_SHELL")
 	_ = os.Unsetenv("SHELL")
+	_ = os.Unsetenv("NU_VERSION")
 	_ = os.Unsetenv("FISH_VERSION")
 	_ = os.Unsetenv("ZSH_VERSION")
 	_ = os.Unsetenv("BASH_VERSION")
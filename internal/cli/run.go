@@ -0,0 +1,217 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+
+	"github.com/NikitaCOEUR/dirvana/internal/config"
+	"github.com/NikitaCOEUR/dirvana/internal/derrors"
+	"github.com/NikitaCOEUR/dirvana/internal/logger"
+	"github.com/NikitaCOEUR/dirvana/internal/shell"
+	"github.com/NikitaCOEUR/dirvana/internal/shellctx"
+)
+
+// RunParams contains parameters for the Run command
+type RunParams struct {
+	CachePath string
+	AuthPath  string
+	LogLevel  string
+	Dir       string   // Directory whose environment is loaded (defaults to current directory)
+	Command   []string // Command and its arguments
+}
+
+// runEnvironment holds everything needed to execute a command in a directory's environment
+type runEnvironment struct {
+	Dir      string   // Absolute directory the command runs in
+	Env      []string // Process environment with the config's env vars applied
+	Preamble string   // Bash code defining aliases and functions (empty if none)
+}
+
+// Run executes a command inside a directory's Dirvana environment.
+// This is meant for non-interactive callers (cron, Makefiles, CI, editors)
+// that don't go through the shell hook.
+func Run(params RunParams) error {
+	log := logger.New(params.LogLevel, os.Stderr)
+
+	if len(params.Command) == 0 {
+		return derrors.NewValidationError("command", "command required: dirvana run [--dir path] -- <command> [args...]", nil)
+	}
+
+	runEnv, err := prepareRunEnvironment(params, log)
+	if err != nil {
+		return err
+	}
+
+	execPath, err := exec.LookPath("bash")
+	if err != nil {
+		return derrors.NewExecutionError(params.Command[0], "shell not found: bash", err)
+	}
+
+	argv := buildRunArgs(runEnv.Preamble, params.Command)
+
+	log.Debug().
+		Str("dir", runEnv.Dir).
+		Str("argv", fmt.Sprintf("%q", argv)).
+		Msg("Running command in dirvana environment")
+
+	// Aliases are resolved by 'dirvana exec' from the working directory,
+	// so the command must run from the directory whose environment was loaded
+	if err := os.Chdir(runEnv.Dir); err != nil {
+		return derrors.NewExecutionError(params.Command[0], "failed to change directory", err)
+	}
+
+	err = syscall.Exec(execPath, argv, runEnv.Env)
+
+	// If we reach here, syscall.Exec failed
+	return derrors.NewExecutionError(params.Command[0], "failed to execute command", err)
+}
+
+// prepareRunEnvironment loads the authorized hierarchy for the target directory
+// and computes the environment and shell preamble for the command
+func prepareRunEnvironment(params RunParams, log *logger.Logger) (*runEnvironment, error) {
+	dir, err := resolveRunDir(params.Dir)
+	if err != nil {
+		return nil, err
+	}
+
+	comps, err := initializeComponents(params.CachePath, params.AuthPath)
+	if err != nil {
+		return nil, err
+	}
+
+	chain := shellctx.GetActiveConfigChain(dir, comps.auth, comps.config)
+
+	// A local config that isn't part of the active chain is not authorized
+	if config.HasLocalConfig(dir) && !containsDir(chain, dir) {
+		return nil, derrors.NewAuthorizationError(dir, fmt.Sprintf("directory not authorized, run 'dirvana allow %s' first", dir), nil)
+	}
+
+	if len(chain) == 0 {
+		return nil, derrors.NewNotFoundError(dir, fmt.Sprintf("no dirvana context found for %s", dir))
+	}
+
+	mergedConfig, _, err := comps.config.LoadHierarchyWithAuth(dir, comps.auth)
+	if err != nil {
+		return nil, derrors.NewConfigurationError(dir, "failed to load configuration", err)
+	}
+
+	staticEnv, shellEnv := mergedConfig.GetEnvVars()
+
+	// Shell commands are approved for the deepest config of the chain,
+	// which is where the interactive hook records the approval
+	approvalDir := chain[len(chain)-1]
+	if comps.auth.RequiresShellApproval(approvalDir, shellEnv) {
+		return nil, derrors.NewShellApprovalError(approvalDir,
+			fmt.Sprintf("shell commands not approved, cd into %s in an interactive shell to review and approve them", approvalDir), nil)
+	}
+
+	env := applyEnvOverrides(os.Environ(), staticEnv)
+	env = evaluateShellEnv(dir, env, shellEnv, log)
+
+	return &runEnvironment{
+		Dir:      dir,
+		Env:      env,
+		Preamble: shell.GenerateExportedFunctions(mergedConfig.GetAliases(), mergedConfig.Functions, getExecutablePath()),
+	}, nil
+}
+
+// resolveRunDir returns the absolute target directory, defaulting to the current directory
+func resolveRunDir(dir string) (string, error) {
+	if dir == "" {
+		currentDir, err := os.Getwd()
+		if err != nil {
+			return "", derrors.NewExecutionError("run", "failed to get current directory", err)
+		}
+		return currentDir, nil
+	}
+
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", derrors.NewExecutionError("run", "failed to resolve directory", err)
+	}
+
+	info, err := os.Stat(absDir)
+	if err != nil || !info.IsDir() {
+		return "", derrors.NewNotFoundError(absDir, fmt.Sprintf("directory not found: %s", absDir))
+	}
+
+	return absDir, nil
+}
+
+// containsDir checks if a directory is part of a config chain
+func containsDir(chain []string, dir string) bool {
+	for _, d := range chain {
+		if d == dir {
+			return true
+		}
+	}
+	return false
+}
+
+// applyEnvOverrides returns a copy of base (KEY=value entries) with overrides applied
+func applyEnvOverrides(base []string, overrides map[string]string) []string {
+	env := make([]string, 0, len(base)+len(overrides))
+	for _, entry := range base {
+		key, _, _ := strings.Cut(entry, "=")
+		if _, overridden := overrides[key]; overridden {
+			continue
+		}
+		env = append(env, entry)
+	}
+
+	for _, key := range sortedStringKeys(overrides) {
+		env = append(env, key+"="+overrides[key])
+	}
+
+	return env
+}
+
+// evaluateShellEnv runs dynamic env var commands in order, like the generated
+// 'export VAR="$(cmd)"' lines do, so each command sees the previous results
+func evaluateShellEnv(dir string, env []string, shellEnv map[string]string, log *logger.Logger) []string {
+	for _, key := range sortedStringKeys(shellEnv) {
+		cmd := exec.Command("bash", "--norc", "--noprofile", "-c", shellEnv[key])
+		cmd.Dir = dir
+		cmd.Env = env
+		cmd.Stderr = os.Stderr
+
+		output, err := cmd.Output()
+		if err != nil {
+			// Same semantics as command substitution: keep whatever was printed
+			log.Warn().Err(err).Str("var", key).Msg("Dynamic environment variable command failed")
+		}
+
+		// Command substitution strips trailing newlines
+		value := strings.TrimRight(string(output), "\n")
+		env = applyEnvOverrides(env, map[string]string{key: value})
+	}
+
+	return env
+}
+
+// buildRunArgs builds the bash argv that defines aliases/functions and runs the command
+func buildRunArgs(preamble string, command []string) []string {
+	argv := []string{"bash", "--norc", "--noprofile", "-c", preamble + `"$@"`, "bash"}
+	return append(argv, command...)
+}
+
+// getExecutablePath returns the absolute path of the running dirvana binary.
+// Non-interactive environments (cron) often have a minimal PATH without dirvana.
+func getExecutablePath() string {
+	if path, err := os.Executable(); err == nil {
+		return path
+	}
+	return getBinaryPath()
+}
+
+// sortedStringKeys returns the sorted keys of a map[string]string
+func sortedStringKeys(m map[string]string) []string {
+	keys := keysFromMap(m)
+	sort.Strings(keys)
+	return keys
+}
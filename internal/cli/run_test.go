@@ -0,0 +1,166 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/NikitaCOEUR/dirvana/internal/auth"
+	"github.com/NikitaCOEUR/dirvana/internal/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupRunProject creates a project directory with the given config and returns its paths
+func setupRunProject(t *testing.T, configContent string) (workDir, cachePath, authPath string) {
+	t.Helper()
+	tmpDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(tmpDir, "config"))
+
+	cachePath = filepath.Join(tmpDir, "cache.json")
+	authPath = filepath.Join(tmpDir, "auth.json")
+	workDir = filepath.Join(tmpDir, "work")
+	require.NoError(t, os.MkdirAll(workDir, 0755))
+
+	// Resolve symlinks for macOS compatibility
+	workDir, err := filepath.EvalSymlinks(workDir)
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(filepath.Join(workDir, ".dirvana.yml"), []byte(configContent), 0644))
+	return workDir, cachePath, authPath
+}
+
+func TestRun_NoCommand(t *testing.T) {
+	err := Run(RunParams{LogLevel: "error"})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "command required")
+}
+
+func TestPrepareRunEnvironment_NotAuthorized(t *testing.T) {
+	workDir, cachePath, authPath := setupRunProject(t, "env:\n  FOO: bar\n")
+
+	_, err := prepareRunEnvironment(RunParams{
+		CachePath: cachePath,
+		AuthPath:  authPath,
+		Dir:       workDir,
+		Command:   []string{"env"},
+	}, logger.New("error", nil))
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "directory not authorized")
+}
+
+func TestPrepareRunEnvironment_NoContext(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(tmpDir, "config"))
+
+	_, err := prepareRunEnvironment(RunParams{
+		CachePath: filepath.Join(tmpDir, "cache.json"),
+		AuthPath:  filepath.Join(tmpDir, "auth.json"),
+		Dir:       tmpDir,
+		Command:   []string{"env"},
+	}, logger.New("error", nil))
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no dirvana context found")
+}
+
+func TestPrepareRunEnvironment_DirNotFound(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	_, err := prepareRunEnvironment(RunParams{
+		CachePath: filepath.Join(tmpDir, "cache.json"),
+		AuthPath:  filepath.Join(tmpDir, "auth.json"),
+		Dir:       filepath.Join(tmpDir, "missing"),
+		Command:   []string{"env"},
+	}, logger.New("error", nil))
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "directory not found")
+}
+
+func TestPrepareRunEnvironment_ShellApprovalRequired(t *testing.T) {
+	workDir, cachePath, authPath := setupRunProject(t, "env:\n  BRANCH:\n    sh: echo main\n")
+
+	authMgr, err := auth.New(authPath)
+	require.NoError(t, err)
+	require.NoError(t, authMgr.Allow(workDir))
+
+	_, err = prepareRunEnvironment(RunParams{
+		CachePath: cachePath,
+		AuthPath:  authPath,
+		Dir:       workDir,
+		Command:   []string{"env"},
+	}, logger.New("error", nil))
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "shell commands not approved")
+}
+
+func TestPrepareRunEnvironment_Success(t *testing.T) {
+	workDir, cachePath, authPath := setupRunProject(t, `aliases:
+  hi: echo hello
+functions:
+  greet: echo "Hello, $1"
+env:
+  FOO: bar
+  DYN:
+    sh: echo "dyn-$FOO"
+`)
+
+	authMgr, err := auth.New(authPath)
+	require.NoError(t, err)
+	require.NoError(t, authMgr.Allow(workDir))
+	require.NoError(t, authMgr.ApproveShellCommands(workDir, map[string]string{"DYN": `echo "dyn-$FOO"`}))
+
+	// Run from a subdirectory to check that the hierarchy is resolved from --dir
+	subDir := filepath.Join(workDir, "sub")
+	require.NoError(t, os.MkdirAll(subDir, 0755))
+
+	runEnv, err := prepareRunEnvironment(RunParams{
+		CachePath: cachePath,
+		AuthPath:  authPath,
+		Dir:       subDir,
+		Command:   []string{"env"},
+	}, logger.New("error", nil))
+	require.NoError(t, err)
+
+	assert.Equal(t, subDir, runEnv.Dir)
+	assert.Contains(t, runEnv.Env, "FOO=bar")
+	assert.Contains(t, runEnv.Env, "DYN=dyn-bar")
+	assert.Contains(t, runEnv.Preamble, "export -f hi")
+	assert.Contains(t, runEnv.Preamble, "export -f greet")
+}
+
+func TestApplyEnvOverrides(t *testing.T) {
+	base := []string{"PATH=/usr/bin", "FOO=old", "EMPTY="}
+
+	env := applyEnvOverrides(base, map[string]string{"FOO": "new", "BAR": "baz"})
+
+	assert.Equal(t, []string{"PATH=/usr/bin", "EMPTY=", "BAR=baz", "FOO=new"}, env)
+	// Base slice must not be modified
+	assert.Equal(t, []string{"PATH=/usr/bin", "FOO=old", "EMPTY="}, base)
+}
+
+func TestEvaluateShellEnv_Order(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	env := evaluateShellEnv(tmpDir, []string{"PATH=" + os.Getenv("PATH")}, map[string]string{
+		"A_FIRST":  "echo one",
+		"B_SECOND": `echo "$A_FIRST-two"`,
+		"C_DIR":    "pwd",
+	}, logger.New("error", nil))
+
+	assert.Contains(t, env, "A_FIRST=one")
+	assert.Contains(t, env, "B_SECOND=one-two")
+	assert.Contains(t, env, "C_DIR="+tmpDir)
+}
+
+func TestBuildRunArgs(t *testing.T) {
+	argv := buildRunArgs("hi() { :; }\n", []string{"make", "-j4", "build"})
+
+	assert.Equal(t, []string{
+		"bash", "--norc", "--noprofile", "-c", "hi() { :; }\n\"$@\"", "bash",
+		"make", "-j4", "build",
+	}, argv)
+}
@@ -0,0 +1,150 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/NikitaCOEUR/dirvana/internal/auth"
+	"github.com/NikitaCOEUR/dirvana/internal/cache"
+	"github.com/NikitaCOEUR/dirvana/internal/completion"
+	"github.com/NikitaCOEUR/dirvana/internal/config"
+)
+
+// SelfCompletionParams contains parameters to complete the arguments of dirvana's own commands
+type SelfCompletionParams struct {
+	CachePath string
+	AuthPath  string
+	Dir       string   // Current directory
+	Args      []string // Arguments already typed after the command
+}
+
+// CompleteExecArgs completes 'dirvana exec': the aliases and functions active in the
+// current directory, described by the command they run
+func CompleteExecArgs(params SelfCompletionParams) []completion.Suggestion {
+	if len(params.Args) > 0 {
+		return nil
+	}
+
+	maps, err := getMergedCommandMaps(params.Dir, params.CachePath, params.AuthPath)
+	if err != nil {
+		return nil
+	}
+
+	suggestions := make([]completion.Suggestion, 0, len(maps.Commands))
+	for name, command := range maps.Commands {
+		description := command
+		if strings.HasPrefix(command, functionCommandPrefix) {
+			description = "function"
+		}
+		suggestions = append(suggestions, completion.Suggestion{Value: name, Description: description})
+	}
+	sortSuggestions(suggestions)
+	return suggestions
+}
+
+// CompleteRevokeArgs completes 'dirvana revoke': the authorized directories
+func CompleteRevokeArgs(params SelfCompletionParams) []completion.Suggestion {
+	if len(params.Args) > 0 {
+		return nil
+	}
+
+	authMgr, err := auth.New(params.AuthPath)
+	if err != nil {
+		return nil
+	}
+
+	paths := authMgr.List()
+	suggestions := make([]completion.Suggestion, 0, len(paths))
+	for _, path := range paths {
+		suggestion := completion.Suggestion{Value: path}
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			suggestion.Description = "directory no longer exists"
+		}
+		suggestions = append(suggestions, suggestion)
+	}
+	sortSuggestions(suggestions)
+	return suggestions
+}
+
+// CompleteAllowArgs completes 'dirvana allow': the subdirectories of the current directory
+// containing a Dirvana config, or all of them when none does
+func CompleteAllowArgs(params SelfCompletionParams) []completion.Suggestion {
+	if len(params.Args) > 0 {
+		return nil
+	}
+
+	entries, err := os.ReadDir(params.Dir)
+	if err != nil {
+		return nil
+	}
+
+	var authMgr *auth.Auth
+	if mgr, err := auth.New(params.AuthPath); err == nil {
+		authMgr = mgr
+	}
+
+	var configured, others []completion.Suggestion
+	for _, entry := range entries {
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		dir := filepath.Join(params.Dir, entry.Name())
+		if !config.HasLocalConfig(dir) {
+			others = append(others, completion.Suggestion{Value: entry.Name()})
+			continue
+		}
+
+		description := "not authorized"
+		if authMgr != nil {
+			if allowed, _ := authMgr.IsAllowed(dir); allowed {
+				description = "authorized"
+			}
+		}
+		configured = append(configured, completion.Suggestion{Value: entry.Name(), Description: description})
+	}
+
+	if len(configured) == 0 {
+		configured = others
+	}
+	sortSuggestions(configured)
+	return configured
+}
+
+// CompleteCleanArgs completes 'dirvana clean': its --all flag, described by the number of
+// cache entries it clears
+func CompleteCleanArgs(params SelfCompletionParams) []completion.Suggestion {
+	if len(params.Args) > 0 {
+		return nil
+	}
+
+	description := "Clear all cache entries"
+	if info, err := cache.GetCacheInfo(params.CachePath); err == nil {
+		description = fmt.Sprintf("Clear all %d cache entries", info.TotalEntries)
+	}
+	return []completion.Suggestion{{Value: "--all", Description: description}}
+}
+
+// PrintSelfSuggestions writes suggestions the way urfave/cli prints its own: 'value:description'
+// for zsh (colons in values escaped as '\:'), the value alone for other shells
+func PrintSelfSuggestions(w io.Writer, suggestions []completion.Suggestion) {
+	zsh := strings.HasSuffix(os.Getenv("SHELL"), "zsh")
+	for _, suggestion := range suggestions {
+		if zsh && suggestion.Description != "" {
+			value := strings.ReplaceAll(suggestion.Value, ":", `\:`)
+			_, _ = fmt.Fprintf(w, "%s:%s\n", value, suggestion.Description)
+		} else {
+			_, _ = fmt.Fprintln(w, suggestion.Value)
+		}
+	}
+}
+
+// sortSuggestions sorts suggestions by value
+func sortSuggestions(suggestions []completion.Suggestion) {
+	sort.Slice(suggestions, func(i, j int) bool {
+		return suggestions[i].Value < suggestions[j].Value
+	})
+}
@@ -0,0 +1,164 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/NikitaCOEUR/dirvana/internal/auth"
+	"github.com/NikitaCOEUR/dirvana/internal/cache"
+	"github.com/NikitaCOEUR/dirvana/internal/completion"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompleteExecArgs(t *testing.T) {
+	tmpDir := t.TempDir()
+	cachePath := filepath.Join(tmpDir, "cache.json")
+	authPath := filepath.Join(tmpDir, "auth.json")
+	projectDir := filepath.Join(tmpDir, "project")
+	require.NoError(t, os.MkdirAll(projectDir, 0755))
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(tmpDir, "config"))
+
+	require.NoError(t, os.WriteFile(filepath.Join(projectDir, ".dirvana.yml"), []byte(`aliases:
+  k: kubectl
+  tf:
+    command: terraform
+functions:
+  greet: echo "Hello $1"
+`), 0644))
+
+	authMgr, err := auth.New(authPath)
+	require.NoError(t, err)
+	require.NoError(t, authMgr.Allow(projectDir))
+
+	params := SelfCompletionParams{CachePath: cachePath, AuthPath: authPath, Dir: projectDir}
+	assert.Equal(t, []completion.Suggestion{
+		{Value: "greet", Description: "function"},
+		{Value: "k", Description: "kubectl"},
+		{Value: "tf", Description: "terraform"},
+	}, CompleteExecArgs(params))
+
+	t.Run("alias already typed", func(t *testing.T) {
+		params.Args = []string{"k"}
+		assert.Empty(t, CompleteExecArgs(params))
+	})
+
+	t.Run("unauthorized directory", func(t *testing.T) {
+		assert.Empty(t, CompleteExecArgs(SelfCompletionParams{
+			CachePath: cachePath,
+			AuthPath:  filepath.Join(tmpDir, "other-auth.json"),
+			Dir:       projectDir,
+		}))
+	})
+}
+
+func TestCompleteRevokeArgs(t *testing.T) {
+	tmpDir := t.TempDir()
+	authPath := filepath.Join(tmpDir, "auth.json")
+	projectB := filepath.Join(tmpDir, "b")
+	projectA := filepath.Join(tmpDir, "a")
+	gone := filepath.Join(tmpDir, "gone")
+	require.NoError(t, os.MkdirAll(projectA, 0755))
+	require.NoError(t, os.MkdirAll(projectB, 0755))
+
+	authMgr, err := auth.New(authPath)
+	require.NoError(t, err)
+	for _, dir := range []string{projectB, gone, projectA} {
+		require.NoError(t, authMgr.Allow(dir))
+	}
+
+	params := SelfCompletionParams{AuthPath: authPath, Dir: tmpDir}
+	assert.Equal(t, []completion.Suggestion{
+		{Value: projectA},
+		{Value: projectB},
+		{Value: gone, Description: "directory no longer exists"},
+	}, CompleteRevokeArgs(params))
+
+	params.Args = []string{projectA}
+	assert.Empty(t, CompleteRevokeArgs(params))
+}
+
+func TestCompleteAllowArgs(t *testing.T) {
+	tmpDir := t.TempDir()
+	authPath := filepath.Join(tmpDir, "auth.json")
+	workDir := filepath.Join(tmpDir, "work")
+	for _, dir := range []string{"api", "web", "docs", ".git"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(workDir, dir), 0755))
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(workDir, "README.md"), []byte("readme"), 0644))
+
+	params := SelfCompletionParams{AuthPath: authPath, Dir: workDir}
+
+	t.Run("no config: all subdirectories", func(t *testing.T) {
+		assert.Equal(t, []completion.Suggestion{
+			{Value: "api"},
+			{Value: "docs"},
+			{Value: "web"},
+		}, CompleteAllowArgs(params))
+	})
+
+	t.Run("directories with a config first", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(filepath.Join(workDir, "web", ".dirvana.yml"), []byte("aliases: {}\n"), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(workDir, "api", ".dirvana.yml"), []byte("aliases: {}\n"), 0644))
+
+		authMgr, err := auth.New(authPath)
+		require.NoError(t, err)
+		require.NoError(t, authMgr.Allow(filepath.Join(workDir, "api")))
+
+		assert.Equal(t, []completion.Suggestion{
+			{Value: "api", Description: "authorized"},
+			{Value: "web", Description: "not authorized"},
+		}, CompleteAllowArgs(params))
+	})
+
+	t.Run("path already typed", func(t *testing.T) {
+		params.Args = []string{"api"}
+		assert.Empty(t, CompleteAllowArgs(params))
+	})
+}
+
+func TestCompleteCleanArgs(t *testing.T) {
+	tmpDir := t.TempDir()
+	cachePath := filepath.Join(tmpDir, "cache.json")
+	params := SelfCompletionParams{CachePath: cachePath, Dir: tmpDir}
+
+	assert.Equal(t, []completion.Suggestion{
+		{Value: "--all", Description: "Clear all 0 cache entries"},
+	}, CompleteCleanArgs(params))
+
+	c, err := cache.New(cachePath)
+	require.NoError(t, err)
+	require.NoError(t, c.Set(&cache.Entry{Path: filepath.Join(tmpDir, "a")}))
+	require.NoError(t, c.Set(&cache.Entry{Path: filepath.Join(tmpDir, "b")}))
+
+	assert.Equal(t, []completion.Suggestion{
+		{Value: "--all", Description: "Clear all 2 cache entries"},
+	}, CompleteCleanArgs(params))
+
+	params.Args = []string{"--all"}
+	assert.Empty(t, CompleteCleanArgs(params))
+}
+
+func TestPrintSelfSuggestions(t *testing.T) {
+	suggestions := []completion.Suggestion{
+		{Value: "k", Description: "kubectl"},
+		{Value: "host:8080", Description: "server"},
+		{Value: "plain"},
+	}
+
+	t.Run("zsh", func(t *testing.T) {
+		t.Setenv("SHELL", "/bin/zsh")
+		var buf bytes.Buffer
+		PrintSelfSuggestions(&buf, suggestions)
+		assert.Equal(t, "k:kubectl\nhost\\:8080:server\nplain\n", buf.String())
+	})
+
+	t.Run("bash", func(t *testing.T) {
+		t.Setenv("SHELL", "/bin/bash")
+		var buf bytes.Buffer
+		PrintSelfSuggestions(&buf, suggestions)
+		assert.Equal(t, "k\nhost:8080\nplain\n", buf.String())
+	})
+}
@@ -0,0 +1,94 @@
+package completion
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Arguments as built by 'dirvana completion' for 'kgp: kubectl get pods -n prod'
+// when the user typed 'kgp --watch <TAB>': alias words first, then the user's words
+var aliasArgs = []string{"get", "pods", "-n", "prod", "--watch", ""}
+
+// writeMockTool writes an executable script and returns its path
+func writeMockTool(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0755))
+	return path
+}
+
+func TestCobraCompleter_Complete_AliasArgs(t *testing.T) {
+	tool := writeMockTool(t, "mockcobra", `#!/bin/bash
+[ "$1" = "__complete" ] || exit 1
+shift
+printf 'args=%s\n' "$(IFS='|'; echo "$*")"
+echo ":4"
+`)
+
+	suggestions, err := NewCobraCompleter().Complete(tool, aliasArgs)
+	require.NoError(t, err)
+	require.Len(t, suggestions, 1)
+	assert.Equal(t, "args=get|pods|-n|prod|--watch|", suggestions[0].Value)
+}
+
+func TestFlagCompleter_Complete_AliasArgs(t *testing.T) {
+	tool := writeMockTool(t, "mockflag", `#!/bin/bash
+printf 'args=%s\n' "$(IFS='|'; echo "$*")"
+`)
+
+	suggestions, err := NewFlagCompleter().Complete(tool, aliasArgs)
+	require.NoError(t, err)
+	require.Len(t, suggestions, 1)
+	assert.Equal(t, "args=get|pods|-n|prod|--watch||--generate-shell-completion", suggestions[0].Value)
+}
+
+func TestFlagCompleter_Complete_DoesNotModifyArgs(t *testing.T) {
+	tool := writeMockTool(t, "mockflag", "#!/bin/bash\necho ok\n")
+
+	// Spare capacity would let append write into the caller's array
+	args := make([]string, 2, 10)
+	copy(args, []string{"get", ""})
+	before := append([]string(nil), args[:cap(args)]...)
+
+	_, err := NewFlagCompleter().Complete(tool, args)
+	require.NoError(t, err)
+
+	assert.Equal(t, before, args[:cap(args)])
+}
+
+func TestEnvCompleter_Complete_AliasArgs(t *testing.T) {
+	tool := writeMockTool(t, "mockenv", `#!/bin/bash
+echo "line=${COMP_LINE#* }"
+echo "point=$COMP_POINT"
+`)
+
+	suggestions, err := NewEnvCompleter().Complete(tool, aliasArgs)
+	require.NoError(t, err)
+	require.Len(t, suggestions, 2)
+	assert.Equal(t, "line=get pods -n prod --watch", suggestions[0].Value)
+	assert.Equal(t, "point=", suggestions[1].Value[:6])
+}
+
+func TestScriptCompleter_Complete_AliasArgs(t *testing.T) {
+	cacheDir := t.TempDir()
+	scriptPath := GetCompletionScriptPath(cacheDir, "mockscript", "bash")
+	require.NoError(t, os.MkdirAll(filepath.Dir(scriptPath), 0755))
+	require.NoError(t, os.WriteFile(scriptPath, []byte(`
+_mockscript() {
+  local IFS='|'
+  COMPREPLY=("words=${COMP_WORDS[*]:1}" "cword=$COMP_CWORD" "cur=${COMP_WORDS[COMP_CWORD]}")
+}
+complete -F _mockscript mockscript
+`), 0644))
+
+	suggestions, err := NewScriptCompleter(cacheDir).Complete("mockscript", aliasArgs)
+	require.NoError(t, err)
+	require.Len(t, suggestions, 3)
+	assert.Equal(t, "words=get|pods|-n|prod|--watch|", suggestions[0].Value)
+	assert.Equal(t, "cword=6", suggestions[1].Value)
+	assert.Equal(t, "cur=", suggestions[2].Value)
+}
@@ -0,0 +1,87 @@
+package completion
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+)
+
+// argcompleteMarker is the marker argcomplete requires in scripts supporting completion
+const argcompleteMarker = "PYTHON_ARGCOMPLETE_OK"
+
+// argcompleteOutputFD is the file descriptor argcomplete writes completions to
+const argcompleteOutputFD = 8
+
+// ArgcompleteCompleter handles Python tools using argcomplete (ansible, pipx, ...)
+// The tool is called with _ARGCOMPLETE=1, COMP_LINE and COMP_POINT and writes
+// the completions to file descriptor 8 (or the file named by _ARGCOMPLETE_STDOUT_FILENAME)
+type ArgcompleteCompleter struct{}
+
+// NewArgcompleteCompleter creates a new argcomplete completer
+func NewArgcompleteCompleter() *ArgcompleteCompleter {
+	return &ArgcompleteCompleter{}
+}
+
+// Supports checks if the tool is a Python script marked with PYTHON_ARGCOMPLETE_OK.
+// The script is only read, never executed: without argcomplete, a tool called with
+// completion variables would just run.
+func (a *ArgcompleteCompleter) Supports(tool string, _ []string) bool {
+	script := inspectPythonScript(tool)
+	return script != nil && script.sourceContains(argcompleteMarker)
+}
+
+// Complete calls the tool with the argcomplete protocol
+func (a *ArgcompleteCompleter) Complete(tool string, args []string) ([]Suggestion, error) {
+	outputFile, err := os.CreateTemp("", "dirvana-argcomplete-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create argcomplete output file: %w", err)
+	}
+	defer func() {
+		_ = outputFile.Close()
+		_ = os.Remove(outputFile.Name())
+	}()
+
+	// argcomplete splits COMP_LINE like a shell: quote the words as they would be typed
+	compLine := completionCommandLine(tool, args, shellQuote)
+
+	ctx, cancel := context.WithTimeout(context.Background(), PythonCommandTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, tool)
+	cmd.Env = append(os.Environ(),
+		"_ARGCOMPLETE=1",
+		"_ARGCOMPLETE_SUPPRESS_SPACE=1",
+		"_ARGCOMPLETE_IFS=\n",
+		"_ARGCOMPLETE_DFS=\t",
+		// Values are returned unescaped, as for fish which escapes them itself
+		"_ARGCOMPLETE_SHELL=fish",
+		"_ARGCOMPLETE_STDOUT_FILENAME="+outputFile.Name(),
+		"COMP_LINE="+compLine,
+		fmt.Sprintf("COMP_POINT=%d", len(compLine)),
+		"COMP_TYPE=9",
+	)
+	// Older argcomplete versions only write to file descriptor 8
+	cmd.ExtraFiles = make([]*os.File, argcompleteOutputFD-2)
+	cmd.ExtraFiles[argcompleteOutputFD-3] = outputFile
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, fmt.Errorf("command timeout after %v: %w", PythonCommandTimeout, err)
+		}
+		return nil, err
+	}
+
+	output, err := readFileHead(outputFile.Name(), MaxOutputSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseArgcompleteOutput([]byte(output)), nil
+}
+
+// parseArgcompleteOutput parses argcomplete output
+// Format: one suggestion per line (_ARGCOMPLETE_IFS), description after a tab (_ARGCOMPLETE_DFS)
+func parseArgcompleteOutput(output []byte) []Suggestion {
+	return parseCompletionOutput(output, true)
+}
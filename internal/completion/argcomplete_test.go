@@ -0,0 +1,59 @@
+package completion
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestArgcompleteCompleter_Supports(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	a := NewArgcompleteCompleter()
+
+	// Detection only reads the script: running it would fail
+	assert.True(t, a.Supports(writeMockTool(t, "marked", "#!/usr/bin/python3\n# PYTHON_ARGCOMPLETE_OK\nexit(1)\n"), nil))
+	assert.False(t, a.Supports(writeMockTool(t, "unmarked", "#!/usr/bin/python3\nimport argparse\n"), nil))
+	assert.False(t, a.Supports(writeMockTool(t, "shell", "#!/bin/bash\n# PYTHON_ARGCOMPLETE_OK\n"), nil))
+}
+
+func TestArgcompleteCompleter_Complete(t *testing.T) {
+	// Recent argcomplete versions write to the file named by _ARGCOMPLETE_STDOUT_FILENAME
+	tool := writeMockTool(t, "mockargcomplete", `#!/bin/bash
+[ "$_ARGCOMPLETE" = "1" ] || exit 1
+printf 'line=%s%s' "$COMP_LINE" "$_ARGCOMPLETE_IFS" > "$_ARGCOMPLETE_STDOUT_FILENAME"
+printf 'play%sRun a playbook%s' "$_ARGCOMPLETE_DFS" "$_ARGCOMPLETE_IFS" >> "$_ARGCOMPLETE_STDOUT_FILENAME"
+printf 'ping%s' "$_ARGCOMPLETE_DFS" >> "$_ARGCOMPLETE_STDOUT_FILENAME"
+`)
+
+	suggestions, err := NewArgcompleteCompleter().Complete(tool, []string{"all", "-m", "p"})
+	require.NoError(t, err)
+	assert.Equal(t, []Suggestion{
+		{Value: "line=" + tool + " all -m p"},
+		{Value: "play", Description: "Run a playbook"},
+		{Value: "ping"},
+	}, suggestions)
+
+	// Arguments are quoted as typed in a shell
+	suggestions, err = NewArgcompleteCompleter().Complete(tool, []string{"-e", "msg=it's here", "p"})
+	require.NoError(t, err)
+	assert.Equal(t, "line="+tool+` -e 'msg=it'\''s here' p`, suggestions[0].Value)
+}
+
+func TestArgcompleteCompleter_Complete_FileDescriptor(t *testing.T) {
+	// Older argcomplete versions only write to file descriptor 8
+	tool := writeMockTool(t, "mockargcomplete", `#!/bin/bash
+printf 'alpha\nbeta' >&8
+`)
+
+	suggestions, err := NewArgcompleteCompleter().Complete(tool, []string{""})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"alpha", "beta"}, suggestionValues(suggestions))
+}
+
+func TestArgcompleteCompleter_Complete_Failure(t *testing.T) {
+	tool := writeMockTool(t, "mockargcomplete", "#!/bin/bash\nexit 1\n")
+
+	_, err := NewArgcompleteCompleter().Complete(tool, []string{""})
+	assert.Error(t, err)
+}
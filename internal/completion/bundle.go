@@ -0,0 +1,381 @@
+package completion
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultBundleName is the file name of a bundle created without an explicit output
+const DefaultBundleName = "dirvana-completion-bundle.tar.gz"
+
+// Files of a bundle archive
+const (
+	bundleManifestFile = "manifest.json"
+	bundleRegistryFile = "completion-scripts.yml"
+	bundleScriptsDir   = "scripts/"
+)
+
+// bundleShells are the shells whose scripts are packed in a bundle: the bash script used by all
+// shells, and the native scripts of zsh and fish
+var bundleShells = []string{"bash", "zsh", "fish"}
+
+// BundleManifest describes the content of a completion bundle
+type BundleManifest struct {
+	Version   string                  `json:"version"` // Registry format version
+	CreatedAt time.Time               `json:"created_at"`
+	Scripts   map[string]BundleScript `json:"scripts"` // By lockfile key: <shell>/<tool> for native scripts
+}
+
+// BundleScript describes a completion script of a bundle
+type BundleScript struct {
+	SHA256   string `json:"sha256"`             // Hash of the script in the bundle
+	URL      string `json:"url"`                // Where the script was downloaded from
+	Registry string `json:"registry,omitempty"` // Registry the tool came from
+	Verified bool   `json:"verified"`           // The registry gave a checksum and it matched
+}
+
+// BundleResult reports what a bundle operation did
+type BundleResult struct {
+	Tools      []string         // Scripts packed or installed by lockfile key (<shell>/<tool> for native scripts), sorted
+	Skipped    map[string]error // Scripts left out by lockfile key, with the reason
+	Registries map[string]error // Registries that could not be loaded, by name
+}
+
+// CreateBundle packs the merged registries and all the scripts they reference, native zsh and fish
+// scripts included, into a gzipped tar archive, for hosts without network access. Scripts that
+// cannot be fetched are skipped; in strict mode, scripts without a checksum in their registry are
+// skipped too.
+func CreateBundle(cacheDir string, sources []RegistrySource, output string, strict bool) (*BundleResult, error) {
+	result := &BundleResult{Skipped: make(map[string]error), Registries: make(map[string]error)}
+
+	configs := make([]*RegistryConfig, 0, len(sources))
+	for _, source := range sources {
+		config, err := loadRegistrySource(cacheDir, source, false)
+		if err != nil {
+			result.Registries[source.Name] = err
+			continue
+		}
+		configs = append(configs, config)
+	}
+	if len(configs) == 0 {
+		return nil, fmt.Errorf("no completion registry could be loaded")
+	}
+	registry := mergeRegistries(configs)
+
+	manifest := BundleManifest{
+		Version:   DefaultRegistryVersion,
+		CreatedAt: time.Now().UTC(),
+		Scripts:   make(map[string]BundleScript),
+	}
+	bundled := RegistryConfig{
+		Version:     registry.Version,
+		Description: registry.Description,
+		Tools:       make(map[string]RegistryTool),
+	}
+	scripts := make(map[string][]byte)
+
+	for _, tool := range sortedTools(registry) {
+		toolInfo := registry.Tools[tool]
+		if err := validateBundleTool(tool); err != nil {
+			result.Skipped[tool] = err
+			continue
+		}
+		if toolInfo.Script.URL == "" && toolInfo.Zsh.URL == "" && toolInfo.Fish.URL == "" {
+			result.Skipped[tool] = fmt.Errorf("no completion script available")
+			continue
+		}
+
+		packed := false
+		for _, shell := range bundleShells {
+			scriptInfo := toolInfo.ScriptFor(shell)
+			if scriptInfo.URL == "" {
+				continue
+			}
+			key := scriptLockKey(tool, shell)
+			if strict && scriptInfo.SHA256 == "" {
+				result.Skipped[key] = fmt.Errorf("no checksum (required in strict mode)")
+				continue
+			}
+
+			data, hash, err := fetchRegistryScript(tool, toolInfo, scriptInfo)
+			if err != nil {
+				result.Skipped[key] = err
+				continue
+			}
+
+			scripts[key] = data
+			manifest.Scripts[key] = BundleScript{
+				SHA256:   hash,
+				URL:      scriptInfo.URL,
+				Registry: toolInfo.Registry,
+				Verified: scriptInfo.SHA256 != "",
+			}
+			result.Tools = append(result.Tools, key)
+			packed = true
+		}
+		if packed {
+			bundled.Tools[tool] = toolInfo
+		}
+	}
+	sort.Strings(result.Tools)
+
+	if err := writeBundle(output, manifest, bundled, scripts); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// writeBundle writes the manifest, the registry and the scripts into a gzipped tar archive
+func writeBundle(output string, manifest BundleManifest, registry RegistryConfig, scripts map[string][]byte) error {
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	registryData, err := yaml.Marshal(registry)
+	if err != nil {
+		return err
+	}
+
+	if dir := filepath.Dir(output); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create bundle dir: %w", err)
+		}
+	}
+	f, err := os.Create(output)
+	if err != nil {
+		return fmt.Errorf("failed to create bundle: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+
+	addFile := func(name string, data []byte) error {
+		header := &tar.Header{
+			Name:    name,
+			Mode:    0644,
+			Size:    int64(len(data)),
+			ModTime: manifest.CreatedAt,
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		_, err := tw.Write(data)
+		return err
+	}
+
+	if err := addFile(bundleManifestFile, manifestData); err != nil {
+		return fmt.Errorf("failed to write bundle: %w", err)
+	}
+	if err := addFile(bundleRegistryFile, registryData); err != nil {
+		return fmt.Errorf("failed to write bundle: %w", err)
+	}
+	keys := make([]string, 0, len(scripts))
+	for key := range scripts {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if err := addFile(bundleScriptsDir+key, scripts[key]); err != nil {
+			return fmt.Errorf("failed to write bundle: %w", err)
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("failed to write bundle: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("failed to write bundle: %w", err)
+	}
+	return f.Close()
+}
+
+// InstallBundle installs the scripts of a bundle in the cache directory and records them in the
+// script lockfile, so completion scripts work without network access. The registry of the bundle
+// becomes the cached copy of the default registry. Scripts that don't match the checksum of the
+// manifest are skipped; in strict mode, scripts without a checksum in their registry are skipped too.
+func InstallBundle(cacheDir, bundlePath string, strict bool) (*BundleResult, error) {
+	manifest, registryData, scripts, err := readBundle(bundlePath)
+	if err != nil {
+		return nil, err
+	}
+
+	lock, err := LoadScriptLock(cacheDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load script lockfile: %w", err)
+	}
+
+	result := &BundleResult{Skipped: make(map[string]error)}
+	keys := make([]string, 0, len(manifest.Scripts))
+	for key := range manifest.Scripts {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		script := manifest.Scripts[key]
+		data, ok := scripts[key]
+		switch {
+		case !ok:
+			result.Skipped[key] = fmt.Errorf("script missing from the bundle")
+			continue
+		case computeHash(data) != script.SHA256:
+			result.Skipped[key] = fmt.Errorf("checksum mismatch: expected %s, got %s", script.SHA256, computeHash(data))
+			continue
+		case strict && !script.Verified:
+			result.Skipped[key] = fmt.Errorf("no checksum in its registry (required in strict mode)")
+			continue
+		}
+
+		tool, shell := parseScriptLockKey(key)
+		if err := writeCompletionScript(cacheDir, tool, shell, data); err != nil {
+			return nil, err
+		}
+		lock.Record(key, ScriptLockEntry{
+			SHA256:      script.SHA256,
+			URL:         script.URL,
+			Registry:    script.Registry,
+			Verified:    script.Verified,
+			InstalledAt: time.Now(),
+		})
+		result.Tools = append(result.Tools, key)
+	}
+
+	if err := lock.Save(); err != nil {
+		return nil, fmt.Errorf("failed to save script lockfile: %w", err)
+	}
+
+	version := manifest.Version
+	if version == "" {
+		version = DefaultRegistryVersion
+	}
+	if _, err := saveCachedRegistry(cacheDir, version, registryData); err != nil {
+		return nil, err
+	}
+	clearRegistryCache()
+
+	return result, nil
+}
+
+// readBundle reads and validates a bundle archive
+func readBundle(bundlePath string) (*BundleManifest, []byte, map[string][]byte, error) {
+	f, err := os.Open(bundlePath)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to open bundle: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("invalid bundle %s: %w", bundlePath, err)
+	}
+	defer func() { _ = gz.Close() }()
+
+	var manifestData, registryData []byte
+	scripts := make(map[string][]byte)
+
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("invalid bundle %s: %w", bundlePath, err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			return nil, nil, nil, fmt.Errorf("invalid bundle %s: unexpected entry %s", bundlePath, header.Name)
+		}
+
+		name := path.Clean(header.Name)
+		switch {
+		case name == bundleManifestFile:
+			manifestData, err = readBundleEntry(tr, MaxRegistrySize)
+		case name == bundleRegistryFile:
+			registryData, err = readBundleEntry(tr, MaxRegistrySize)
+		case strings.HasPrefix(name, bundleScriptsDir):
+			key := strings.TrimPrefix(name, bundleScriptsDir)
+			if err := validateBundleKey(key); err != nil {
+				return nil, nil, nil, fmt.Errorf("invalid bundle %s: %w", bundlePath, err)
+			}
+			scripts[key], err = readBundleEntry(tr, MaxScriptSize)
+		default:
+			return nil, nil, nil, fmt.Errorf("invalid bundle %s: unexpected entry %s", bundlePath, header.Name)
+		}
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("invalid bundle %s: %s: %w", bundlePath, name, err)
+		}
+	}
+
+	if manifestData == nil || registryData == nil {
+		return nil, nil, nil, fmt.Errorf("invalid bundle %s: missing %s or %s", bundlePath, bundleManifestFile, bundleRegistryFile)
+	}
+
+	var manifest BundleManifest
+	if err := json.Unmarshal(manifestData, &manifest); err != nil {
+		return nil, nil, nil, fmt.Errorf("invalid bundle manifest: %w", err)
+	}
+	var registry RegistryConfig
+	if err := yaml.Unmarshal(registryData, &registry); err != nil {
+		return nil, nil, nil, fmt.Errorf("invalid bundle registry: %w", err)
+	}
+	for key := range manifest.Scripts {
+		if err := validateBundleKey(key); err != nil {
+			return nil, nil, nil, fmt.Errorf("invalid bundle manifest: %w", err)
+		}
+	}
+
+	return &manifest, registryData, scripts, nil
+}
+
+// readBundleEntry reads an entry of a bundle, failing if it is larger than maxSize
+func readBundleEntry(r io.Reader, maxSize int64) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > maxSize {
+		return nil, fmt.Errorf("content too large: exceeds %d bytes", maxSize)
+	}
+	return data, nil
+}
+
+// validateBundleTool checks that a tool name can be used as a script file name
+func validateBundleTool(tool string) error {
+	if tool == "" || tool == "." || tool == ".." || strings.ContainsAny(tool, `/\`) {
+		return fmt.Errorf("invalid tool name %q", tool)
+	}
+	return nil
+}
+
+// validateBundleKey checks the lockfile key of a bundled script: a tool name, or <shell>/<tool>
+// for the native scripts of zsh and fish
+func validateBundleKey(key string) error {
+	tool, shell := parseScriptLockKey(key)
+	if key != scriptLockKey(tool, shell) || (shell != "zsh" && shell != "fish" && shell != "bash") {
+		return fmt.Errorf("invalid tool name %q", key)
+	}
+	return validateBundleTool(tool)
+}
+
+// sortedTools returns the tools of a registry, sorted
+func sortedTools(registry *RegistryConfig) []string {
+	tools := make([]string, 0, len(registry.Tools))
+	for tool := range registry.Tools {
+		tools = append(tools, tool)
+	}
+	sort.Strings(tools)
+	return tools
+}
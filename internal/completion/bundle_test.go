@@ -0,0 +1,246 @@
+package completion
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeTestBundle writes a bundle archive with the given entries
+func writeTestBundle(t *testing.T, entries map[string]string) string {
+	t.Helper()
+	bundlePath := filepath.Join(t.TempDir(), "bundle.tar.gz")
+	f, err := os.Create(bundlePath)
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	for name, content := range entries {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content))}))
+		_, err := tw.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+	return bundlePath
+}
+
+// testBundleManifest returns a manifest for the given scripts
+func testBundleManifest(t *testing.T, scripts map[string]BundleScript) string {
+	t.Helper()
+	data, err := json.Marshal(BundleManifest{Version: "v1", Scripts: scripts})
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestCreateAndInstallBundle(t *testing.T) {
+	clearRegistryCache()
+	t.Cleanup(clearRegistryCache)
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/helm.bash":
+			_, _ = w.Write([]byte("complete -W 'install' helm\n"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	defer setupTestHTTPClient(server)()
+
+	team := t.TempDir()
+	writeFileRegistry(t, team, `version: v1
+tools:
+  deployctl:
+    script:
+      url: deployctl.bash
+    zsh:
+      url: deployctl.zsh
+  helm:
+    script:
+      url: `+server.URL+`/helm.bash
+      sha256: `+computeHash([]byte("complete -W 'install' helm\n"))+`
+  broken:
+    script:
+      url: `+server.URL+`/missing.bash
+`)
+	require.NoError(t, os.WriteFile(filepath.Join(team, "v1", "deployctl.bash"), []byte("complete -W 'up' deployctl\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(team, "v1", "deployctl.zsh"), []byte("#compdef deployctl\n_arguments '1:cmd:(up)'\n"), 0644))
+
+	// Create on a connected host
+	bundlePath := filepath.Join(t.TempDir(), "out", "bundle.tar.gz")
+	sources := []RegistrySource{{Name: "team", URL: team}, {Name: "missing", URL: filepath.Join(t.TempDir(), "missing")}}
+	result, err := CreateBundle(t.TempDir(), sources, bundlePath, false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"deployctl", "helm", "zsh/deployctl"}, result.Tools)
+	assert.Contains(t, result.Skipped, "broken")
+	assert.Contains(t, result.Registries, "missing")
+	assert.FileExists(t, bundlePath)
+
+	// Install on an offline host
+	server.Close()
+	clearRegistryCache()
+	cacheDir := t.TempDir()
+	result, err = InstallBundle(cacheDir, bundlePath, false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"deployctl", "helm", "zsh/deployctl"}, result.Tools)
+	assert.Empty(t, result.Skipped)
+
+	data, err := os.ReadFile(GetCompletionScriptPath(cacheDir, "deployctl", "bash"))
+	require.NoError(t, err)
+	assert.Equal(t, "complete -W 'up' deployctl\n", string(data))
+	data, err = os.ReadFile(GetCompletionScriptPath(cacheDir, "deployctl", "zsh"))
+	require.NoError(t, err)
+	assert.Equal(t, "#compdef deployctl\n_arguments '1:cmd:(up)'\n", string(data))
+
+	lock, err := LoadScriptLock(cacheDir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"deployctl", "helm", "zsh/deployctl"}, lock.Tools())
+	assert.True(t, lock.Scripts["helm"].Verified)
+	assert.False(t, lock.Scripts["deployctl"].Verified)
+	assert.Equal(t, "team", lock.Scripts["helm"].Registry)
+
+	// The bundle registry is the cached default registry, with the checksums of the registries:
+	// the hash of a script without one is only in the manifest
+	registry, err := LoadRegistry(cacheDir)
+	require.NoError(t, err)
+	assert.Len(t, registry.Tools, 2)
+	assert.Empty(t, registry.Tools["deployctl"].Script.SHA256)
+	assert.Equal(t, computeHash([]byte("complete -W 'install' helm\n")), registry.Tools["helm"].Script.SHA256)
+	assert.Equal(t, computeHash([]byte("complete -W 'up' deployctl\n")), lock.Scripts["deployctl"].SHA256)
+
+	// Scripts are usable without network access
+	script := NewScriptCompleter(cacheDir)
+	assert.True(t, script.Supports("deployctl", nil))
+	integrity, err := VerifyScript(cacheDir, "helm")
+	require.NoError(t, err)
+	assert.Equal(t, ScriptIntact, integrity.Status)
+	integrity, err = VerifyShellScript(cacheDir, "deployctl", "zsh")
+	require.NoError(t, err)
+	assert.Equal(t, ScriptIntact, integrity.Status)
+}
+
+func TestCreateBundle_Strict(t *testing.T) {
+	clearRegistryCache()
+	t.Cleanup(clearRegistryCache)
+
+	team := t.TempDir()
+	writeFileRegistry(t, team, `tools:
+  pinned:
+    script:
+      url: pinned.bash
+      sha256: `+computeHash([]byte("pinned\n"))+`
+    fish:
+      url: pinned.fish
+  unpinned:
+    script:
+      url: unpinned.bash
+`)
+	require.NoError(t, os.WriteFile(filepath.Join(team, "v1", "pinned.bash"), []byte("pinned\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(team, "v1", "unpinned.bash"), []byte("unpinned\n"), 0644))
+
+	bundlePath := filepath.Join(t.TempDir(), "bundle.tar.gz")
+	result, err := CreateBundle(t.TempDir(), []RegistrySource{{Name: "team", URL: team}}, bundlePath, true)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"pinned"}, result.Tools)
+	assert.ErrorContains(t, result.Skipped["unpinned"], "strict mode")
+	assert.ErrorContains(t, result.Skipped["fish/pinned"], "strict mode")
+
+	_, err = CreateBundle(t.TempDir(), []RegistrySource{{Name: "missing", URL: filepath.Join(team, "missing")}}, bundlePath, false)
+	assert.ErrorContains(t, err, "no completion registry could be loaded")
+}
+
+func TestInstallBundle_Verification(t *testing.T) {
+	clearRegistryCache()
+	t.Cleanup(clearRegistryCache)
+
+	bundlePath := writeTestBundle(t, map[string]string{
+		"manifest.json": testBundleManifest(t, map[string]BundleScript{
+			"good":     {SHA256: computeHash([]byte("good\n")), Verified: true},
+			"tampered": {SHA256: computeHash([]byte("original\n")), Verified: true},
+			"unpinned": {SHA256: computeHash([]byte("unpinned\n"))},
+			"missing":  {SHA256: "abc"},
+		}),
+		"completion-scripts.yml": "version: v1\ntools: {}\n",
+		"scripts/good":           "good\n",
+		"scripts/tampered":       "echo tampered\n",
+		"scripts/unpinned":       "unpinned\n",
+	})
+
+	cacheDir := t.TempDir()
+	result, err := InstallBundle(cacheDir, bundlePath, true)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"good"}, result.Tools)
+	assert.ErrorContains(t, result.Skipped["tampered"], "checksum mismatch")
+	assert.ErrorContains(t, result.Skipped["unpinned"], "strict mode")
+	assert.ErrorContains(t, result.Skipped["missing"], "missing from the bundle")
+	assert.NoFileExists(t, GetCompletionScriptPath(cacheDir, "tampered", "bash"))
+
+	result, err = InstallBundle(cacheDir, bundlePath, false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"good", "unpinned"}, result.Tools)
+}
+
+func TestInstallBundle_Invalid(t *testing.T) {
+	manifest := testBundleManifest(t, map[string]BundleScript{})
+	tests := []struct {
+		name    string
+		entries map[string]string
+		want    string
+	}{
+		{
+			name:    "path traversal",
+			entries: map[string]string{"manifest.json": manifest, "completion-scripts.yml": "tools: {}\n", "scripts/../../evil": "x"},
+			want:    "unexpected entry",
+		},
+		{
+			name:    "unexpected file",
+			entries: map[string]string{"manifest.json": manifest, "completion-scripts.yml": "tools: {}\n", "README": "x"},
+			want:    "unexpected entry",
+		},
+		{
+			name:    "missing manifest",
+			entries: map[string]string{"completion-scripts.yml": "tools: {}\n"},
+			want:    "missing manifest.json",
+		},
+		{
+			name: "invalid tool name in manifest",
+			entries: map[string]string{
+				"manifest.json":          testBundleManifest(t, map[string]BundleScript{"../evil": {SHA256: "abc"}}),
+				"completion-scripts.yml": "tools: {}\n",
+			},
+			want: "invalid tool name",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := InstallBundle(t.TempDir(), writeTestBundle(t, tt.entries), false)
+			assert.ErrorContains(t, err, tt.want)
+		})
+	}
+
+	notGzip := filepath.Join(t.TempDir(), "bundle.tar.gz")
+	require.NoError(t, os.WriteFile(notGzip, []byte("not a bundle"), 0644))
+	_, err := InstallBundle(t.TempDir(), notGzip, false)
+	assert.ErrorContains(t, err, "invalid bundle")
+
+	_, err = InstallBundle(t.TempDir(), filepath.Join(t.TempDir(), "nonexistent.tar.gz"), false)
+	assert.ErrorContains(t, err, "failed to open bundle")
+}
+
+func TestValidateBundleTool(t *testing.T) {
+	assert.NoError(t, validateBundleTool("kubectl"))
+	assert.NoError(t, validateBundleTool("docker-compose"))
+	for _, tool := range []string{"", ".", "..", "a/b", `a\b`} {
+		assert.Error(t, validateBundleTool(tool), tool)
+	}
+}
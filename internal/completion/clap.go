@@ -0,0 +1,56 @@
+package completion
+
+import (
+	"context"
+	"os"
+	"strings"
+)
+
+// ClapCompleter handles Rust tools using clap_complete's dynamic completion (CompleteEnv)
+// The tool is called with COMPLETE=<shell> and the command line after '--'
+type ClapCompleter struct{}
+
+// NewClapCompleter creates a new clap completer
+func NewClapCompleter() *ClapCompleter {
+	return &ClapCompleter{}
+}
+
+// Supports checks if the tool prints clap's registration script for COMPLETE=bash
+func (c *ClapCompleter) Supports(tool string, _ []string) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultCommandTimeout)
+	defer cancel()
+	env := append(os.Environ(), "COMPLETE=bash")
+	output, err := execWithTimeoutAndEnv(ctx, env, tool)
+	if err != nil || len(output) == 0 {
+		return false
+	}
+
+	// The registration script passes the completion index in this variable
+	return strings.Contains(string(output), "_CLAP_COMPLETE_INDEX")
+}
+
+// Complete calls the tool with the clap dynamic completion protocol.
+// The fish flavor is used because it returns descriptions in the format dirvana prints:
+// one 'value<TAB>help' line per candidate, completing the last word.
+func (c *ClapCompleter) Complete(tool string, args []string) ([]Suggestion, error) {
+	words := append([]string{"--", tool}, args...)
+	if len(args) == 0 {
+		words = append(words, "")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultCommandTimeout)
+	defer cancel()
+	env := append(os.Environ(), "COMPLETE=fish")
+	output, err := execWithTimeoutAndEnv(ctx, env, tool, words...)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseClapOutput(output), nil
+}
+
+// parseClapOutput parses clap fish completion output
+// Format: one suggestion per line, description after a tab
+func parseClapOutput(output []byte) []Suggestion {
+	return parseCompletionOutput(output, true)
+}
@@ -0,0 +1,53 @@
+package completion
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockClapScript emulates a clap_complete CompleteEnv tool
+const mockClapScript = `#!/bin/bash
+case "$COMPLETE" in
+bash)
+    if [ $# -eq 0 ]; then
+        echo '_clap_complete_mock() { local _CLAP_COMPLETE_INDEX=${COMP_CWORD}; }'
+        exit 0
+    fi
+    ;;
+fish)
+    shift
+    printf 'words=%s\n' "$(IFS='|'; echo "$*")"
+    printf 'build\tCompile the project\n'
+    printf 'bench\n'
+    exit 0
+    ;;
+esac
+echo "running"
+`
+
+func TestClapCompleter_Supports(t *testing.T) {
+	c := NewClapCompleter()
+
+	assert.True(t, c.Supports(writeMockTool(t, "mockclap", mockClapScript), nil))
+	assert.False(t, c.Supports(writeMockTool(t, "mockother", "#!/bin/bash\necho running\n"), nil))
+	assert.False(t, c.Supports("this-command-does-not-exist-12345", nil))
+}
+
+func TestClapCompleter_Complete(t *testing.T) {
+	tool := writeMockTool(t, "mockclap", mockClapScript)
+
+	suggestions, err := NewClapCompleter().Complete(tool, []string{"--release", "b"})
+	require.NoError(t, err)
+	assert.Equal(t, []Suggestion{
+		{Value: "words=" + tool + "|--release|b"},
+		{Value: "build", Description: "Compile the project"},
+		{Value: "bench"},
+	}, suggestions)
+
+	// Completing the first word
+	suggestions, err = NewClapCompleter().Complete(tool, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "words="+tool+"|", suggestions[0].Value)
+}
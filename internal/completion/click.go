@@ -0,0 +1,101 @@
+package completion
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ClickCompleter handles Python tools built with click 8+ (and frameworks on top of it)
+// The tool is called with _<PROG>_COMPLETE=bash_complete, COMP_WORDS (the command line)
+// and COMP_CWORD (the index of the word being completed), and prints 'type,value' lines
+type ClickCompleter struct{}
+
+// NewClickCompleter creates a new click completer
+func NewClickCompleter() *ClickCompleter {
+	return &ClickCompleter{}
+}
+
+// Supports checks, without running the tool, that it is a console_scripts wrapper of a
+// package using click, with click 8 or later installed: only click 8 entry points handle the
+// completion variables before running any command.
+func (c *ClickCompleter) Supports(tool string, _ []string) bool {
+	script := inspectPythonScript(tool)
+	if script == nil || !script.isConsoleScript() || !script.sourceContains("import click", "from click") {
+		return false
+	}
+
+	major, _, _ := strings.Cut(script.packageVersion("click"), ".")
+	version, err := strconv.Atoi(major)
+	return err == nil && version >= 8
+}
+
+// Complete calls the tool with the click completion protocol
+func (c *ClickCompleter) Complete(tool string, args []string) ([]Suggestion, error) {
+	output, err := c.run(tool, args)
+	if err != nil {
+		return nil, err
+	}
+
+	current := ""
+	if len(args) > 0 {
+		current = args[len(args)-1]
+	}
+
+	return parseClickOutput(output, current), nil
+}
+
+// run executes the tool in completion mode
+func (c *ClickCompleter) run(tool string, args []string) ([]byte, error) {
+	words := append([]string{filepath.Base(tool)}, args...)
+	if len(args) == 0 {
+		// Complete a new word
+		words = append(words, "")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), PythonCommandTimeout)
+	defer cancel()
+
+	env := append(os.Environ(),
+		clickCompleteVar(tool)+"=bash_complete",
+		"COMP_WORDS="+strings.Join(escapeShellWords(words), " "),
+		"COMP_CWORD="+strconv.Itoa(len(words)-1),
+	)
+	return execWithTimeoutAndEnv(ctx, env, tool)
+}
+
+// clickCompleteVar returns the variable enabling completion, derived by click from the program name
+func clickCompleteVar(tool string) string {
+	name := strings.NewReplacer("-", "_", ".", "_").Replace(filepath.Base(tool))
+	return "_" + strings.ToUpper(name) + "_COMPLETE"
+}
+
+// parseClickOutput parses click bash_complete output
+// Format: 'type,value' per line ('type,value<TAB>help' is accepted too). The 'file'
+// and 'dir' types ask the shell to complete paths, they are completed from the current word.
+func parseClickOutput(output []byte, current string) []Suggestion {
+	var suggestions []Suggestion
+
+	for _, line := range strings.Split(string(output), "\n") {
+		itemType, item, found := strings.Cut(line, ",")
+		if !found {
+			continue
+		}
+
+		switch itemType {
+		case "file":
+			suggestions = append(suggestions, CompletePaths(current, "", false)...)
+		case "dir":
+			suggestions = append(suggestions, CompletePaths(current, "", true)...)
+		case "plain":
+			value, description, _ := strings.Cut(item, "\t")
+			if value != "" {
+				suggestions = append(suggestions, Suggestion{Value: value, Description: description})
+			}
+		}
+	}
+
+	return suggestions
+}
@@ -0,0 +1,82 @@
+package completion
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClickCompleter_Supports(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	c := NewClickCompleter()
+
+	// Virtualenv with console_scripts wrappers, like pip generates
+	venv := func(clickVersion string) (sitePackages string, wrapper func(name, module string) string) {
+		dir := t.TempDir()
+		sitePackages = filepath.Join(dir, "lib", "python3.12", "site-packages")
+		require.NoError(t, os.MkdirAll(filepath.Join(sitePackages, "click-"+clickVersion+".dist-info"), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(sitePackages, "clicktool.py"), []byte("import click\n"), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(sitePackages, "plaintool.py"), []byte("import argparse\n"), 0644))
+		return sitePackages, func(name, module string) string {
+			return writeMockTool(t, name, "#!"+filepath.Join(dir, "bin", "python")+"\nimport sys\nfrom "+module+" import main\n"+
+				"if __name__ == \"__main__\":\n    sys.exit(main())\n")
+		}
+	}
+
+	_, wrapper := venv("8.1.7")
+	assert.True(t, c.Supports(wrapper("clicktool", "clicktool"), nil))
+	assert.False(t, c.Supports(wrapper("plaintool", "plaintool"), nil))
+
+	// click 7 entry points run the tool instead of completing
+	_, wrapper = venv("7.1.2")
+	assert.False(t, c.Supports(wrapper("clicktool", "clicktool"), nil))
+
+	// Scripts importing click are never executed to find out
+	marker := filepath.Join(t.TempDir(), "executed")
+	script := writeMockTool(t, "standalone", "#!/usr/bin/env python3\nimport click\nopen('"+marker+"', 'w')\n")
+	assert.False(t, c.Supports(script, nil))
+	assert.NoFileExists(t, marker)
+}
+
+func TestClickCompleter_Complete(t *testing.T) {
+	tool := writeMockTool(t, "mock-click", `#!/bin/bash
+[ "$_MOCK_CLICK_COMPLETE" = "bash_complete" ] || exit 1
+printf 'plain,words=%s\n' "$COMP_WORDS"
+printf 'plain,cword=%s\n' "$COMP_CWORD"
+printf 'plain,deploy\n'
+`)
+
+	suggestions, err := NewClickCompleter().Complete(tool, []string{"--env", "it's", "de"})
+	require.NoError(t, err)
+	assert.Equal(t, []Suggestion{
+		{Value: `words='mock-click' '--env' 'it'\''s' 'de'`},
+		{Value: "cword=3"},
+		{Value: "deploy"},
+	}, suggestions)
+
+	// Without arguments, a new word is completed
+	suggestions, err = NewClickCompleter().Complete(tool, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"words='mock-click' ''", "cword=1", "deploy"}, suggestionValues(suggestions))
+}
+
+func TestClickCompleteVar(t *testing.T) {
+	assert.Equal(t, "_MYTOOL_COMPLETE", clickCompleteVar("mytool"))
+	assert.Equal(t, "_MY_TOOL_COMPLETE", clickCompleteVar("/usr/local/bin/my-tool"))
+}
+
+func TestParseClickOutput(t *testing.T) {
+	setupPathsDir(t)
+
+	suggestions := parseClickOutput([]byte("plain,run\tRun it\nplain,stop\n"), "")
+	assert.Equal(t, []Suggestion{{Value: "run", Description: "Run it"}, {Value: "stop"}}, suggestions)
+
+	// Paths are completed from the current word
+	assert.Equal(t, []string{"values.json", "values.yaml"}, suggestionValues(parseClickOutput([]byte("file,\n"), "val")))
+	assert.Equal(t, []string{"charts/"}, suggestionValues(parseClickOutput([]byte("dir,\n"), "ch")))
+
+	assert.Empty(t, parseClickOutput([]byte("garbage\n"), ""))
+}
@@ -19,6 +19,9 @@ const (
 	ShellCompDirectiveKeepOrder     = 32 // Keep completion order
 )
 
+// shellDirectives are the directives applied by the shell scripts of dirvana
+const shellDirectives = ShellCompDirectiveError | ShellCompDirectiveNoSpace | ShellCompDirectiveNoFileComp | ShellCompDirectiveKeepOrder
+
 // CobraCompleter handles completion for Cobra-based CLIs (kubectl, helm, etc.)
 type CobraCompleter struct{}
 
@@ -57,6 +60,13 @@ func (c *CobraCompleter) Supports(tool string, _ []string) bool {
 
 // Complete executes the tool's __complete command and parses the output
 func (c *CobraCompleter) Complete(tool string, args []string) ([]Suggestion, error) {
+	suggestions, _, err := c.CompleteWithDirective(tool, args)
+	return suggestions, err
+}
+
+// CompleteWithDirective executes the tool's __complete command and parses the output,
+// returning the directive of the tool with the suggestions
+func (c *CobraCompleter) CompleteWithDirective(tool string, args []string) ([]Suggestion, int, error) {
 	// Build the __complete command
 	// tool __complete <args...>
 	completeArgs := append([]string{"__complete"}, args...)
@@ -64,7 +74,7 @@ func (c *CobraCompleter) Complete(tool string, args []string) ([]Suggestion, err
 	ctx := context.Background()
 	output, err := execWithTimeout(ctx, tool, completeArgs...)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
 	suggestions, directive := parseCobraOutput(output)
@@ -72,15 +82,17 @@ func (c *CobraCompleter) Complete(tool string, args []string) ([]Suggestion, err
 	// Handle directives that require file/directory completion
 	if directive&ShellCompDirectiveFilterFileExt != 0 {
 		// Suggestions are file extensions, list files matching those extensions
-		return c.completeFilesWithExtensions(suggestions, args)
+		suggestions, err := c.completeFilesWithExtensions(suggestions, args)
+		return suggestions, directive, err
 	}
 
 	if directive&ShellCompDirectiveFilterDirs != 0 {
 		// Only show directories
-		return c.completeDirectories(args)
+		suggestions, err := c.completeDirectories(args)
+		return suggestions, directive, err
 	}
 
-	return suggestions, nil
+	return suggestions, directive, nil
 }
 
 // parseCobraOutput parses Cobra completion output format:
@@ -3,8 +3,8 @@ package completion
 
 // Suggestion represents a single completion suggestion
 type Suggestion struct {
-	Value       string // The actual value to complete
-	Description string // Optional description/help text
+	Value       string `json:"value"`                 // The actual value to complete
+	Description string `json:"description,omitempty"` // Optional description/help text
 }
 
 // Completer defines the interface for completion strategies
@@ -17,8 +17,36 @@ type Completer interface {
 	Complete(tool string, args []string) ([]Suggestion, error)
 }
 
+// DirectiveCompleter is implemented by completers whose tools also tell how their
+// suggestions should be presented, with Cobra's shell completion directives
+type DirectiveCompleter interface {
+	// CompleteWithDirective returns completion suggestions and the directive of the tool
+	CompleteWithDirective(tool string, args []string) ([]Suggestion, int, error)
+}
+
 // Result represents the result of a completion attempt
 type Result struct {
 	Suggestions []Suggestion
 	Source      string // Which completer provided these suggestions
+	Directive   int    // Cobra shell completion directive of the tool, 0 if none
+}
+
+// KeepOrder reports whether the tool asked to keep the order of its suggestions
+func (r *Result) KeepOrder() bool {
+	return r.Directive&ShellCompDirectiveKeepOrder != 0
+}
+
+// ShellDirective returns the directive passed to the shell scripts with the suggestions.
+// File extension and directory filters are left out: dirvana already listed the paths.
+func (r *Result) ShellDirective() int {
+	return r.Directive & shellDirectives
+}
+
+// complete runs a completer, with the directive of the tool if the completer gives one
+func complete(completer Completer, tool string, args []string) ([]Suggestion, int, error) {
+	if c, ok := completer.(DirectiveCompleter); ok {
+		return c.CompleteWithDirective(tool, args)
+	}
+	suggestions, err := completer.Complete(tool, args)
+	return suggestions, 0, err
 }
@@ -28,3 +28,12 @@ func TestResult(t *testing.T) {
 	assert.Equal(t, 2, len(result.Suggestions))
 	assert.Equal(t, "TestCompleter", result.Source)
 }
+
+func TestResult_ShellDirective(t *testing.T) {
+	result := &Result{Directive: ShellCompDirectiveNoSpace | ShellCompDirectiveFilterDirs | ShellCompDirectiveKeepOrder}
+	assert.Equal(t, ShellCompDirectiveNoSpace|ShellCompDirectiveKeepOrder, result.ShellDirective())
+	assert.True(t, result.KeepOrder())
+
+	assert.Zero(t, (&Result{Directive: ShellCompDirectiveFilterFileExt}).ShellDirective())
+	assert.Equal(t, ShellCompDirectiveError, (&Result{Directive: ShellCompDirectiveError}).ShellDirective())
+}
@@ -0,0 +1,109 @@
+package completion
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// CustomCommandTimeout is the timeout of commands declared as completion sources.
+// They are written by the user for this purpose (e.g. listing environments from an API),
+// so they get more time than the probing of tools' completion protocols.
+const CustomCommandTimeout = 2 * time.Second
+
+// Source describes custom completion candidates declared in the config
+type Source struct {
+	Values  []Suggestion // Static candidates
+	Command string       // Shell command printing candidates (value<TAB>description per line)
+	Files   []string     // Glob patterns of files to complete
+	Dirs    bool         // Complete directories
+}
+
+// CustomCompleter completes aliases with the sources declared in their config
+// instead of asking the underlying tool. Sources are keyed by alias name.
+type CustomCompleter struct {
+	sources map[string]Source
+}
+
+// NewCustomCompleter creates a completer for the given alias sources
+func NewCustomCompleter(sources map[string]Source) *CustomCompleter {
+	return &CustomCompleter{sources: sources}
+}
+
+// Supports returns true if a source is declared for the alias
+func (c *CustomCompleter) Supports(tool string, _ []string) bool {
+	_, ok := c.sources[tool]
+	return ok
+}
+
+// Complete returns the candidates of every source declared for the alias.
+// The last argument is the word being completed, used as the prefix of paths.
+// Values are returned unfiltered and in the declared order.
+func (c *CustomCompleter) Complete(tool string, args []string) ([]Suggestion, error) {
+	source, ok := c.sources[tool]
+	if !ok {
+		return nil, fmt.Errorf("no completion source for %s", tool)
+	}
+
+	current := ""
+	if len(args) > 0 {
+		current = args[len(args)-1]
+	}
+
+	suggestions := append([]Suggestion{}, source.Values...)
+
+	if source.Command != "" {
+		commandSuggestions, err := runSourceCommand(source.Command, args)
+		if err != nil {
+			return nil, err
+		}
+		suggestions = append(suggestions, commandSuggestions...)
+	}
+
+	if len(source.Files) > 0 || source.Dirs {
+		suggestions = append(suggestions, completeSourcePaths(current, source.Files)...)
+	}
+
+	return suggestions, nil
+}
+
+// runSourceCommand runs a completion command through sh, passing the alias arguments as $@
+func runSourceCommand(command string, args []string) ([]Suggestion, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), CustomCommandTimeout)
+	defer cancel()
+
+	shArgs := append([]string{"-c", command, "dirvana-completion"}, args...)
+	output, err := execWithTimeout(ctx, "sh", shArgs...)
+	if err != nil {
+		return nil, fmt.Errorf("completion command failed: %w", err)
+	}
+
+	return parseCompletionOutput(output, true), nil
+}
+
+// completeSourcePaths completes directories and the files matching any of the patterns
+// (no files when there is no pattern, i.e. 'dirs: true' alone)
+func completeSourcePaths(prefix string, patterns []string) []Suggestion {
+	if len(patterns) == 0 {
+		return CompletePaths(prefix, "", true)
+	}
+
+	// Directories are returned for every pattern, keep them once
+	seen := make(map[string]bool)
+	var suggestions []Suggestion
+	for _, pattern := range patterns {
+		for _, suggestion := range CompletePaths(prefix, pattern, false) {
+			if !seen[suggestion.Value] {
+				seen[suggestion.Value] = true
+				suggestions = append(suggestions, suggestion)
+			}
+		}
+	}
+
+	sort.Slice(suggestions, func(i, j int) bool {
+		return suggestions[i].Value < suggestions[j].Value
+	})
+
+	return suggestions
+}
@@ -0,0 +1,87 @@
+package completion
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCustomCompleter_Supports(t *testing.T) {
+	c := NewCustomCompleter(map[string]Source{"deploy": {Dirs: true}})
+
+	assert.True(t, c.Supports("deploy", nil))
+	assert.False(t, c.Supports("kubectl", nil))
+}
+
+func TestCustomCompleter_Values(t *testing.T) {
+	c := NewCustomCompleter(map[string]Source{
+		"deploy": {Values: []Suggestion{{Value: "staging"}, {Value: "prod", Description: "Production"}}},
+	})
+
+	suggestions, err := c.Complete("deploy", []string{""})
+	require.NoError(t, err)
+	// Declared order is kept
+	assert.Equal(t, []Suggestion{{Value: "staging"}, {Value: "prod", Description: "Production"}}, suggestions)
+}
+
+func TestCustomCompleter_Command(t *testing.T) {
+	c := NewCustomCompleter(map[string]Source{
+		"deploy": {Command: `printf 'dev\tDevelopment\nprod\n'; echo "args=$*"`},
+	})
+
+	suggestions, err := c.Complete("deploy", []string{"--force", "d"})
+	require.NoError(t, err)
+	assert.Equal(t, []Suggestion{
+		{Value: "dev", Description: "Development"},
+		{Value: "prod"},
+		{Value: "args=--force d"},
+	}, suggestions)
+}
+
+func TestCustomCompleter_CommandFailure(t *testing.T) {
+	c := NewCustomCompleter(map[string]Source{"deploy": {Command: "exit 1"}})
+
+	_, err := c.Complete("deploy", []string{""})
+	assert.Error(t, err)
+}
+
+func TestCustomCompleter_Files(t *testing.T) {
+	setupPathsDir(t)
+
+	c := NewCustomCompleter(map[string]Source{
+		"apply": {Files: []string{"*.yaml", "*.json"}},
+		"cdx":   {Dirs: true},
+	})
+
+	suggestions, err := c.Complete("apply", []string{""})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"charts/", "config.yaml", "link/", "values.json", "values.yaml"}, suggestionValues(suggestions))
+
+	suggestions, err = c.Complete("apply", []string{"charts/"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"charts/api.yaml", "charts/api/"}, suggestionValues(suggestions))
+
+	suggestions, err = c.Complete("cdx", []string{""})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"charts/", "link/"}, suggestionValues(suggestions))
+}
+
+func TestCustomCompleter_CombinedSources(t *testing.T) {
+	setupPathsDir(t)
+
+	c := NewCustomCompleter(map[string]Source{
+		"run": {Values: []Suggestion{{Value: "all"}}, Files: []string{"*.json"}},
+	})
+
+	suggestions, err := c.Complete("run", nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"all", "charts/", "link/", "values.json"}, suggestionValues(suggestions))
+}
+
+func TestCustomCompleter_UnknownAlias(t *testing.T) {
+	c := NewCustomCompleter(nil)
+
+	_, err := c.Complete("deploy", nil)
+	assert.Error(t, err)
+}
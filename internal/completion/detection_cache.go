@@ -3,18 +3,59 @@ package completion
 import (
 	"encoding/json"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"sync"
 	"time"
 )
 
-// CacheEntry stores completer type with timestamp for TTL
+// CacheEntry stores completer type with timestamp for TTL, and the identity of the binary it was detected for
 type CacheEntry struct {
 	CompleterType string    `json:"completer_type"`
 	Timestamp     time.Time `json:"timestamp"`
+	Tool          string    `json:"tool,omitempty"`     // Tool name the binary was found for
+	Size          int64     `json:"size,omitempty"`     // Size of the binary when detected
+	ModTime       time.Time `json:"mod_time,omitempty"` // Modification time of the binary when detected
 }
 
-// DetectionCache persists which completer type works for each tool
+// binaryIdentity identifies the binary a tool name resolves to
+type binaryIdentity struct {
+	key     string // Resolved path of the binary and tool name (<path>#<tool>), or the tool name if it is not found
+	size    int64
+	modTime time.Time
+}
+
+// resolveBinary resolves a tool to its binary through PATH and symlinks.
+// The tool name is part of the key: multi-call binaries (busybox) behave differently for
+// each name they are linked as. Tools that are not found are identified by their name only.
+func resolveBinary(tool string) binaryIdentity {
+	path, err := exec.LookPath(tool)
+	if err != nil {
+		return binaryIdentity{key: tool}
+	}
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return binaryIdentity{key: tool}
+	}
+	return binaryIdentity{key: path + "#" + filepath.Base(tool), size: info.Size(), modTime: info.ModTime()}
+}
+
+// matches checks if an entry was detected for this binary, unchanged since
+func (b binaryIdentity) matches(entry CacheEntry) bool {
+	return entry.Size == b.size && entry.ModTime.Equal(b.modTime)
+}
+
+// DetectionCache persists which completer type works for each binary
+// Entries are keyed by the resolved path of the binary and the tool name: the same tool name
+// found elsewhere in PATH is detected again, and entries are invalidated when the binary
+// changes (upgrade).
 // Only successful completions are cached, with a TTL to allow re-detection
 type DetectionCache struct {
 	mu       sync.RWMutex
@@ -41,13 +82,16 @@ func NewDetectionCache(cachePath string) (*DetectionCache, error) {
 	return c, nil
 }
 
-// Get returns the completer type for a tool, or empty string if not cached or expired
+// Get returns the completer type for a tool, or empty string if not cached, expired
+// or detected for a binary that changed since
 func (c *DetectionCache) Get(tool string) string {
+	binary := resolveBinary(tool)
+
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
-	entry, ok := c.cache[tool]
-	if !ok {
+	entry, ok := c.cache[binary.key]
+	if !ok || !binary.matches(entry) {
 		return ""
 	}
 
@@ -59,19 +103,37 @@ func (c *DetectionCache) Get(tool string) string {
 	return entry.CompleterType
 }
 
-// Set stores the completer type for a tool with current timestamp
+// Set stores the completer type for a tool with current timestamp and the identity of its binary
 func (c *DetectionCache) Set(tool string, completerType string) {
+	binary := resolveBinary(tool)
+
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
 	entry := CacheEntry{
 		CompleterType: completerType,
 		Timestamp:     time.Now(),
+		Tool:          filepath.Base(tool),
+		Size:          binary.size,
+		ModTime:       binary.modTime,
+	}
+
+	// Only mark as modified if the completer type or the binary actually changed
+	if existing, ok := c.cache[binary.key]; !ok || existing.CompleterType != completerType || !binary.matches(existing) {
+		c.cache[binary.key] = entry
+		c.modified = true
 	}
+}
+
+// Delete removes the entry of a tool, so that it is detected again
+func (c *DetectionCache) Delete(tool string) {
+	binary := resolveBinary(tool)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
 
-	// Only mark as modified if the completer type actually changed
-	if existing, ok := c.cache[tool]; !ok || existing.CompleterType != completerType {
-		c.cache[tool] = entry
+	if _, ok := c.cache[binary.key]; ok {
+		delete(c.cache, binary.key)
 		c.modified = true
 	}
 }
@@ -149,3 +149,94 @@ func TestDetectionCache_SaveToReadOnlyDir(t *testing.T) {
 	err = cache.Save()
 	assert.Error(t, err, "Should fail to save to read-only directory")
 }
+
+func TestDetectionCache_KeyedByBinary(t *testing.T) {
+	cache, err := NewDetectionCache(filepath.Join(t.TempDir(), "detection.json"))
+	require.NoError(t, err)
+
+	// Two binaries with the same name on different PATHs
+	first := writeMockTool(t, "mytool", "#!/bin/sh\n")
+	second := writeMockTool(t, "mytool", "#!/bin/sh\necho other\n")
+
+	t.Setenv("PATH", filepath.Dir(first))
+	cache.Set("mytool", "Cobra")
+	assert.Equal(t, "Cobra", cache.Get("mytool"))
+	assert.Equal(t, "Cobra", cache.Get(first))
+
+	t.Setenv("PATH", filepath.Dir(second))
+	assert.Equal(t, "", cache.Get("mytool"))
+	cache.Set("mytool", "Flag")
+	assert.Equal(t, "Flag", cache.Get("mytool"))
+
+	t.Setenv("PATH", filepath.Dir(first))
+	assert.Equal(t, "Cobra", cache.Get("mytool"))
+}
+
+func TestDetectionCache_BinaryChanged(t *testing.T) {
+	cache, err := NewDetectionCache(filepath.Join(t.TempDir(), "detection.json"))
+	require.NoError(t, err)
+
+	tool := writeMockTool(t, "mytool", "#!/bin/sh\n")
+	cache.Set(tool, "Cobra")
+	assert.Equal(t, "Cobra", cache.Get(tool))
+
+	// Upgraded binary
+	require.NoError(t, os.WriteFile(tool, []byte("#!/bin/sh\necho v2\n"), 0755))
+	assert.Equal(t, "", cache.Get(tool))
+
+	// Same size, touched
+	cache.Set(tool, "Cobra")
+	later := time.Now().Add(time.Hour)
+	require.NoError(t, os.Chtimes(tool, later, later))
+	assert.Equal(t, "", cache.Get(tool))
+}
+
+func TestDetectionCache_Symlink(t *testing.T) {
+	cache, err := NewDetectionCache(filepath.Join(t.TempDir(), "detection.json"))
+	require.NoError(t, err)
+
+	tool := writeMockTool(t, "mytool", "#!/bin/sh\n")
+	link := filepath.Join(t.TempDir(), "mytool")
+	require.NoError(t, os.Symlink(tool, link))
+
+	cache.Set(link, "Cobra")
+	assert.Equal(t, "Cobra", cache.Get(tool))
+}
+
+func TestDetectionCache_MultiCallBinary(t *testing.T) {
+	cache, err := NewDetectionCache(filepath.Join(t.TempDir(), "detection.json"))
+	require.NoError(t, err)
+
+	// One binary linked under several names, like busybox
+	binary := writeMockTool(t, "busybox", "#!/bin/sh\n")
+	dir := t.TempDir()
+	for _, name := range []string{"ls", "cat"} {
+		require.NoError(t, os.Symlink(binary, filepath.Join(dir, name)))
+	}
+	t.Setenv("PATH", dir)
+
+	cache.Set("ls", "Flag")
+	assert.Equal(t, "Flag", cache.Get("ls"))
+	assert.Equal(t, "", cache.Get("cat"))
+
+	cache.Set("cat", "Help")
+	assert.Equal(t, "Flag", cache.Get("ls"))
+	assert.Equal(t, "Help", cache.Get("cat"))
+}
+
+func TestDetectionCache_Delete(t *testing.T) {
+	cachePath := filepath.Join(t.TempDir(), "detection.json")
+	cache, err := NewDetectionCache(cachePath)
+	require.NoError(t, err)
+
+	cache.Set("kubectl", "Cobra")
+	require.NoError(t, cache.Save())
+
+	cache.Delete("kubectl")
+	assert.Equal(t, "", cache.Get("kubectl"))
+	require.NoError(t, cache.Save())
+
+	cache2, err := NewDetectionCache(cachePath)
+	require.NoError(t, err)
+	assert.Equal(t, "", cache2.Get("kubectl"))
+}
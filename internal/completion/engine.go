@@ -13,8 +13,11 @@ import (
 // Engine orchestrates multiple completion strategies
 type Engine struct {
 	completers      []Completer
+	contextual      []Completer // Depend on the arguments: tried first and never cached
+	fallback        Completer   // Tried when no completer supports the tool
 	detectionCache  *DetectionCache
 	completerByName map[string]Completer
+	plugins         []PluginSource // External completers declared in the global config
 }
 
 // NewEngine creates a new completion engine with all strategies
@@ -22,7 +25,14 @@ func NewEngine(cacheDir string) *Engine {
 	flag := NewFlagCompleter()
 	cobra := NewCobraCompleter()
 	env := NewEnvCompleter()
+	clap := NewClapCompleter()
+	argcomplete := NewArgcompleteCompleter()
+	click := NewClickCompleter()
+	yargs := NewYargsCompleter()
+	tabtab := NewTabtabCompleter()
+	oclif := NewOclifCompleter()
 	script := NewScriptCompleter(cacheDir)
+	help := NewHelpCompleter(cacheDir)
 
 	// Load detection cache
 	cachePath := filepath.Join(cacheDir, "completion-detection.json")
@@ -30,17 +40,34 @@ func NewEngine(cacheDir string) *Engine {
 
 	return &Engine{
 		completers: []Completer{
-			cobra,  // Try Cobra first (kubectl, helm, etc.) - most specific
-			flag,   // Then flag-based (dirvana, and other Go CLI tools)
-			env,    // Then env-based (terraform, consul, vault, nomad, etc.)
-			script, // Finally script-based (git, docker, systemctl, etc.)
+			cobra,       // Try Cobra first (kubectl, helm, etc.) - most specific
+			flag,        // Then flag-based (dirvana, and other urfave/cli tools)
+			clap,        // Rust tools using clap_complete's dynamic completion
+			env,         // Then env-based (terraform, consul, vault, nomad, etc.)
+			argcomplete, // Python tools using argcomplete (ansible, pipx, etc.)
+			click,       // Python tools built with click
+			yargs,       // Node.js tools built with yargs (nx, etc.)
+			tabtab,      // Node.js tools using tabtab or npm's protocol (npm, pnpm, etc.)
+			oclif,       // oclif CLIs, from their autocomplete cache
+			script,      // Finally script-based (git, docker, systemctl, etc.)
 		},
+		contextual: []Completer{
+			NewPackageScriptsCompleter(), // Script names for npm run, pnpm run, yarn, etc.
+		},
+		fallback:       help, // Last resort: subcommands and flags parsed from --help
 		detectionCache: detectionCache,
 		completerByName: map[string]Completer{
-			"Flag":   flag,
-			"Cobra":  cobra,
-			"Env":    env,
-			"Script": script,
+			"Flag":        flag,
+			"Cobra":       cobra,
+			"Env":         env,
+			"Clap":        clap,
+			"Script":      script,
+			"Argcomplete": argcomplete,
+			"Click":       click,
+			"Yargs":       yargs,
+			"Tabtab":      tabtab,
+			"Oclif":       oclif,
+			"Help":        help,
 		},
 	}
 }
@@ -49,6 +76,7 @@ func NewEngine(cacheDir string) *Engine {
 type completerResult struct {
 	completer   Completer
 	suggestions []Suggestion
+	directive   int
 	err         error
 }
 
@@ -57,33 +85,84 @@ func (e *Engine) Complete(tool string, args []string) (*Result, error) {
 	ctx := context.Background()
 	defer trace.Region(ctx, "Engine.Complete")()
 
+	// Completers depending on the arguments take precedence over the tool's own completion
+	for _, completer := range e.contextual {
+		if !completer.Supports(tool, args) {
+			continue
+		}
+		if suggestions, directive, err := complete(completer, tool, args); err == nil {
+			return &Result{
+				Suggestions: suggestions,
+				Source:      getCompleterType(completer),
+				Directive:   directive,
+			}, nil
+		}
+	}
+
 	// Check if we already know which completer works for this tool
 	if cachedType := e.detectionCache.Get(tool); cachedType != "" {
-		if completer, ok := e.completerByName[cachedType]; ok {
+		if completer, ok := e.completer(cachedType); ok {
 			var suggestions []Suggestion
+			var directive int
 			var err error
 			trace.WithRegion(ctx, "completer.Complete(cached:"+cachedType+")", func() {
-				suggestions, err = completer.Complete(tool, args)
+				suggestions, directive, err = complete(completer, tool, args)
 			})
 			if err == nil {
 				// Return immediately, even with empty suggestions
 				return &Result{
 					Suggestions: suggestions,
 					Source:      cachedType + " (cached)",
+					Directive:   directive,
 				}, nil
 			}
 		}
 	}
 
-	// Launch all completers in parallel
-	ctxTimeout, cancel := context.WithTimeout(ctx, DefaultCommandTimeout)
-	defer cancel()
+	return e.detect(tool, args), nil
+}
+
+// Redetect forgets the completer detected for a tool and runs the detection again,
+// for all completers in parallel. Returns the completer found, "none" if there is none.
+func (e *Engine) Redetect(tool string) (string, error) {
+	e.detectionCache.Delete(tool)
+	if err := e.detectionCache.Save(); err != nil {
+		return "", fmt.Errorf("failed to save detection cache: %w", err)
+	}
+
+	return e.detect(tool, []string{""}).Source, nil
+}
+
+// completer returns the completer recorded under a name in the detection cache.
+// External completers are looked up when needed, not to search PATH on every completion.
+func (e *Engine) completer(name string) (Completer, bool) {
+	if completer, ok := e.completerByName[name]; ok {
+		return completer, true
+	}
+	if pluginName, ok := strings.CutPrefix(name, pluginSourcePrefix); ok {
+		if plugin, found := findPlugin(e.plugins, pluginName); found {
+			return plugin, true
+		}
+	}
+	return nil, false
+}
+
+// detect tries all completers in parallel, caches and returns the first successful result.
+// Each completer is bounded by its own timeout (DefaultCommandTimeout, PythonCommandTimeout,
+// the timeout of an external completer), so a slower one is still detected when it answers.
+func (e *Engine) detect(tool string, args []string) *Result {
+	// Built-in completers, then external completers
+	completers := append([]Completer{}, e.completers...)
+	for _, plugin := range DiscoverPlugins(e.plugins) {
+		completers = append(completers, plugin)
+	}
 
-	resultChan := make(chan completerResult, len(e.completers))
+	// Launch all completers in parallel: the channel has room for all of their results
+	resultChan := make(chan completerResult, len(completers))
 	var wg sync.WaitGroup
 
 	// Start a goroutine for each completer
-	for _, completer := range e.completers {
+	for _, completer := range completers {
 		wg.Add(1)
 		go func(c Completer) {
 			defer wg.Done()
@@ -94,17 +173,13 @@ func (e *Engine) Complete(tool string, args []string) (*Result, error) {
 			}
 
 			// Try to complete
-			suggestions, err := c.Complete(tool, args)
+			suggestions, directive, err := complete(c, tool, args)
 			if err != nil {
 				return
 			}
 
 			// Send result to channel
-			select {
-			case resultChan <- completerResult{completer: c, suggestions: suggestions, err: nil}:
-			case <-ctxTimeout.Done():
-				return
-			}
+			resultChan <- completerResult{completer: c, suggestions: suggestions, directive: directive, err: nil}
 		}(completer)
 	}
 
@@ -122,8 +197,6 @@ func (e *Engine) Complete(tool string, args []string) (*Result, error) {
 		e.detectionCache.Set(tool, source)
 		_ = e.detectionCache.Save()
 
-		cancel() // Stop other goroutines
-
 		// Wait for all goroutines to finish cleanup
 		// This ensures subprocesses are terminated and files are closed
 		// before we return, preventing test cleanup issues
@@ -132,14 +205,65 @@ func (e *Engine) Complete(tool string, args []string) (*Result, error) {
 		return &Result{
 			Suggestions: result.suggestions,
 			Source:      source,
-		}, nil
+			Directive:   result.directive,
+		}
+	}
+
+	// No completer supported this tool: parse its help text
+	if e.fallback != nil && e.fallback.Supports(tool, args) {
+		if suggestions, err := e.fallback.Complete(tool, args); err == nil {
+			source := getCompleterType(e.fallback)
+			e.detectionCache.Set(tool, source)
+			_ = e.detectionCache.Save()
+
+			return &Result{
+				Suggestions: suggestions,
+				Source:      source,
+			}
+		}
 	}
 
 	// No completer supported this tool
 	return &Result{
 		Suggestions: []Suggestion{},
 		Source:      "none",
-	}, nil
+	}
+}
+
+// SetRegistries sets the registries completion scripts are downloaded from, by precedence
+func (e *Engine) SetRegistries(sources []RegistrySource) {
+	if script, ok := e.completerByName["Script"].(*ScriptCompleter); ok {
+		script.registries = sources
+	}
+}
+
+// SetStrictScripts makes the script completer refuse downloaded scripts without a checksum
+// in their registry, or modified since they were installed
+func (e *Engine) SetStrictScripts(strict bool) {
+	if script, ok := e.completerByName["Script"].(*ScriptCompleter); ok {
+		script.strict = strict
+	}
+}
+
+// SetScriptSandbox sets the policy for running bash completion scripts
+func (e *Engine) SetScriptSandbox(sandbox ScriptSandbox) {
+	if script, ok := e.completerByName["Script"].(*ScriptCompleter); ok {
+		script.sandbox = sandbox
+	}
+}
+
+// SetPlugins sets the external completers declared in the global config. They are tried with
+// the built-in completers, along with the dirvana-completer-<name> executables found on PATH.
+func (e *Engine) SetPlugins(plugins []PluginSource) {
+	e.plugins = plugins
+}
+
+// SetShell sets the shell of the user: the script completer then prefers the native zsh or fish
+// completion script of a tool to its bash script
+func (e *Engine) SetShell(shell string) {
+	if script, ok := e.completerByName["Script"].(*ScriptCompleter); ok {
+		script.shell = shell
+	}
 }
 
 // HasCachedDetection returns true if we have a valid cached detection for this tool
@@ -148,23 +272,21 @@ func (e *Engine) HasCachedDetection(tool string) bool {
 	return e.detectionCache.Get(tool) != ""
 }
 
-// Filter applies prefix filtering to suggestions
-func (e *Engine) Filter(suggestions []Suggestion, prefix string) []Suggestion {
-	if prefix == "" {
-		return suggestions
-	}
-
-	var filtered []Suggestion
-	for _, s := range suggestions {
-		if strings.HasPrefix(s.Value, prefix) {
-			filtered = append(filtered, s)
-		}
-	}
+// Match filters suggestions with a matching mode and ranks them, keeping the order of the
+// tool when the result asks for it (see Match)
+func (e *Engine) Match(result *Result, word string, mode MatchMode) []Suggestion {
+	return Match(result.Suggestions, word, mode, result.KeepOrder())
+}
 
-	return filtered
+// namedCompleter is implemented by completers with several instances, named in the detection cache
+type namedCompleter interface {
+	Name() string
 }
 
 func getCompleterType(completer Completer) string {
+	if named, ok := completer.(namedCompleter); ok {
+		return named.Name()
+	}
 	source := fmt.Sprintf("%T", completer)
 	source = strings.TrimPrefix(source, "*completion.")
 	source = strings.TrimSuffix(source, "Completer")
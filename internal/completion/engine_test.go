@@ -7,30 +7,30 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
-func TestEngine_Filter(t *testing.T) {
+func TestEngine_MatchPrefix(t *testing.T) {
 	engine := &Engine{}
 
-	suggestions := []Suggestion{
+	result := &Result{Suggestions: []Suggestion{
 		{Value: "apply", Description: "Apply a configuration"},
 		{Value: "annotate", Description: "Update annotations"},
 		{Value: "get", Description: "Get resources"},
-	}
+	}}
 
 	// Test with empty prefix (should return all)
-	filtered := engine.Filter(suggestions, "")
+	filtered := engine.Match(result, "", MatchPrefix)
 	assert.Equal(t, 3, len(filtered))
 
 	// Test with prefix "ap"
-	filtered = engine.Filter(suggestions, "ap")
+	filtered = engine.Match(result, "ap", MatchPrefix)
 	assert.Equal(t, 1, len(filtered))
 	assert.Equal(t, "apply", filtered[0].Value)
 
 	// Test with prefix "a"
-	filtered = engine.Filter(suggestions, "a")
+	filtered = engine.Match(result, "a", MatchPrefix)
 	assert.Equal(t, 2, len(filtered))
 
 	// Test with no matches
-	filtered = engine.Filter(suggestions, "xyz")
+	filtered = engine.Match(result, "xyz", MatchPrefix)
 	assert.Equal(t, 0, len(filtered))
 }
 
@@ -39,9 +39,11 @@ func TestNewEngine(t *testing.T) {
 	engine := NewEngine(tmpDir)
 
 	require.NotNil(t, engine)
-	assert.Equal(t, 4, len(engine.completers)) // Cobra, Flag, Env, Script
+	assert.Equal(t, 10, len(engine.completers)) // Cobra, Flag, Clap, Env, Argcomplete, Click, Yargs, Tabtab, Oclif, Script
+	assert.Equal(t, 1, len(engine.contextual))  // PackageScripts
+	assert.NotNil(t, engine.fallback)           // Help
 	assert.NotNil(t, engine.detectionCache)
-	assert.Equal(t, 4, len(engine.completerByName))
+	assert.Equal(t, 11, len(engine.completerByName))
 }
 
 func TestEngine_Complete_NoCommand(t *testing.T) {
@@ -131,6 +133,45 @@ func TestEngine_Complete_WithCache(t *testing.T) {
 	assert.Contains(t, result.Source, "cached")
 }
 
+func TestEngine_Complete_ContextualFirst(t *testing.T) {
+	tmpDir := t.TempDir()
+	engine := NewEngine(tmpDir)
+
+	contextual := &mockCompleter{
+		supportsResult: true,
+		suggestions:    []Suggestion{{Value: "build"}},
+	}
+	engine.contextual = []Completer{contextual}
+	engine.completerByName["Mock"] = &mockCompleter{supportsResult: true, suggestions: []Suggestion{{Value: "install"}}}
+	engine.detectionCache.Set("npm", "Mock")
+
+	result, err := engine.Complete("npm", []string{"run", ""})
+	require.NoError(t, err)
+	assert.Equal(t, []Suggestion{{Value: "build"}}, result.Suggestions)
+	assert.Equal(t, "mock", result.Source)
+
+	// Not supported: the cached completer is used
+	contextual.supportsResult = false
+	result, err = engine.Complete("npm", []string{"in"})
+	require.NoError(t, err)
+	assert.Equal(t, []Suggestion{{Value: "install"}}, result.Suggestions)
+}
+
+func TestEngine_Complete_Fallback(t *testing.T) {
+	tmpDir := t.TempDir()
+	engine := NewEngine(tmpDir)
+
+	engine.completers = []Completer{&mockCompleter{supportsResult: false}}
+	engine.fallback = &mockCompleter{supportsResult: true, suggestions: []Suggestion{{Value: "--help"}}}
+	engine.completerByName["mock"] = engine.fallback
+
+	result, err := engine.Complete("mockTool", []string{"-"})
+	require.NoError(t, err)
+	assert.Equal(t, []Suggestion{{Value: "--help"}}, result.Suggestions)
+	assert.Equal(t, "mock", result.Source)
+	assert.Equal(t, "mock", engine.detectionCache.Get("mockTool"))
+}
+
 func TestEngine_Complete_CacheInvalidAfterFailure(t *testing.T) {
 	tmpDir := t.TempDir()
 	engine := NewEngine(tmpDir)
@@ -225,3 +266,61 @@ func TestEngine_HasCachedDetection(t *testing.T) {
 	// Now should have cached detection
 	assert.True(t, engine.HasCachedDetection("testTool"))
 }
+
+func TestEngine_Redetect(t *testing.T) {
+	tmpDir := t.TempDir()
+	engine := NewEngine(tmpDir)
+
+	mock := &mockCompleter{supportsResult: true}
+	engine.completers = []Completer{mock}
+	engine.contextual = nil
+	engine.completerByName["mock"] = mock
+	engine.detectionCache.Set("mockTool", "Cobra")
+
+	source, err := engine.Redetect("mockTool")
+	require.NoError(t, err)
+	assert.Equal(t, "mock", source)
+	assert.Equal(t, "mock", engine.detectionCache.Get("mockTool"))
+
+	// Nothing supports the tool anymore
+	mock.supportsResult = false
+	engine.fallback = nil
+	source, err = engine.Redetect("mockTool")
+	require.NoError(t, err)
+	assert.Equal(t, "none", source)
+	assert.False(t, engine.HasCachedDetection("mockTool"))
+}
+
+func TestEngine_Complete_Directive(t *testing.T) {
+	tool := writeMockTool(t, "mockcobra", `#!/bin/bash
+[ "$1" = "__complete" ] || exit 1
+printf 'zeta\nalpha\nbeta\n:36\n'
+`)
+
+	engine := NewEngine(t.TempDir())
+	engine.completers = []Completer{NewCobraCompleter()}
+
+	result, err := engine.Complete(tool, []string{""})
+	require.NoError(t, err)
+	assert.Equal(t, ShellCompDirectiveKeepOrder|ShellCompDirectiveNoFileComp, result.Directive)
+	assert.True(t, result.KeepOrder())
+
+	// The order of the tool is kept, even when ranking would change it
+	assert.Equal(t, []string{"zeta", "alpha", "beta"}, values(engine.Match(result, "", MatchPrefix)))
+	assert.Equal(t, []string{"zeta", "alpha", "beta"}, values(engine.Match(result, "a", MatchSubstring)))
+}
+
+func TestEngine_Match(t *testing.T) {
+	engine := &Engine{}
+	result := &Result{Suggestions: []Suggestion{{Value: "port-forward"}, {Value: "apply"}, {Value: "get"}}}
+
+	assert.Equal(t, []string{"apply", "get", "port-forward"}, values(engine.Match(result, "", MatchPrefix)))
+	assert.Equal(t, []string{"port-forward"}, values(engine.Match(result, "pf", MatchFuzzy)))
+	assert.False(t, result.KeepOrder())
+
+	// Completers without directives give none
+	suggestions, directive, err := complete(&mockCompleter{suggestions: result.Suggestions}, "tool", nil)
+	require.NoError(t, err)
+	assert.Len(t, suggestions, 3)
+	assert.Zero(t, directive)
+}
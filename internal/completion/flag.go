@@ -1,9 +1,20 @@
 package completion
 
-import "context"
+import (
+	"context"
+	"os"
+	"strings"
+)
+
+// Completion flags of urfave/cli: v3 (used by dirvana itself) and v2
+const (
+	shellCompletionFlag = "--generate-shell-completion"
+	bashCompletionFlag  = "--generate-bash-completion"
+)
 
 // FlagCompleter handles tools that use --generate-shell-completion flag
-// This is used by tools built with github.com/urfave/cli and similar frameworks
+// This is used by tools built with github.com/urfave/cli (v3, and v2 with
+// --generate-bash-completion) and similar frameworks
 type FlagCompleter struct{}
 
 // NewFlagCompleter creates a new flag-based completer
@@ -11,40 +22,75 @@ func NewFlagCompleter() *FlagCompleter {
 	return &FlagCompleter{}
 }
 
-// Supports checks if the tool supports --generate-shell-completion
+// Supports checks if the tool supports --generate-shell-completion (or --generate-bash-completion)
 // We verify by checking that it returns a simple list of words
 func (f *FlagCompleter) Supports(tool string, _ []string) bool {
-	// Test if tool accepts --generate-shell-completion (with timeout)
-	ctx := context.Background()
-	output, err := execWithTimeout(ctx, tool, "--generate-shell-completion")
+	for _, flag := range []string{shellCompletionFlag, bashCompletionFlag} {
+		// Test if tool accepts the completion flag (with timeout)
+		ctx, cancel := context.WithTimeout(context.Background(), DefaultCommandTimeout)
+		output, err := execWithTimeout(ctx, tool, flag)
+		cancel()
 
-	// If command failed or returned nothing, doesn't support
-	if err != nil || len(output) == 0 {
-		return false
+		// Use common validator to check if output looks like suggestions
+		if err == nil && validateSimpleOutput(output, 1) {
+			return true
+		}
 	}
 
-	// Use common validator to check if output looks like suggestions
-	return validateSimpleOutput(output, 1)
+	return false
 }
 
-// Complete uses --generate-shell-completion to get suggestions
+// Complete uses --generate-shell-completion to get suggestions,
+// falling back to --generate-bash-completion for urfave/cli v2 tools
 func (f *FlagCompleter) Complete(tool string, args []string) ([]Suggestion, error) {
-	// Build command: tool [args...] --generate-shell-completion
-	// Note: we pass all args INCLUDING the current word being completed
-	cmdArgs := append(args, "--generate-shell-completion")
-
-	ctx := context.Background()
-	output, err := execWithTimeout(ctx, tool, cmdArgs...)
+	output, err := f.run(tool, args, shellCompletionFlag)
 	if err != nil {
-		return nil, err
+		var fallbackErr error
+		if output, fallbackErr = f.run(tool, args, bashCompletionFlag); fallbackErr != nil {
+			return nil, err
+		}
 	}
 
 	return parseFlagOutput(output), nil
 }
 
+// run calls the tool with its arguments followed by the completion flag.
+// SHELL is set to zsh so urfave/cli prints 'name:usage' with descriptions.
+func (f *FlagCompleter) run(tool string, args []string, flag string) ([]byte, error) {
+	// Build command: tool [args...] --generate-shell-completion
+	// Note: we pass all args INCLUDING the current word being completed
+	cmdArgs := append(append([]string{}, args...), flag)
+
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultCommandTimeout)
+	defer cancel()
+	env := append(os.Environ(), "SHELL=/bin/zsh")
+	return execWithTimeoutAndEnv(ctx, env, tool, cmdArgs...)
+}
+
 // parseFlagOutput parses flag-based completion output
-// Format: one suggestion per line, no descriptions (simple list)
+// Format: one suggestion per line, optionally followed by ':description' like
+// zsh's _describe expects (colons in values are escaped as '\:')
 func parseFlagOutput(output []byte) []Suggestion {
-	// Use common parser without description support
-	return parseCompletionOutput(output, false)
+	suggestions := parseCompletionOutput(output, false)
+	for i, suggestion := range suggestions {
+		suggestions[i] = splitDescribeItem(suggestion.Value)
+	}
+	return suggestions
+}
+
+// splitDescribeItem splits a 'value:description' item at the first unescaped colon
+func splitDescribeItem(item string) Suggestion {
+	var value strings.Builder
+	for i := 0; i < len(item); i++ {
+		switch {
+		case item[i] == '\\' && i+1 < len(item) && item[i+1] == ':':
+			value.WriteByte(':')
+			i++
+		case item[i] == ':':
+			return Suggestion{Value: value.String(), Description: strings.TrimSpace(item[i+1:])}
+		default:
+			value.WriteByte(item[i])
+		}
+	}
+	return Suggestion{Value: value.String()}
 }
@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestFlagCompleter_New(t *testing.T) {
@@ -41,6 +42,15 @@ func TestFlagCompleter_parseFlagOutput(t *testing.T) {
 				{Value: "update", Description: ""},
 			},
 		},
+		{
+			name:  "zsh descriptions",
+			input: "export:Export the environment\nhost\\:port:Escaped colon\n--help\n",
+			expected: []Suggestion{
+				{Value: "export", Description: "Export the environment"},
+				{Value: "host:port", Description: "Escaped colon"},
+				{Value: "--help", Description: ""},
+			},
+		},
 		{
 			name:  "with whitespace",
 			input: "  export  \n  allow  \n",
@@ -87,3 +97,37 @@ func TestFlagCompleter_Complete_NonExistentCommand(t *testing.T) {
 	assert.Error(t, err, "Should return error for non-existent command")
 	assert.Nil(t, suggestions)
 }
+
+func TestFlagCompleter_Complete_Descriptions(t *testing.T) {
+	tool := writeMockTool(t, "mockurfave", `#!/bin/bash
+case "$SHELL" in
+*zsh) echo "deploy:Deploy the app" ;;
+*) echo "deploy" ;;
+esac
+`)
+
+	suggestions, err := NewFlagCompleter().Complete(tool, []string{""})
+	require.NoError(t, err)
+	assert.Equal(t, []Suggestion{{Value: "deploy", Description: "Deploy the app"}}, suggestions)
+}
+
+func TestFlagCompleter_UrfaveV2(t *testing.T) {
+	// urfave/cli v2 only knows --generate-bash-completion
+	tool := writeMockTool(t, "mockurfavev2", `#!/bin/bash
+for arg in "$@"; do
+    if [ "$arg" = "--generate-shell-completion" ]; then
+        echo "Incorrect Usage: flag provided but not defined" >&2
+        exit 1
+    fi
+done
+echo "build"
+echo "test"
+`)
+
+	f := NewFlagCompleter()
+	assert.True(t, f.Supports(tool, nil))
+
+	suggestions, err := f.Complete(tool, []string{""})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"build", "test"}, suggestionValues(suggestions))
+}
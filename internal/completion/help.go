@@ -0,0 +1,325 @@
+package completion
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// HelpCommandTimeout is the timeout of help commands, which may load more than completion does
+const HelpCommandTimeout = 1 * time.Second
+
+var (
+	// ansiEscape matches terminal colors and styles
+	ansiEscape = regexp.MustCompile(`\x1b\[[0-9;]*[A-Za-z]`)
+	// overstrike matches the bold and underline sequences of man pages ('N\bN')
+	overstrike = regexp.MustCompile(`.\x08`)
+	// helpColumns matches the gap between an entry and its description
+	helpColumns = regexp.MustCompile(`\s{2,}|\t`)
+	// helpFlagName matches a flag at the start of an option entry ('--output=FILE' -> '--output')
+	helpFlagName = regexp.MustCompile(`^--?[A-Za-z0-9?][\w.?-]*`)
+	// helpCommandName matches a subcommand name
+	helpCommandName = regexp.MustCompile(`^[A-Za-z][\w.:-]*$`)
+	// argparseChoices matches the subcommands of argparse ('{init,run}')
+	argparseChoices = regexp.MustCompile(`^\{([\w.:-]+(?:,[\w.:-]+)*)\}`)
+)
+
+// HelpCompleter is the last resort for tools no other completer supports.
+// It parses the output of 'tool --help' (or 'tool help') into subcommands and flags,
+// understanding GNU, Cobra, argparse and clap layouts. Parsed help texts are cached
+// per binary path and modification time.
+type HelpCompleter struct {
+	cacheDir string
+	cache    *HelpCache
+}
+
+// NewHelpCompleter creates a new help text completer
+func NewHelpCompleter(cacheDir string) *HelpCompleter {
+	return &HelpCompleter{cacheDir: cacheDir}
+}
+
+// Supports checks if the help text of the tool lists subcommands or flags
+func (h *HelpCompleter) Supports(tool string, _ []string) bool {
+	entry, err := h.help(tool, nil)
+	return err == nil && (len(entry.Commands) > 0 || len(entry.Flags) > 0)
+}
+
+// Complete suggests flags when the current word starts with '-', otherwise subcommands,
+// or flags when the command has no subcommands. Previous words that are subcommands
+// select the help text to read.
+func (h *HelpCompleter) Complete(tool string, args []string) ([]Suggestion, error) {
+	entry, err := h.help(tool, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	current := ""
+	var subcommands []string
+	if len(args) > 0 {
+		current = args[len(args)-1]
+		for _, word := range args[:len(args)-1] {
+			if !containsSuggestion(entry.Commands, word) {
+				continue
+			}
+			subcommands = append(subcommands, word)
+			subEntry, err := h.help(tool, subcommands)
+			if err != nil {
+				// Keep the flags and subcommands of the parent
+				break
+			}
+			entry = subEntry
+		}
+	}
+
+	if strings.HasPrefix(current, "-") || len(entry.Commands) == 0 {
+		return entry.Flags, nil
+	}
+	return entry.Commands, nil
+}
+
+// help returns the parsed help of a command, from the cache when the binary did not change
+func (h *HelpCompleter) help(tool string, subcommands []string) (HelpCacheEntry, error) {
+	path, err := exec.LookPath(tool)
+	if err != nil {
+		return HelpCacheEntry{}, err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return HelpCacheEntry{}, err
+	}
+
+	cache := h.loadCache()
+	key := strings.Join(append([]string{path}, subcommands...), " ")
+	if cache != nil {
+		if entry, ok := cache.Get(key, info.ModTime()); ok {
+			return entry, nil
+		}
+	}
+
+	var entry HelpCacheEntry
+	for _, helpArgs := range [][]string{
+		append(append([]string{}, subcommands...), "--help"),
+		append([]string{"help"}, subcommands...),
+	} {
+		output, err := runHelp(path, helpArgs)
+		if err != nil {
+			continue
+		}
+		if entry.Commands, entry.Flags = parseHelpText(string(output)); len(entry.Commands) > 0 || len(entry.Flags) > 0 {
+			break
+		}
+	}
+	if len(entry.Commands) == 0 && len(entry.Flags) == 0 {
+		return HelpCacheEntry{}, fmt.Errorf("no help text found for %s", key)
+	}
+
+	if cache != nil {
+		entry.ModTime = info.ModTime()
+		cache.Set(key, entry)
+		_ = cache.Save()
+	}
+	return entry, nil
+}
+
+// loadCache loads the help cache on first use, nil without cache directory
+func (h *HelpCompleter) loadCache() *HelpCache {
+	if h.cache == nil && h.cacheDir != "" {
+		h.cache, _ = NewHelpCache(filepath.Join(h.cacheDir, "completion-help.json"))
+	}
+	return h.cache
+}
+
+// runHelp runs a help command. Tools print help on stdout or stderr and some
+// exit with an error code after printing it, so both outputs are kept whatever the status.
+// Pagers are disabled for tools opening man pages.
+func runHelp(path string, args []string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), HelpCommandTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, path, args...)
+	cmd.Env = append(os.Environ(), "PAGER=cat", "MANPAGER=cat", "GIT_PAGER=cat", "NO_COLOR=1")
+	output, err := cmd.CombinedOutput()
+	if ctx.Err() == context.DeadlineExceeded {
+		return nil, fmt.Errorf("command timeout after %v: %w", HelpCommandTimeout, err)
+	}
+	if len(output) == 0 {
+		return nil, fmt.Errorf("no output from %s: %w", path, err)
+	}
+
+	if len(output) > MaxOutputSize {
+		output = output[:MaxOutputSize]
+	}
+	return output, nil
+}
+
+// helpSection is the kind of a section of a help text
+type helpSection int
+
+const (
+	sectionOther      helpSection = iota
+	sectionCommands               // 'Commands:', 'Available Commands:', 'SUBCOMMANDS', ...
+	sectionPositional             // argparse 'positional arguments:', listing subcommands in braces
+)
+
+// parseHelpText extracts subcommands and flags with their descriptions from a help text.
+// Flags are option entries starting with '-' anywhere in the text; subcommands are the
+// entries of sections whose heading mentions commands, and argparse subcommand choices.
+func parseHelpText(text string) (commands, flags []Suggestion) {
+	text = overstrike.ReplaceAllString(ansiEscape.ReplaceAllString(text, ""), "")
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+
+	section := sectionOther
+	entryIndent := -1
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimRight(lines[i], " \t")
+		trimmed := strings.TrimLeft(line, " \t")
+		if trimmed == "" {
+			continue
+		}
+		indent := len(line) - len(trimmed)
+
+		// A non-indented line is a section heading (or usage and text lines)
+		if indent == 0 {
+			// git groups its commands under lowercase headings after the first one
+			if section != sectionCommands || !startsLowercase(trimmed) {
+				section = helpSectionKind(trimmed)
+			}
+			entryIndent = -1
+			if !strings.HasPrefix(trimmed, "-") {
+				continue
+			}
+		}
+
+		entry, description := splitHelpColumns(trimmed)
+		if description == "" && i+1 < len(lines) {
+			// Description wrapped on the next, more indented, line
+			next := strings.TrimRight(lines[i+1], " \t")
+			nextTrimmed := strings.TrimLeft(next, " \t")
+			if nextTrimmed != "" && len(next)-len(nextTrimmed) > indent && !strings.HasPrefix(nextTrimmed, "-") {
+				description = nextTrimmed
+			}
+		}
+
+		if strings.HasPrefix(trimmed, "-") {
+			for _, name := range helpFlagNames(entry) {
+				flags = addHelpSuggestion(flags, name, description)
+			}
+			continue
+		}
+
+		switch section {
+		case sectionCommands:
+			// Deeper lines are wrapped descriptions of the previous entry
+			if entryIndent == -1 {
+				entryIndent = indent
+			}
+			if indent != entryIndent {
+				continue
+			}
+			for _, name := range helpCommandNames(entry) {
+				commands = addHelpSuggestion(commands, name, description)
+			}
+		case sectionPositional:
+			if match := argparseChoices.FindStringSubmatch(entry); match != nil {
+				for _, name := range strings.Split(match[1], ",") {
+					commands = addHelpSuggestion(commands, name, "")
+				}
+			} else if containsSuggestion(commands, entry) {
+				commands = addHelpSuggestion(commands, entry, description)
+			}
+		}
+	}
+
+	return commands, flags
+}
+
+// helpSectionKind returns the kind of section a heading starts
+func helpSectionKind(heading string) helpSection {
+	heading = strings.ToLower(strings.TrimSuffix(heading, ":"))
+	switch {
+	case strings.Contains(heading, "usage"):
+		return sectionOther
+	case strings.Contains(heading, "command"):
+		return sectionCommands
+	case heading == "positional arguments":
+		return sectionPositional
+	}
+	return sectionOther
+}
+
+// splitHelpColumns splits an entry from its description, separated by two spaces or more
+func splitHelpColumns(line string) (entry, description string) {
+	loc := helpColumns.FindStringIndex(line)
+	if loc == nil {
+		return line, ""
+	}
+	return line[:loc[0]], strings.TrimSpace(line[loc[1]:])
+}
+
+// helpFlagNames returns the flags of an option entry ('-o, --output <FILE>' -> '-o', '--output')
+func helpFlagNames(entry string) []string {
+	var names []string
+	for _, part := range strings.Split(entry, ",") {
+		name := strings.TrimRight(helpFlagName.FindString(strings.TrimSpace(part)), ".")
+		if name != "" && name != "-" && name != "--" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// helpCommandNames returns the subcommands of a command entry: its first word
+// ('run [options] <script>' -> 'run'), or all words of a comma-separated list
+// ('build, b' or npm's 'access, adduser, audit')
+func helpCommandNames(entry string) []string {
+	var names []string
+	for _, part := range strings.Split(entry, ",") {
+		name := strings.TrimSpace(part)
+		if !helpCommandName.MatchString(name) {
+			break
+		}
+		names = append(names, name)
+	}
+	if len(names) > 0 {
+		return names
+	}
+
+	name := strings.Fields(entry)[0]
+	if helpCommandName.MatchString(name) {
+		return []string{name}
+	}
+	return nil
+}
+
+// startsLowercase checks if a line starts with a lowercase letter
+func startsLowercase(line string) bool {
+	return line[0] >= 'a' && line[0] <= 'z'
+}
+
+// addHelpSuggestion adds a suggestion once, completing the description of an existing one
+func addHelpSuggestion(suggestions []Suggestion, value, description string) []Suggestion {
+	for i, suggestion := range suggestions {
+		if suggestion.Value == value {
+			if suggestion.Description == "" {
+				suggestions[i].Description = description
+			}
+			return suggestions
+		}
+	}
+	return append(suggestions, Suggestion{Value: value, Description: description})
+}
+
+// containsSuggestion checks if a value is among the suggestions
+func containsSuggestion(suggestions []Suggestion, value string) bool {
+	for _, suggestion := range suggestions {
+		if suggestion.Value == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,98 @@
+package completion
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// HelpCacheEntry stores what was parsed from the help text of a command,
+// with the modification time of the binary it was read from
+type HelpCacheEntry struct {
+	ModTime  time.Time    `json:"mod_time"`
+	Commands []Suggestion `json:"commands"`
+	Flags    []Suggestion `json:"flags"`
+}
+
+// HelpCache persists parsed help texts, keyed by binary path and subcommands
+// An entry is valid as long as the binary is not modified (upgraded or reinstalled)
+type HelpCache struct {
+	mu       sync.RWMutex
+	path     string
+	cache    map[string]HelpCacheEntry
+	modified bool
+}
+
+// NewHelpCache creates or loads a help cache
+func NewHelpCache(cachePath string) (*HelpCache, error) {
+	c := &HelpCache{
+		path:  cachePath,
+		cache: make(map[string]HelpCacheEntry),
+	}
+
+	// Try to load existing cache
+	if err := c.load(); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// Get returns the entry for a key if it was parsed from a binary with the same modification time
+func (c *HelpCache) Get(key string, modTime time.Time) (HelpCacheEntry, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	entry, ok := c.cache[key]
+	if !ok || !entry.ModTime.Equal(modTime) {
+		return HelpCacheEntry{}, false
+	}
+	return entry, true
+}
+
+// Set stores the entry for a key
+func (c *HelpCache) Set(key string, entry HelpCacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.cache[key] = entry
+	c.modified = true
+}
+
+// Save persists the cache to disk if it was modified
+func (c *HelpCache) Save() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.modified {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(c.cache)
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(c.path, data, 0644); err != nil {
+		return err
+	}
+
+	c.modified = false
+	return nil
+}
+
+// load reads the cache from disk
+func (c *HelpCache) load() error {
+	data, err := os.ReadFile(c.path)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, &c.cache)
+}
@@ -0,0 +1,54 @@
+package completion
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHelpCache_GetSet(t *testing.T) {
+	cache, err := NewHelpCache(filepath.Join(t.TempDir(), "help.json"))
+	require.NoError(t, err)
+
+	modTime := time.Now().Truncate(time.Second)
+	cache.Set("/usr/bin/tool", HelpCacheEntry{ModTime: modTime, Commands: []Suggestion{{Value: "run"}}})
+
+	entry, ok := cache.Get("/usr/bin/tool", modTime)
+	assert.True(t, ok)
+	assert.Equal(t, []Suggestion{{Value: "run"}}, entry.Commands)
+
+	// Binary modified
+	_, ok = cache.Get("/usr/bin/tool", modTime.Add(time.Second))
+	assert.False(t, ok)
+
+	_, ok = cache.Get("/usr/bin/other", modTime)
+	assert.False(t, ok)
+}
+
+func TestHelpCache_SaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sub", "help.json")
+	cache, err := NewHelpCache(path)
+	require.NoError(t, err)
+
+	modTime := time.Now()
+	cache.Set("/usr/bin/tool run", HelpCacheEntry{ModTime: modTime, Flags: []Suggestion{{Value: "--force", Description: "Force"}}})
+	require.NoError(t, cache.Save())
+
+	loaded, err := NewHelpCache(path)
+	require.NoError(t, err)
+	entry, ok := loaded.Get("/usr/bin/tool run", modTime)
+	assert.True(t, ok)
+	assert.Equal(t, []Suggestion{{Value: "--force", Description: "Force"}}, entry.Flags)
+}
+
+func TestHelpCache_InvalidFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "help.json")
+	require.NoError(t, os.WriteFile(path, []byte("not json"), 0644))
+
+	_, err := NewHelpCache(path)
+	assert.Error(t, err)
+}
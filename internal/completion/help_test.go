@@ -0,0 +1,220 @@
+package completion
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const gnuHelp = `Usage: ls [OPTION]... [FILE]...
+List information about the FILEs (the current directory by default).
+
+Mandatory arguments to long options are mandatory for short options too.
+  -a, --all                  do not ignore entries starting with .
+      --block-size=SIZE      with -l, scale sizes by SIZE when printing them;
+                               e.g., '--block-size=M'; see SIZE format below
+  -C                         list entries by columns
+      --color[=WHEN]         color the output WHEN; more info below
+`
+
+const cobraHelp = `kubectl controls the Kubernetes cluster manager.
+
+Basic Commands (Beginner):
+  create          Create a resource from a file or from stdin
+  expose          Take a replication controller, service, deployment or pod and expose it
+
+Basic Commands (Intermediate):
+  get             Display one or many resources
+
+Usage:
+  kubectl [flags] [options]
+
+Flags:
+  -h, --help             help for kubectl
+      --context string   The name of the kubeconfig context to use
+`
+
+const argparseHelp = `usage: tool [-h] [--verbose] {init,run} ...
+
+positional arguments:
+  {init,run}
+    init         Initialize a project
+    run          Run the project
+
+options:
+  -h, --help     show this help message and exit
+  --format {json,yaml}
+                 output format
+`
+
+const clapHelp = "A fast build tool\n\n" +
+	"\x1b[1mUsage:\x1b[0m mytool [OPTIONS] <COMMAND>\n\n" +
+	"\x1b[1mCommands:\x1b[0m\n" +
+	"  build, b  Compile the project\n" +
+	"  check\n" +
+	"          Analyze without building\n" +
+	"  help      Print this message or the help of the given subcommand(s)\n\n" +
+	"\x1b[1mOptions:\x1b[0m\n" +
+	"  -v, --verbose...       More output per occurrence\n" +
+	"  -c, --config <FILE>    Config file\n"
+
+func TestParseHelpText_GNU(t *testing.T) {
+	commands, flags := parseHelpText(gnuHelp)
+
+	assert.Empty(t, commands)
+	assert.Equal(t, []Suggestion{
+		{Value: "-a", Description: "do not ignore entries starting with ."},
+		{Value: "--all", Description: "do not ignore entries starting with ."},
+		{Value: "--block-size", Description: "with -l, scale sizes by SIZE when printing them;"},
+		{Value: "-C", Description: "list entries by columns"},
+		{Value: "--color", Description: "color the output WHEN; more info below"},
+	}, flags)
+}
+
+func TestParseHelpText_Cobra(t *testing.T) {
+	commands, flags := parseHelpText(cobraHelp)
+
+	assert.Equal(t, []Suggestion{
+		{Value: "create", Description: "Create a resource from a file or from stdin"},
+		{Value: "expose", Description: "Take a replication controller, service, deployment or pod and expose it"},
+		{Value: "get", Description: "Display one or many resources"},
+	}, commands)
+	assert.Equal(t, []string{"-h", "--help", "--context"}, suggestionValues(flags))
+	assert.Equal(t, "The name of the kubeconfig context to use", flags[2].Description)
+}
+
+func TestParseHelpText_Argparse(t *testing.T) {
+	commands, flags := parseHelpText(argparseHelp)
+
+	assert.Equal(t, []Suggestion{
+		{Value: "init", Description: "Initialize a project"},
+		{Value: "run", Description: "Run the project"},
+	}, commands)
+	assert.Equal(t, []Suggestion{
+		{Value: "-h", Description: "show this help message and exit"},
+		{Value: "--help", Description: "show this help message and exit"},
+		{Value: "--format", Description: "output format"},
+	}, flags)
+}
+
+func TestParseHelpText_Clap(t *testing.T) {
+	commands, flags := parseHelpText(clapHelp)
+
+	assert.Equal(t, []Suggestion{
+		{Value: "build", Description: "Compile the project"},
+		{Value: "b", Description: "Compile the project"},
+		{Value: "check", Description: "Analyze without building"},
+		{Value: "help", Description: "Print this message or the help of the given subcommand(s)"},
+	}, commands)
+	assert.Equal(t, []string{"-v", "--verbose", "-c", "--config"}, suggestionValues(flags))
+}
+
+func TestParseHelpText_GitGroups(t *testing.T) {
+	commands, _ := parseHelpText(`usage: git [-v | --version] <command> [<args>]
+
+These are common Git commands used in various situations:
+
+start a working area (see also: git help tutorial)
+   clone     Clone a repository into a new directory
+
+work on the current change (see also: git help everyday)
+   add       Add file contents to the index
+
+'git help -a' and 'git help -g' list available subcommands.
+`)
+
+	assert.Equal(t, []string{"clone", "add"}, suggestionValues(commands))
+}
+
+func TestParseHelpText_CommaList(t *testing.T) {
+	commands, _ := parseHelpText("All commands:\n\n    access, adduser, audit,\n    bugs\n")
+
+	assert.Equal(t, []string{"access", "adduser", "audit", "bugs"}, suggestionValues(commands))
+}
+
+// mockHelpTool prints a Cobra-like help, with a 'deploy' subcommand
+const mockHelpTool = `#!/bin/bash
+echo "$*" >> "$(dirname "$0")/calls"
+case "$*" in
+  "--help")
+    printf 'Available Commands:\n  deploy   Deploy the app\n  status   Show status\n\nFlags:\n  -v, --verbose   Verbose output\n'
+    ;;
+  "deploy --help")
+    printf 'Usage: mock deploy [flags]\n\nFlags:\n      --env string   Target environment\n'
+    ;;
+  *)
+    exit 1
+    ;;
+esac
+`
+
+func TestHelpCompleter_Complete(t *testing.T) {
+	tool := writeMockTool(t, "mock-help", mockHelpTool)
+	h := NewHelpCompleter(t.TempDir())
+
+	assert.True(t, h.Supports(tool, nil))
+
+	suggestions, err := h.Complete(tool, []string{""})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"deploy", "status"}, suggestionValues(suggestions))
+
+	suggestions, err = h.Complete(tool, []string{"--v"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"-v", "--verbose"}, suggestionValues(suggestions))
+
+	// Flags of the subcommand, after a flag; no subcommands so flags are suggested
+	suggestions, err = h.Complete(tool, []string{"-v", "deploy", ""})
+	require.NoError(t, err)
+	assert.Equal(t, []Suggestion{{Value: "--env", Description: "Target environment"}}, suggestions)
+
+	// Subcommand without help: the parent's suggestions are kept
+	suggestions, err = h.Complete(tool, []string{"status", ""})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"deploy", "status"}, suggestionValues(suggestions))
+}
+
+func TestHelpCompleter_HelpSubcommand(t *testing.T) {
+	tool := writeMockTool(t, "mock-help", `#!/bin/bash
+[ "$1" = "help" ] || { echo "unknown flag: $1" >&2; exit 2; }
+printf 'COMMANDS\n  start   Start the service\n'
+`)
+
+	suggestions, err := NewHelpCompleter("").Complete(tool, []string{""})
+	require.NoError(t, err)
+	assert.Equal(t, []Suggestion{{Value: "start", Description: "Start the service"}}, suggestions)
+}
+
+func TestHelpCompleter_NoHelp(t *testing.T) {
+	h := NewHelpCompleter(t.TempDir())
+
+	assert.False(t, h.Supports(writeMockTool(t, "mock-silent", "#!/bin/sh\nexit 0\n"), nil))
+	assert.False(t, h.Supports("nonexistent-command-xyz", nil))
+}
+
+func TestHelpCompleter_Cache(t *testing.T) {
+	tool := writeMockTool(t, "mock-help", mockHelpTool)
+	calls := filepath.Join(filepath.Dir(tool), "calls")
+	cacheDir := t.TempDir()
+
+	_, err := NewHelpCompleter(cacheDir).Complete(tool, []string{""})
+	require.NoError(t, err)
+	assert.FileExists(t, filepath.Join(cacheDir, "completion-help.json"))
+
+	// Read from the cache by a new completer
+	require.NoError(t, os.Remove(calls))
+	suggestions, err := NewHelpCompleter(cacheDir).Complete(tool, []string{""})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"deploy", "status"}, suggestionValues(suggestions))
+	assert.NoFileExists(t, calls)
+
+	// The binary changed: help is read again
+	later := time.Now().Add(time.Hour)
+	require.NoError(t, os.Chtimes(tool, later, later))
+	_, err = NewHelpCompleter(cacheDir).Complete(tool, []string{""})
+	require.NoError(t, err)
+	assert.FileExists(t, calls)
+}
@@ -12,7 +12,7 @@ import (
 type DetectionInfo struct {
 	Path     string
 	Size     int64
-	Commands map[string]string // command -> source type
+	Commands map[string]string // command -> source type (binary path -> source type when the name is ambiguous)
 }
 
 // RegistryInfo contains information about the completion registry
@@ -22,11 +22,21 @@ type RegistryInfo struct {
 	ToolsCount int
 }
 
+// ResultCacheInfo contains information about the completion result cache
+type ResultCacheInfo struct {
+	Path    string
+	Size    int64
+	Entries int
+	Stats   ResultCacheStats
+}
+
 // ScriptInfo contains information about a downloaded completion script
 type ScriptInfo struct {
-	Tool string
-	Path string
-	Size int64
+	Tool   string
+	Shell  string // bash, or zsh and fish for native scripts
+	Path   string
+	Size   int64
+	Status ScriptStatus // Integrity compared to the script lockfile, empty if the lockfile can't be read
 }
 
 // GetDetectionCacheInfo returns information about the detection cache
@@ -53,21 +63,57 @@ func GetDetectionCacheInfo(cacheDir string) (*DetectionInfo, error) {
 		return result, nil // Return partial info
 	}
 
-	type cacheEntry struct {
-		CompleterType string `json:"completer_type"`
-	}
-	var detections map[string]cacheEntry
+	var detections map[string]CacheEntry
 	if err := json.Unmarshal(data, &detections); err != nil {
 		return result, nil // Return partial info
 	}
 
-	for cmd, entry := range detections {
+	// Entries are keyed by binary path: show tool names, unless several binaries share one
+	names := make(map[string]int)
+	for _, entry := range detections {
+		if entry.Tool != "" {
+			names[entry.Tool]++
+		}
+	}
+	for key, entry := range detections {
+		cmd := key
+		if entry.Tool != "" && names[entry.Tool] == 1 {
+			cmd = entry.Tool
+		}
 		result.Commands[cmd] = entry.CompleterType
 	}
 
 	return result, nil
 }
 
+// GetResultCacheInfo returns information about the completion result cache
+func GetResultCacheInfo(cacheDir string) (*ResultCacheInfo, error) {
+	resultCachePath := filepath.Join(cacheDir, "completion-results.json")
+
+	info, err := os.Stat(resultCachePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil // No cache yet
+		}
+		return nil, err
+	}
+
+	result := &ResultCacheInfo{
+		Path: resultCachePath,
+		Size: info.Size(),
+	}
+
+	data, err := readResultCacheFile(resultCachePath)
+	if err != nil {
+		return result, nil // Return partial info
+	}
+
+	result.Entries = len(data.Entries)
+	result.Stats = readResultCacheStats(resultCachePath, data)
+
+	return result, nil
+}
+
 // GetRegistryInfo returns information about the completion registry
 func GetRegistryInfo(cacheDir string) (*RegistryInfo, error) {
 	registryPath := filepath.Join(cacheDir, "completion-registry-v1.yml")
@@ -105,35 +151,45 @@ func GetRegistryInfo(cacheDir string) (*RegistryInfo, error) {
 }
 
 // GetDownloadedScripts returns information about downloaded completion scripts
+// Bash scripts come first, then the native zsh and fish scripts
 func GetDownloadedScripts(cacheDir string) ([]ScriptInfo, error) {
 	scriptsPath := filepath.Join(cacheDir, "completion-scripts")
-	bashScriptsPath := filepath.Join(scriptsPath, "bash")
-
-	entries, err := os.ReadDir(bashScriptsPath)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return nil, nil // No scripts yet
-		}
-		return nil, err
-	}
+	lock, lockErr := LoadScriptLock(cacheDir)
 
 	var scripts []ScriptInfo
-	for _, entry := range entries {
-		if entry.IsDir() {
-			continue
-		}
+	for _, shell := range []string{"bash", "zsh", "fish"} {
+		shellScriptsPath := filepath.Join(scriptsPath, shell)
 
-		scriptPath := filepath.Join(bashScriptsPath, entry.Name())
-		info, err := os.Stat(scriptPath)
+		entries, err := os.ReadDir(shellScriptsPath)
 		if err != nil {
-			continue
+			if os.IsNotExist(err) {
+				continue // No scripts for this shell yet
+			}
+			return nil, err
 		}
 
-		scripts = append(scripts, ScriptInfo{
-			Tool: entry.Name(),
-			Path: scriptPath,
-			Size: info.Size(),
-		})
+		for _, entry := range entries {
+			if entry.IsDir() {
+				continue
+			}
+
+			scriptPath := filepath.Join(shellScriptsPath, entry.Name())
+			info, err := os.Stat(scriptPath)
+			if err != nil {
+				continue
+			}
+
+			script := ScriptInfo{
+				Tool:  entry.Name(),
+				Shell: shell,
+				Path:  scriptPath,
+				Size:  info.Size(),
+			}
+			if lockErr == nil {
+				script.Status = lock.CheckShell(cacheDir, entry.Name(), shell).Status
+			}
+			scripts = append(scripts, script)
+		}
 	}
 
 	return scripts, nil
@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -139,3 +140,91 @@ func TestGetDownloadedScripts_WithSubdirectories(t *testing.T) {
 	assert.Len(t, result, 1)
 	assert.Equal(t, "tool.sh", result[0].Tool)
 }
+
+// TestGetResultCacheInfo tests the result cache info and statistics
+func TestGetResultCacheInfo(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	result, err := GetResultCacheInfo(tmpDir)
+	require.NoError(t, err)
+	assert.Nil(t, result) // Should return nil when no cache exists
+
+	cache, err := NewResultCache(filepath.Join(tmpDir, "completion-results.json"))
+	require.NoError(t, err)
+	cache.Get("helm", time.Minute)
+	cache.Set("helm", &Result{Suggestions: []Suggestion{{Value: "install"}}, Source: "Cobra"})
+	require.NoError(t, cache.Save())
+
+	result, err = GetResultCacheInfo(tmpDir)
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	assert.Equal(t, 1, result.Entries)
+	assert.Equal(t, ResultCacheStats{Misses: 1}, result.Stats)
+}
+
+// TestGetDetectionCacheInfo_BinaryKeys tests that tool names are shown for binary path keys
+func TestGetDetectionCacheInfo_BinaryKeys(t *testing.T) {
+	tmpDir := t.TempDir()
+	detectionPath := filepath.Join(tmpDir, "completion-detection.json")
+
+	err := os.WriteFile(detectionPath, []byte(`{
+		"/usr/bin/kubectl": {"completer_type": "Cobra", "tool": "kubectl"},
+		"/usr/bin/terraform": {"completer_type": "Env", "tool": "terraform"},
+		"/opt/tf/bin/terraform": {"completer_type": "Help", "tool": "terraform"},
+		"legacy": {"completer_type": "Flag"}
+	}`), 0644)
+	require.NoError(t, err)
+
+	result, err := GetDetectionCacheInfo(tmpDir)
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	assert.Equal(t, map[string]string{
+		"kubectl":               "Cobra",
+		"/usr/bin/terraform":    "Env",
+		"/opt/tf/bin/terraform": "Help",
+		"legacy":                "Flag",
+	}, result.Commands)
+}
+
+// TestGetDownloadedScripts_Integrity tests that scripts are checked against the lockfile
+func TestGetDownloadedScripts_Integrity(t *testing.T) {
+	tmpDir := t.TempDir()
+	installTestScript(t, tmpDir, "kubectl", "kubectl completion\n")
+	installTestScript(t, tmpDir, "helm", "helm completion\n")
+	installTestScript(t, tmpDir, "manual", "manual completion\n")
+
+	lock, err := LoadScriptLock(tmpDir)
+	require.NoError(t, err)
+	lock.Record("kubectl", ScriptLockEntry{SHA256: computeHash([]byte("kubectl completion\n"))})
+	lock.Record("helm", ScriptLockEntry{SHA256: computeHash([]byte("original helm completion\n"))})
+	require.NoError(t, lock.Save())
+
+	scripts, err := GetDownloadedScripts(tmpDir)
+	require.NoError(t, err)
+	statuses := make(map[string]ScriptStatus)
+	for _, script := range scripts {
+		statuses[script.Tool] = script.Status
+	}
+	assert.Equal(t, map[string]ScriptStatus{
+		"kubectl": ScriptIntact,
+		"helm":    ScriptModified,
+		"manual":  ScriptUnrecorded,
+	}, statuses)
+}
+
+// TestGetDownloadedScripts_Native tests that native zsh and fish scripts are listed after bash scripts
+func TestGetDownloadedScripts_Native(t *testing.T) {
+	tmpDir := t.TempDir()
+	installNativeScript(t, tmpDir, "kubectl", "fish", "complete -c kubectl\n")
+	installNativeScript(t, tmpDir, "kubectl", "zsh", "#compdef kubectl\n")
+	installTestScript(t, tmpDir, "kubectl", "kubectl completion\n")
+
+	scripts, err := GetDownloadedScripts(tmpDir)
+	require.NoError(t, err)
+	require.Len(t, scripts, 3)
+	assert.Equal(t, "bash", scripts[0].Shell)
+	assert.Equal(t, "zsh", scripts[1].Shell)
+	assert.Equal(t, GetCompletionScriptPath(tmpDir, "kubectl", "zsh"), scripts[1].Path)
+	assert.Equal(t, ScriptUnrecorded, scripts[1].Status)
+	assert.Equal(t, "fish", scripts[2].Shell)
+}
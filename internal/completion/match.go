@@ -0,0 +1,180 @@
+package completion
+
+import (
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// MatchMode selects how suggestions are matched against the word being completed
+type MatchMode string
+
+const (
+	// MatchPrefix keeps suggestions starting with the word (default)
+	MatchPrefix MatchMode = "prefix"
+	// MatchIgnoreCase keeps suggestions starting with the word, ignoring case
+	MatchIgnoreCase MatchMode = "ignore-case"
+	// MatchSubstring keeps suggestions containing the word, ranked by where it starts
+	MatchSubstring MatchMode = "substring"
+	// MatchFuzzy keeps suggestions containing the characters of the word in order, ranked by score
+	MatchFuzzy MatchMode = "fuzzy"
+)
+
+// MatchModes are all the matching modes, the default first
+var MatchModes = []MatchMode{MatchPrefix, MatchIgnoreCase, MatchSubstring, MatchFuzzy}
+
+// Scores of fuzzy matches
+const (
+	fuzzyMatchScore       = 16 // Each character of the word
+	fuzzyConsecutiveBonus = 12 // Character right after the previous one
+	fuzzyBoundaryBonus    = 10 // Character at the start of the value or of a word (after - _ . / : or a case change)
+	fuzzyGapPenalty       = 1  // Each character skipped between two characters of the word, up to fuzzyMaxGapPenalty
+	fuzzyMaxGapPenalty    = 8
+
+	substringBoundaryBonus = 1 << 16 // Substring at the start of a word, twice at the start of the value
+)
+
+// ParseMatchMode returns the matching mode of a name, prefix if empty or unknown
+func ParseMatchMode(name string) MatchMode {
+	if mode := MatchMode(name); slices.Contains(MatchModes, mode) {
+		return mode
+	}
+	return MatchPrefix
+}
+
+// Match keeps the suggestions matching the word being completed and ranks them: best score
+// first, then alphabetically. With keepOrder (Cobra's ShellCompDirectiveKeepOrder, or sources
+// declared in order), suggestions are only filtered and keep the order of the tool.
+func Match(suggestions []Suggestion, word string, mode MatchMode, keepOrder bool) []Suggestion {
+	type match struct {
+		suggestion Suggestion
+		score      int
+	}
+
+	matches := make([]match, 0, len(suggestions))
+	for _, s := range suggestions {
+		if score, ok := matchScore(s.Value, word, mode); ok {
+			matches = append(matches, match{suggestion: s, score: score})
+		}
+	}
+
+	if !keepOrder {
+		sort.SliceStable(matches, func(i, j int) bool {
+			if matches[i].score != matches[j].score {
+				return matches[i].score > matches[j].score
+			}
+			return matches[i].suggestion.Value < matches[j].suggestion.Value
+		})
+	}
+
+	result := make([]Suggestion, len(matches))
+	for i, m := range matches {
+		result[i] = m.suggestion
+	}
+	return result
+}
+
+// matchScore reports whether a value matches the word in a mode, with its score (higher is better)
+// Substring and fuzzy matches ignore case unless the word has an upper case letter
+func matchScore(value, word string, mode MatchMode) (int, bool) {
+	if word == "" {
+		return 0, true
+	}
+
+	switch mode {
+	case MatchIgnoreCase:
+		return 0, len(value) >= len(word) && strings.EqualFold(value[:len(word)], word)
+	case MatchSubstring:
+		value, word = smartCase(value, word)
+		index := strings.Index(value, word)
+		if index < 0 {
+			return 0, false
+		}
+		// Prefixes first, then matches at the start of a word, then the earliest
+		score := -index
+		switch {
+		case index == 0:
+			score += 2 * substringBoundaryBonus
+		case isBoundary(value, index):
+			score += substringBoundaryBonus
+		}
+		return score, true
+	case MatchFuzzy:
+		return fuzzyScore(value, word)
+	default:
+		return 0, strings.HasPrefix(value, word)
+	}
+}
+
+// fuzzyScore matches the characters of the word in order in the value. Every start of the
+// first character is tried, and the best score is kept.
+func fuzzyScore(value, word string) (int, bool) {
+	original := value
+	value, word = smartCase(value, word)
+	if len(original) != len(value) {
+		original = value // Case folding changed the length: boundaries are taken on the folded value
+	}
+	first, _ := utf8.DecodeRuneInString(word)
+
+	best, found := 0, false
+	for start, r := range value {
+		if r != first {
+			continue
+		}
+		if score, ok := fuzzyScoreFrom(original, value, word, start); ok && (!found || score > best) {
+			best, found = score, true
+		}
+	}
+	return best, found
+}
+
+// fuzzyScoreFrom matches the word in the value from a start index, taking each next character
+// at its first occurrence. original is the value before case folding, for word boundaries.
+func fuzzyScoreFrom(original, value, word string, start int) (int, bool) {
+	score := 0
+	pos := start // End of the previous character of the word in the value
+	for i, r := range word {
+		index := strings.IndexRune(value[pos:], r)
+		if index < 0 {
+			return 0, false
+		}
+		index += pos
+
+		score += fuzzyMatchScore
+		switch {
+		case i == 0:
+		case index == pos:
+			score += fuzzyConsecutiveBonus
+		default:
+			score -= min(index-pos, fuzzyMaxGapPenalty) * fuzzyGapPenalty
+		}
+		if isBoundary(original, index) {
+			score += fuzzyBoundaryBonus
+		}
+
+		pos = index + utf8.RuneLen(r)
+	}
+
+	// Matches starting late and long values rank lower
+	return score - min(start, fuzzyMaxGapPenalty) - len(value)/8, true
+}
+
+// isBoundary reports whether the character at index starts a word of the value
+func isBoundary(value string, index int) bool {
+	if index == 0 {
+		return true
+	}
+	prev, _ := utf8.DecodeLastRuneInString(value[:index])
+	cur, _ := utf8.DecodeRuneInString(value[index:])
+	return strings.ContainsRune("-_./: =", prev) || (unicode.IsLower(prev) && unicode.IsUpper(cur))
+}
+
+// smartCase lowers the value and the word, unless the word has an upper case letter
+func smartCase(value, word string) (string, string) {
+	if strings.IndexFunc(word, unicode.IsUpper) >= 0 {
+		return value, word
+	}
+	return strings.ToLower(value), word
+}
@@ -0,0 +1,88 @@
+package completion
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// values returns the values of suggestions
+func values(suggestions []Suggestion) []string {
+	result := make([]string, len(suggestions))
+	for i, s := range suggestions {
+		result[i] = s.Value
+	}
+	return result
+}
+
+func TestParseMatchMode(t *testing.T) {
+	assert.Equal(t, MatchPrefix, ParseMatchMode(""))
+	assert.Equal(t, MatchPrefix, ParseMatchMode("prefix"))
+	assert.Equal(t, MatchIgnoreCase, ParseMatchMode("ignore-case"))
+	assert.Equal(t, MatchSubstring, ParseMatchMode("substring"))
+	assert.Equal(t, MatchFuzzy, ParseMatchMode("fuzzy"))
+	assert.Equal(t, MatchPrefix, ParseMatchMode("unknown"))
+}
+
+func TestMatch(t *testing.T) {
+	suggestions := []Suggestion{
+		{Value: "get"},
+		{Value: "apply"},
+		{Value: "port-forward"},
+		{Value: "Annotate"},
+		{Value: "api-resources"},
+		{Value: "rollout"},
+	}
+
+	tests := []struct {
+		name string
+		word string
+		mode MatchMode
+		want []string
+	}{
+		{name: "prefix sorted", word: "", mode: MatchPrefix, want: []string{"Annotate", "api-resources", "apply", "get", "port-forward", "rollout"}},
+		{name: "prefix", word: "ap", mode: MatchPrefix, want: []string{"api-resources", "apply"}},
+		{name: "prefix is case sensitive", word: "an", mode: MatchPrefix, want: []string{}},
+		{name: "ignore case", word: "an", mode: MatchIgnoreCase, want: []string{"Annotate"}},
+		{name: "substring ranks prefixes then word starts", word: "r", mode: MatchSubstring, want: []string{"rollout", "api-resources", "port-forward"}},
+		{name: "substring", word: "forw", mode: MatchSubstring, want: []string{"port-forward"}},
+		{name: "fuzzy", word: "pf", mode: MatchFuzzy, want: []string{"port-forward"}},
+		{name: "fuzzy ranks consecutive characters first", word: "po", mode: MatchFuzzy, want: []string{"port-forward", "api-resources"}},
+		{name: "fuzzy ranks shorter values first", word: "ap", mode: MatchFuzzy, want: []string{"apply", "api-resources"}},
+		{name: "fuzzy no match", word: "xyz", mode: MatchFuzzy, want: []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, values(Match(suggestions, tt.word, tt.mode, false)))
+		})
+	}
+}
+
+func TestMatch_KeepOrder(t *testing.T) {
+	suggestions := []Suggestion{{Value: "zeta"}, {Value: "alpha-z"}, {Value: "beta"}, {Value: "z"}}
+
+	assert.Equal(t, []string{"zeta", "alpha-z", "z"}, values(Match(suggestions, "z", MatchSubstring, true)))
+	assert.Equal(t, []string{"z", "zeta", "alpha-z"}, values(Match(suggestions, "z", MatchSubstring, false)))
+}
+
+func TestMatch_SmartCase(t *testing.T) {
+	suggestions := []Suggestion{{Value: "ReadMe"}, {Value: "readme"}}
+
+	// Lower case words match any case, words with an upper case letter match exactly
+	assert.Equal(t, []string{"ReadMe", "readme"}, values(Match(suggestions, "rm", MatchFuzzy, false)))
+	assert.Equal(t, []string{"ReadMe"}, values(Match(suggestions, "RM", MatchFuzzy, false)))
+	assert.Equal(t, []string{"ReadMe"}, values(Match(suggestions, "dM", MatchSubstring, false)))
+}
+
+func TestFuzzyScore_Boundaries(t *testing.T) {
+	// Characters at word starts score higher than the same characters inside words
+	boundary, ok := fuzzyScore("get-pods", "gp")
+	assert.True(t, ok)
+	inner, ok := fuzzyScore("gxxxxpxx", "gp")
+	assert.True(t, ok)
+	assert.Greater(t, boundary, inner)
+
+	// camelCase changes are word starts
+	assert.True(t, isBoundary("getPods", 3))
+	assert.False(t, isBoundary("getpods", 3))
+}
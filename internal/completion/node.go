@@ -0,0 +1,34 @@
+package completion
+
+import (
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// NodeCommandTimeout is the timeout of completion commands of Node.js tools,
+// which load their dependencies before answering (nx, turbo wrappers, ...)
+const NodeCommandTimeout = 1 * time.Second
+
+// isNodeScript checks if a tool is a Node.js script, reading its shebang without running it
+func isNodeScript(tool string) bool {
+	_, interpreter, _ := inspectScript(tool)
+	name := filepath.Base(interpreter)
+	return name == "node" || name == "nodejs" || name == "bun" || name == "deno"
+}
+
+// validateWordList checks that the output is a non-empty list of single words,
+// which tells completion answers from help or usage messages
+func validateWordList(output []byte) bool {
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	if len(lines) == 0 || lines[0] == "" {
+		return false
+	}
+
+	for _, line := range lines {
+		if len(strings.Fields(line)) != 1 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,21 @@
+package completion
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsNodeScript(t *testing.T) {
+	assert.True(t, isNodeScript(writeMockTool(t, "mock-env", "#!/usr/bin/env node\nrequire('yargs')\n")))
+	assert.True(t, isNodeScript(writeMockTool(t, "mock-abs", "#!/usr/local/bin/node\n")))
+	assert.True(t, isNodeScript(writeMockTool(t, "mock-split", "#!/usr/bin/env -S node --no-warnings\n")))
+	assert.False(t, isNodeScript(writeMockTool(t, "mock-sh", "#!/bin/sh\necho node\n")))
+	assert.False(t, isNodeScript("nonexistent-command-xyz"))
+}
+
+func TestValidateWordList(t *testing.T) {
+	assert.True(t, validateWordList([]byte("build\ntest\n")))
+	assert.False(t, validateWordList([]byte("")))
+	assert.False(t, validateWordList([]byte("Usage: tool [command]\nbuild\n")))
+}
@@ -0,0 +1,132 @@
+package completion
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// oclifCacheReadSize is how much of an autocomplete cache file is read
+const oclifCacheReadSize = 1024 * 1024
+
+// OclifCompleter handles oclif-based CLIs (heroku, salesforce sf, ...) through the cache
+// of @oclif/plugin-autocomplete, written by '<cli> autocomplete'. The tool is never run.
+type OclifCompleter struct{}
+
+// NewOclifCompleter creates a new oclif completer
+func NewOclifCompleter() *OclifCompleter {
+	return &OclifCompleter{}
+}
+
+// Supports checks if an autocomplete cache exists for the tool
+func (o *OclifCompleter) Supports(tool string, _ []string) bool {
+	return oclifCachePath(tool) != ""
+}
+
+// Complete completes command IDs and flags from the autocomplete cache
+func (o *OclifCompleter) Complete(tool string, args []string) ([]Suggestion, error) {
+	path := oclifCachePath(tool)
+	if path == "" {
+		return nil, fmt.Errorf("no oclif autocomplete cache for %s", tool)
+	}
+
+	content, err := readFileHead(path, oclifCacheReadSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return completeOclifCommands(parseOclifCommands(content), args), nil
+}
+
+// oclifCachePath returns the bash functions file of the autocomplete cache of a tool,
+// or an empty string if the cache was not generated
+func oclifCachePath(tool string) string {
+	bin := filepath.Base(tool)
+	for _, cacheDir := range oclifCacheDirs(bin) {
+		path := filepath.Join(cacheDir, "autocomplete", "functions", "bash", bin+".bash")
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+// oclifCacheDirs returns the cache directories oclif may use for a CLI
+func oclifCacheDirs(bin string) []string {
+	var dirs []string
+	if dir := os.Getenv(strings.ToUpper(strings.ReplaceAll(bin, "-", "_")) + "_CACHE_DIR"); dir != "" {
+		dirs = append(dirs, dir)
+	}
+	if dir := os.Getenv("XDG_CACHE_HOME"); dir != "" {
+		dirs = append(dirs, filepath.Join(dir, bin))
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		if runtime.GOOS == "darwin" {
+			dirs = append(dirs, filepath.Join(home, "Library", "Caches", bin))
+		}
+		dirs = append(dirs, filepath.Join(home, ".cache", bin))
+	}
+	return dirs
+}
+
+// parseOclifCommands extracts the commands list of the bash autocomplete function
+// Format: 'local commands="' then one 'command:id --flag1 --flag2' line per command, then '"'
+func parseOclifCommands(content string) map[string][]string {
+	_, list, found := strings.Cut(content, `local commands="`)
+	if !found {
+		return nil
+	}
+	list, _, _ = strings.Cut(list, `"`)
+
+	commands := make(map[string][]string)
+	for _, line := range strings.Split(list, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		commands[fields[0]] = fields[1:]
+	}
+	return commands
+}
+
+// completeOclifCommands completes the last argument.
+// The previous words, without flags, form the command ID: its flags are suggested once it
+// is complete, otherwise the next topic is (for CLIs using spaces as topic separator).
+// The first word is completed with full command IDs.
+func completeOclifCommands(commands map[string][]string, args []string) []Suggestion {
+	var topics []string
+	for i := 0; i < len(args)-1; i++ {
+		if !strings.HasPrefix(args[i], "-") {
+			topics = append(topics, args[i])
+		}
+	}
+
+	seen := make(map[string]bool)
+	id := strings.Join(topics, ":")
+	if flags, ok := commands[id]; ok && id != "" {
+		for _, flag := range flags {
+			seen[flag] = true
+		}
+	} else {
+		for command := range commands {
+			if id == "" {
+				seen[command] = true
+			} else if rest, ok := strings.CutPrefix(command, id+":"); ok {
+				next, _, _ := strings.Cut(rest, ":")
+				seen[next] = true
+			}
+		}
+	}
+
+	suggestions := make([]Suggestion, 0, len(seen))
+	for value := range seen {
+		suggestions = append(suggestions, Suggestion{Value: value})
+	}
+	sort.Slice(suggestions, func(i, j int) bool {
+		return suggestions[i].Value < suggestions[j].Value
+	})
+	return suggestions
+}
@@ -0,0 +1,82 @@
+package completion
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const mockOclifCache = `#!/usr/bin/env bash
+
+_mockcli_autocomplete()
+{
+
+  local cur="${COMP_WORDS[COMP_CWORD]}" opts IFS=$' \t\n'
+  COMPREPLY=()
+
+  local commands="
+apps --all --json
+apps:create --region --team
+apps:destroy --confirm
+config:get --json
+config:set
+login --browser
+"
+}
+`
+
+func writeOclifCache(t *testing.T, bin string) {
+	t.Helper()
+	cacheHome := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", cacheHome)
+	dir := filepath.Join(cacheHome, bin, "autocomplete", "functions", "bash")
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, bin+".bash"), []byte(mockOclifCache), 0644))
+}
+
+func TestOclifCompleter_Supports(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	writeOclifCache(t, "mockcli")
+	c := NewOclifCompleter()
+
+	assert.True(t, c.Supports("/usr/local/bin/mockcli", nil))
+	assert.False(t, c.Supports("othercli", nil))
+}
+
+func TestOclifCompleter_Complete(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	writeOclifCache(t, "mockcli")
+	c := NewOclifCompleter()
+
+	suggestions, err := c.Complete("mockcli", []string{"ap"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"apps", "apps:create", "apps:destroy", "config:get", "config:set", "login"}, suggestionValues(suggestions))
+
+	suggestions, err = c.Complete("mockcli", []string{"apps:create", "--"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"--region", "--team"}, suggestionValues(suggestions))
+
+	// Flags of a command followed by flags
+	suggestions, err = c.Complete("mockcli", []string{"apps", "--json", "--a"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"--all", "--json"}, suggestionValues(suggestions))
+
+	_, err = c.Complete("othercli", nil)
+	assert.Error(t, err)
+}
+
+func TestCompleteOclifCommands_Topics(t *testing.T) {
+	commands := parseOclifCommands(mockOclifCache)
+
+	// CLIs using spaces as topic separator
+	assert.Equal(t, []string{"get", "set"}, suggestionValues(completeOclifCommands(commands, []string{"config", ""})))
+	assert.Equal(t, []string{"--json"}, suggestionValues(completeOclifCommands(commands, []string{"config", "get", "-"})))
+	assert.Empty(t, completeOclifCommands(commands, []string{"unknown", ""}))
+}
+
+func TestParseOclifCommands_NoList(t *testing.T) {
+	assert.Empty(t, parseOclifCommands("#!/usr/bin/env bash\n"))
+}
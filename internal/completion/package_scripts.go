@@ -0,0 +1,109 @@
+package completion
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// packageRunCommands lists the commands running a package.json script, by package manager
+var packageRunCommands = map[string][]string{
+	"npm":  {"run", "run-script", "rum", "urn"},
+	"pnpm": {"run"},
+	"yarn": {"run"},
+	"bun":  {"run"},
+}
+
+// PackageScriptsCompleter completes the script names of the nearest package.json
+// for 'npm run', 'pnpm run', 'yarn run' and 'bun run', and for the first word of 'yarn'.
+// It depends on the arguments: the engine tries it before detecting a completer for the tool.
+type PackageScriptsCompleter struct{}
+
+// NewPackageScriptsCompleter creates a new package.json scripts completer
+func NewPackageScriptsCompleter() *PackageScriptsCompleter {
+	return &PackageScriptsCompleter{}
+}
+
+// Supports checks if a script name is being completed and a package.json exists
+func (p *PackageScriptsCompleter) Supports(tool string, args []string) bool {
+	if !completesPackageScript(filepath.Base(tool), args) {
+		return false
+	}
+
+	return findPackageJSON() != ""
+}
+
+// Complete returns the scripts of the nearest package.json, described by their command
+func (p *PackageScriptsCompleter) Complete(_ string, _ []string) ([]Suggestion, error) {
+	path := findPackageJSON()
+	if path == "" {
+		return nil, fmt.Errorf("no package.json found")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var pkg struct {
+		Scripts map[string]string `json:"scripts"`
+	}
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	suggestions := make([]Suggestion, 0, len(pkg.Scripts))
+	for name, command := range pkg.Scripts {
+		suggestions = append(suggestions, Suggestion{Value: name, Description: command})
+	}
+	sort.Slice(suggestions, func(i, j int) bool {
+		return suggestions[i].Value < suggestions[j].Value
+	})
+	return suggestions, nil
+}
+
+// completesPackageScript checks if the last argument is a script name for the package manager
+func completesPackageScript(manager string, args []string) bool {
+	runCommands, ok := packageRunCommands[manager]
+	if !ok {
+		return false
+	}
+
+	// yarn runs scripts without 'run'
+	if manager == "yarn" && len(args) <= 1 {
+		return true
+	}
+
+	if len(args) != 2 {
+		return false
+	}
+	for _, command := range runCommands {
+		if args[0] == command {
+			return true
+		}
+	}
+	return false
+}
+
+// findPackageJSON returns the package.json of the current directory or its nearest parent
+func findPackageJSON() string {
+	dir, err := os.Getwd()
+	if err != nil {
+		return ""
+	}
+
+	for {
+		path := filepath.Join(dir, "package.json")
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
@@ -0,0 +1,73 @@
+package completion
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupPackageJSON(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "package.json"), []byte(`{
+  "name": "app",
+  "scripts": {
+    "test": "vitest",
+    "build": "tsc -p .",
+    "dev": "vite"
+  }
+}`), 0644))
+	return dir
+}
+
+func TestPackageScriptsCompleter_Supports(t *testing.T) {
+	dir := setupPackageJSON(t)
+	sub := filepath.Join(dir, "src", "components")
+	require.NoError(t, os.MkdirAll(sub, 0755))
+	t.Chdir(sub)
+	c := NewPackageScriptsCompleter()
+
+	assert.True(t, c.Supports("npm", []string{"run", ""}))
+	assert.True(t, c.Supports("/usr/bin/npm", []string{"run-script", "b"}))
+	assert.True(t, c.Supports("pnpm", []string{"run", "d"}))
+	assert.True(t, c.Supports("bun", []string{"run", ""}))
+	assert.True(t, c.Supports("yarn", []string{"run", ""}))
+	assert.True(t, c.Supports("yarn", []string{"b"}))
+	assert.True(t, c.Supports("yarn", nil))
+
+	assert.False(t, c.Supports("npm", []string{"install", ""}))
+	assert.False(t, c.Supports("npm", []string{"r"}))
+	assert.False(t, c.Supports("npm", []string{"run", "build", "--"}))
+	assert.False(t, c.Supports("pnpm", []string{"b"}))
+	assert.False(t, c.Supports("make", []string{"run", ""}))
+}
+
+func TestPackageScriptsCompleter_Supports_NoPackageJSON(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	assert.False(t, NewPackageScriptsCompleter().Supports("npm", []string{"run", ""}))
+}
+
+func TestPackageScriptsCompleter_Complete(t *testing.T) {
+	t.Chdir(setupPackageJSON(t))
+
+	suggestions, err := NewPackageScriptsCompleter().Complete("npm", []string{"run", ""})
+	require.NoError(t, err)
+	assert.Equal(t, []Suggestion{
+		{Value: "build", Description: "tsc -p ."},
+		{Value: "dev", Description: "vite"},
+		{Value: "test", Description: "vitest"},
+	}, suggestions)
+}
+
+func TestPackageScriptsCompleter_Complete_InvalidJSON(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "package.json"), []byte("{"), 0644))
+	t.Chdir(dir)
+
+	_, err := NewPackageScriptsCompleter().Complete("npm", []string{"run", ""})
+	assert.Error(t, err)
+}
@@ -0,0 +1,70 @@
+package completion
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// CompletePaths completes file system paths starting with prefix.
+// Directories are always suggested (with a trailing slash) so users can navigate into them.
+// Files are suggested unless dirsOnly is set, filtered by pattern (a glob on the base name) if not empty.
+// Hidden entries are only suggested when the prefix starts with a dot.
+func CompletePaths(prefix, pattern string, dirsOnly bool) []Suggestion {
+	dir, base := filepath.Split(prefix)
+
+	readDir := dir
+	if readDir == "" {
+		readDir = "."
+	}
+
+	entries, err := os.ReadDir(readDir)
+	if err != nil {
+		return nil
+	}
+
+	var suggestions []Suggestion
+	for _, entry := range entries {
+		name := entry.Name()
+		if !strings.HasPrefix(name, base) {
+			continue
+		}
+		if strings.HasPrefix(name, ".") && !strings.HasPrefix(base, ".") {
+			continue
+		}
+
+		if isDirEntry(readDir, entry) {
+			suggestions = append(suggestions, Suggestion{Value: dir + name + "/"})
+			continue
+		}
+
+		if dirsOnly {
+			continue
+		}
+		if pattern != "" {
+			if matched, _ := filepath.Match(pattern, name); !matched {
+				continue
+			}
+		}
+		suggestions = append(suggestions, Suggestion{Value: dir + name})
+	}
+
+	sort.Slice(suggestions, func(i, j int) bool {
+		return suggestions[i].Value < suggestions[j].Value
+	})
+
+	return suggestions
+}
+
+// isDirEntry checks if an entry is a directory, following symlinks
+func isDirEntry(dir string, entry os.DirEntry) bool {
+	if entry.IsDir() {
+		return true
+	}
+	if entry.Type()&os.ModeSymlink == 0 {
+		return false
+	}
+	info, err := os.Stat(filepath.Join(dir, entry.Name()))
+	return err == nil && info.IsDir()
+}
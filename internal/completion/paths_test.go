@@ -0,0 +1,60 @@
+package completion
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupPathsDir creates a directory tree for path completion tests and chdirs into it
+func setupPathsDir(t *testing.T) {
+	t.Helper()
+	tmpDir := t.TempDir()
+
+	require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, "charts", "api"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, ".hidden"), 0755))
+	for _, file := range []string{"values.yaml", "values.json", "config.yaml", ".env", "charts/api.yaml"} {
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, file), []byte("x"), 0644))
+	}
+	require.NoError(t, os.Symlink(filepath.Join(tmpDir, "charts"), filepath.Join(tmpDir, "link")))
+
+	t.Chdir(tmpDir)
+}
+
+func suggestionValues(suggestions []Suggestion) []string {
+	result := make([]string, len(suggestions))
+	for i, s := range suggestions {
+		result[i] = s.Value
+	}
+	return result
+}
+
+func TestCompletePaths_Files(t *testing.T) {
+	setupPathsDir(t)
+
+	assert.Equal(t, []string{"charts/", "config.yaml", "link/", "values.json", "values.yaml"}, suggestionValues(CompletePaths("", "", false)))
+	assert.Equal(t, []string{"values.json", "values.yaml"}, suggestionValues(CompletePaths("val", "", false)))
+	assert.Equal(t, []string{"charts/api.yaml", "charts/api/"}, suggestionValues(CompletePaths("charts/", "", false)))
+}
+
+func TestCompletePaths_Pattern(t *testing.T) {
+	setupPathsDir(t)
+
+	assert.Equal(t, []string{"charts/", "config.yaml", "link/", "values.yaml"}, suggestionValues(CompletePaths("", "*.yaml", false)))
+}
+
+func TestCompletePaths_DirsOnly(t *testing.T) {
+	setupPathsDir(t)
+
+	assert.Equal(t, []string{"charts/", "link/"}, suggestionValues(CompletePaths("", "", true)))
+	assert.Equal(t, []string{".hidden/"}, suggestionValues(CompletePaths(".h", "", true)))
+}
+
+func TestCompletePaths_MissingDir(t *testing.T) {
+	setupPathsDir(t)
+
+	assert.Empty(t, CompletePaths("missing/", "", false))
+}
@@ -0,0 +1,224 @@
+package completion
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// PluginPrefix is the prefix of the external completers found on PATH: dirvana-completer-<name>
+const PluginPrefix = "dirvana-completer-"
+
+// PluginTimeout is how long an external completer may take to answer by default
+const PluginTimeout = 1 * time.Second
+
+// PluginProtocolVersion is the version of the JSON protocol spoken with external completers
+const PluginProtocolVersion = 1
+
+// pluginSourcePrefix prefixes the name of external completers in the detection cache
+const pluginSourcePrefix = "Plugin:"
+
+// Requests sent to external completers
+const (
+	pluginRequestSupports = "supports"
+	pluginRequestComplete = "complete"
+)
+
+// PluginSource declares an external completer in the global config
+type PluginSource struct {
+	Name    string        // Name of the completer
+	Path    string        // Executable, dirvana-completer-<name> on PATH if empty
+	Timeout time.Duration // How long the completer may take to answer, PluginTimeout if 0
+}
+
+// pluginRequest is written as JSON on the standard input of an external completer
+type pluginRequest struct {
+	Version int      `json:"version"`
+	Request string   `json:"request"` // supports or complete
+	Tool    string   `json:"tool"`    // Name of the tool to complete
+	Path    string   `json:"path"`    // Tool as called, name or path
+	Args    []string `json:"args"`    // Arguments after the tool, the word being completed last
+}
+
+// pluginSupportsResponse is the answer of an external completer to a supports request
+type pluginSupportsResponse struct {
+	Supported bool `json:"supported"`
+}
+
+// pluginCompleteResponse is the answer of an external completer to a complete request
+type pluginCompleteResponse struct {
+	Suggestions []Suggestion `json:"suggestions"`
+	Directive   int          `json:"directive,omitempty"` // Cobra shell completion directive
+}
+
+// PluginCompleter runs an external completer: an executable reading a JSON request on its
+// standard input and writing a JSON response on its standard output, for internal CLIs with
+// their own completion mechanism
+type PluginCompleter struct {
+	name    string
+	path    string
+	timeout time.Duration
+}
+
+// NewPluginCompleter creates a completer running the external completer at path
+func NewPluginCompleter(name, path string, timeout time.Duration) *PluginCompleter {
+	if timeout <= 0 {
+		timeout = PluginTimeout
+	}
+	return &PluginCompleter{name: name, path: path, timeout: timeout}
+}
+
+// Name returns the name of the completer in the detection cache (Plugin:<name>)
+func (p *PluginCompleter) Name() string {
+	return pluginSourcePrefix + p.name
+}
+
+// Supports asks the external completer whether it completes the tool
+func (p *PluginCompleter) Supports(tool string, args []string) bool {
+	var response pluginSupportsResponse
+	if err := p.call(pluginRequestSupports, tool, args, &response); err != nil {
+		return false
+	}
+	return response.Supported
+}
+
+// Complete asks the external completer for the suggestions of the command line
+func (p *PluginCompleter) Complete(tool string, args []string) ([]Suggestion, error) {
+	suggestions, _, err := p.CompleteWithDirective(tool, args)
+	return suggestions, err
+}
+
+// CompleteWithDirective asks the external completer for the suggestions of the command line,
+// with the directive it gives
+func (p *PluginCompleter) CompleteWithDirective(tool string, args []string) ([]Suggestion, int, error) {
+	var response pluginCompleteResponse
+	if err := p.call(pluginRequestComplete, tool, args, &response); err != nil {
+		return nil, 0, err
+	}
+
+	suggestions := make([]Suggestion, 0, len(response.Suggestions))
+	for _, s := range response.Suggestions {
+		if s.Value != "" {
+			suggestions = append(suggestions, s)
+		}
+	}
+	return suggestions, response.Directive, nil
+}
+
+// call sends a request to the external completer and decodes its response
+func (p *PluginCompleter) call(request, tool string, args []string, response any) error {
+	input, err := json.Marshal(pluginRequest{
+		Version: PluginProtocolVersion,
+		Request: request,
+		Tool:    filepath.Base(tool),
+		Path:    tool,
+		Args:    args,
+	})
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), p.timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, p.path)
+	cmd.Stdin = bytes.NewReader(input)
+	out := &limitedBuffer{max: MaxOutputSize}
+	cmd.Stdout = out
+	cmd.WaitDelay = 100 * time.Millisecond
+
+	err = cmd.Run()
+	if ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("completer %s timeout after %v", p.name, p.timeout)
+	}
+	if out.truncated {
+		return fmt.Errorf("completer %s: output exceeds %d bytes", p.name, MaxOutputSize)
+	}
+	if err != nil {
+		return fmt.Errorf("completer %s failed: %w", p.name, err)
+	}
+
+	if err := json.Unmarshal(out.buf.Bytes(), response); err != nil {
+		return fmt.Errorf("completer %s: invalid response: %w", p.name, err)
+	}
+	return nil
+}
+
+// DiscoverPlugins returns the external completers declared in the global config, then the
+// executables named dirvana-completer-<name> on PATH. A name is only taken once: declared
+// completers shadow those on PATH, and earlier PATH directories shadow later ones.
+func DiscoverPlugins(declared []PluginSource) []*PluginCompleter {
+	var plugins []*PluginCompleter
+	seen := make(map[string]bool)
+
+	for _, source := range declared {
+		if seen[source.Name] {
+			continue
+		}
+		path := source.Path
+		if path == "" {
+			found, err := exec.LookPath(PluginPrefix + source.Name)
+			if err != nil {
+				continue
+			}
+			path = found
+		}
+		seen[source.Name] = true
+		plugins = append(plugins, NewPluginCompleter(source.Name, path, source.Timeout))
+	}
+
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			name, ok := strings.CutPrefix(entry.Name(), PluginPrefix)
+			if !ok || name == "" || seen[name] {
+				continue
+			}
+			path := filepath.Join(dir, entry.Name())
+			if !isExecutableFile(path) {
+				continue
+			}
+			seen[name] = true
+			plugins = append(plugins, NewPluginCompleter(name, path, 0))
+		}
+	}
+
+	return plugins
+}
+
+// findPlugin returns the external completer with a name, declared or on PATH
+func findPlugin(declared []PluginSource, name string) (*PluginCompleter, bool) {
+	for _, source := range declared {
+		if source.Name != name {
+			continue
+		}
+		if source.Path != "" {
+			return NewPluginCompleter(name, source.Path, source.Timeout), true
+		}
+		if path, err := exec.LookPath(PluginPrefix + name); err == nil {
+			return NewPluginCompleter(name, path, source.Timeout), true
+		}
+		return nil, false
+	}
+
+	path, err := exec.LookPath(PluginPrefix + name)
+	if err != nil {
+		return nil, false
+	}
+	return NewPluginCompleter(name, path, 0), true
+}
+
+// isExecutableFile reports whether path is a regular file (or a link to one) that can be executed
+func isExecutableFile(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.Mode().IsRegular() && info.Mode()&0111 != 0
+}
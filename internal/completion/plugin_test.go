@@ -0,0 +1,174 @@
+package completion
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testPlugin is an external completer for deployctl, recording its last request in $PLUGIN_LOG
+const testPlugin = `#!/bin/bash
+input=$(cat)
+[ -n "$PLUGIN_LOG" ] && printf '%s' "$input" > "$PLUGIN_LOG"
+case "$input" in
+  *'"request":"supports"'*'"tool":"deployctl"'*) echo '{"supported": true}' ;;
+  *'"request":"supports"'*) echo '{"supported": false}' ;;
+  *'"request":"complete"'*) echo '{"suggestions": [{"value": "staging"}, {"value": "production", "description": "Live"}, {"value": ""}], "directive": 36}' ;;
+esac
+`
+
+// writePlugin writes an external completer named dirvana-completer-<name> in dir
+func writePlugin(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, PluginPrefix+name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0755))
+	return path
+}
+
+func TestPluginCompleter(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "request.json")
+	t.Setenv("PLUGIN_LOG", logPath)
+	plugin := NewPluginCompleter("corp", writePlugin(t, t.TempDir(), "corp", testPlugin), 0)
+
+	assert.Equal(t, "Plugin:corp", plugin.Name())
+	assert.Equal(t, "Plugin:corp", getCompleterType(plugin))
+	assert.Equal(t, PluginTimeout, plugin.timeout)
+
+	assert.True(t, plugin.Supports("/usr/local/bin/deployctl", []string{""}))
+	assert.False(t, plugin.Supports("kubectl", []string{""}))
+
+	suggestions, directive, err := plugin.CompleteWithDirective("/usr/local/bin/deployctl", []string{"up", ""})
+	require.NoError(t, err)
+	assert.Equal(t, []Suggestion{{Value: "staging"}, {Value: "production", Description: "Live"}}, suggestions)
+	assert.Equal(t, ShellCompDirectiveNoFileComp|ShellCompDirectiveKeepOrder, directive)
+
+	// The request of the protocol
+	data, err := os.ReadFile(logPath)
+	require.NoError(t, err)
+	var request map[string]any
+	require.NoError(t, json.Unmarshal(data, &request))
+	assert.Equal(t, map[string]any{
+		"version": float64(PluginProtocolVersion),
+		"request": "complete",
+		"tool":    "deployctl",
+		"path":    "/usr/local/bin/deployctl",
+		"args":    []any{"up", ""},
+	}, request)
+}
+
+func TestPluginCompleter_Errors(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{name: "invalid response", content: "#!/bin/bash\necho not json\n", want: "invalid response"},
+		{name: "failure", content: "#!/bin/bash\nexit 3\n", want: "failed"},
+		{name: "timeout", content: "#!/bin/bash\nsleep 5\n", want: "timeout"},
+		{name: "output limit", content: "#!/bin/bash\nhead -c 2000000 /dev/zero\n", want: "output exceeds"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := NewPluginCompleter("broken", writePlugin(t, dir, strings.ReplaceAll(tt.name, " ", "-"), tt.content), 200*time.Millisecond)
+			_, err := plugin.Complete("deployctl", nil)
+			assert.ErrorContains(t, err, tt.want)
+			assert.False(t, plugin.Supports("deployctl", nil))
+		})
+	}
+}
+
+func TestDiscoverPlugins(t *testing.T) {
+	first, second, declaredDir := t.TempDir(), t.TempDir(), t.TempDir()
+	writePlugin(t, first, "corp", testPlugin)
+	writePlugin(t, second, "corp", testPlugin)
+	writePlugin(t, second, "team", testPlugin)
+	require.NoError(t, os.WriteFile(filepath.Join(second, PluginPrefix+"disabled"), []byte(testPlugin), 0644))
+	declared := writePlugin(t, declaredDir, "ops", testPlugin)
+	t.Setenv("PATH", first+string(os.PathListSeparator)+second)
+
+	plugins := DiscoverPlugins([]PluginSource{
+		{Name: "ops", Path: declared, Timeout: 2 * time.Second},
+		{Name: "team"},
+		{Name: "missing"},
+	})
+
+	paths := make(map[string]string)
+	var names []string
+	for _, plugin := range plugins {
+		names = append(names, plugin.name)
+		paths[plugin.name] = plugin.path
+	}
+	// Declared first, then PATH in order; not executable and missing ones are skipped
+	assert.Equal(t, []string{"ops", "team", "corp"}, names)
+	assert.Equal(t, declared, paths["ops"])
+	assert.Equal(t, filepath.Join(first, PluginPrefix+"corp"), paths["corp"])
+	assert.Equal(t, 2*time.Second, plugins[0].timeout)
+
+	plugin, ok := findPlugin(nil, "team")
+	require.True(t, ok)
+	assert.Equal(t, filepath.Join(second, PluginPrefix+"team"), plugin.path)
+	plugin, ok = findPlugin([]PluginSource{{Name: "ops", Path: declared}}, "ops")
+	require.True(t, ok)
+	assert.Equal(t, declared, plugin.path)
+	_, ok = findPlugin([]PluginSource{{Name: "missing"}}, "missing")
+	assert.False(t, ok)
+	_, ok = findPlugin(nil, "disabled")
+	assert.False(t, ok)
+}
+
+func TestEngine_Plugins(t *testing.T) {
+	binDir := t.TempDir()
+	writePlugin(t, binDir, "corp", testPlugin)
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	cacheDir := t.TempDir()
+	engine := NewEngine(cacheDir)
+	engine.completers = []Completer{}
+
+	// Detected in parallel with the built-in completers, and recorded in the detection cache
+	result, err := engine.Complete("deployctl", []string{""})
+	require.NoError(t, err)
+	assert.Equal(t, "Plugin:corp", result.Source)
+	assert.Len(t, result.Suggestions, 2)
+	assert.True(t, result.KeepOrder())
+
+	engine = NewEngine(cacheDir)
+	result, err = engine.Complete("deployctl", []string{""})
+	require.NoError(t, err)
+	assert.Equal(t, "Plugin:corp (cached)", result.Source)
+
+	// Declared plugins are configured with their path
+	declaredDir := t.TempDir()
+	declared := writePlugin(t, declaredDir, "ops", testPlugin)
+	engine = NewEngine(t.TempDir())
+	engine.completers = []Completer{}
+	engine.SetPlugins([]PluginSource{{Name: "ops", Path: declared}})
+	completer, ok := engine.completer("Plugin:ops")
+	require.True(t, ok)
+	assert.Equal(t, declared, completer.(*PluginCompleter).path)
+	_, ok = engine.completer("Plugin:unknown")
+	assert.False(t, ok)
+}
+
+func TestEngine_SlowPlugin(t *testing.T) {
+	binDir := t.TempDir()
+	writePlugin(t, binDir, "slow", "#!/bin/bash\nsleep 0.3\n"+strings.TrimPrefix(testPlugin, "#!/bin/bash\n"))
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	// Slower than the built-in completers, within its own timeout: always detected
+	for i := 0; i < 3; i++ {
+		engine := NewEngine(t.TempDir())
+		engine.completers = []Completer{}
+
+		result, err := engine.Complete("deployctl", []string{""})
+		require.NoError(t, err)
+		assert.Equal(t, "Plugin:slow", result.Source)
+	}
+}
@@ -0,0 +1,130 @@
+package completion
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	// PythonCommandTimeout is the timeout of completion commands of Python tools,
+	// which need more time than compiled tools to start the interpreter and import their modules
+	PythonCommandTimeout = 1 * time.Second
+	// pythonModuleReadSize is how much of an entry point module is read to look for markers
+	pythonModuleReadSize = 64 * 1024
+)
+
+// entryPointImport matches the import of console_scripts wrappers generated by pip, uv or pipx
+// (e.g. 'from ansible.cli.adhoc import main')
+var entryPointImport = regexp.MustCompile(`(?m)^from\s+([A-Za-z_][\w.]*)\s+import\s+\w+`)
+
+// entryPointCall matches the call of the entry point in console_scripts wrappers (e.g. 'sys.exit(main())')
+var entryPointCall = regexp.MustCompile(`(?m)^\s*sys\.exit\(\w+\(\)\)`)
+
+// pythonScript is a Python executable found in PATH, inspected without running it
+type pythonScript struct {
+	path        string // Path of the executable
+	interpreter string // Python interpreter from the shebang
+	head        string // Beginning of the executable
+}
+
+// inspectPythonScript reads the beginning of a tool's executable.
+// Returns nil if the tool is not found or is not a Python script.
+func inspectPythonScript(tool string) *pythonScript {
+	path, interpreter, head := inspectScript(tool)
+	if !strings.HasPrefix(filepath.Base(interpreter), "python") {
+		return nil
+	}
+
+	return &pythonScript{path: path, interpreter: interpreter, head: head}
+}
+
+// sourceContains reports whether the script, or the module of its console_scripts
+// entry point, contains one of the markers
+func (s *pythonScript) sourceContains(markers ...string) bool {
+	if containsAny(s.head, markers) {
+		return true
+	}
+
+	match := entryPointImport.FindStringSubmatch(s.head)
+	if match == nil {
+		return false
+	}
+
+	for _, moduleFile := range s.moduleFiles(match[1]) {
+		content, err := readFileHead(moduleFile, pythonModuleReadSize)
+		if err == nil {
+			return containsAny(content, markers)
+		}
+	}
+
+	return false
+}
+
+// isConsoleScript reports whether the script is a console_scripts wrapper, which only
+// imports the entry point of a package and calls it
+func (s *pythonScript) isConsoleScript() bool {
+	return entryPointImport.MatchString(s.head) && entryPointCall.MatchString(s.head)
+}
+
+// packageVersion returns the version of a package installed for the interpreter, read from
+// its metadata directory (<name>-<version>.dist-info or .egg-info), or "" if it is not found
+func (s *pythonScript) packageVersion(name string) string {
+	for _, dir := range s.sitePackagesDirs() {
+		for _, suffix := range []string{".dist-info", ".egg-info"} {
+			matches, _ := filepath.Glob(filepath.Join(dir, name+"-*"+suffix))
+			if len(matches) > 0 {
+				return strings.TrimSuffix(strings.TrimPrefix(filepath.Base(matches[0]), name+"-"), suffix)
+			}
+		}
+	}
+	return ""
+}
+
+// moduleFiles returns the candidate source files of a module in the site-packages of the interpreter
+func (s *pythonScript) moduleFiles(module string) []string {
+	relPath := filepath.Join(strings.Split(module, ".")...)
+
+	var files []string
+	for _, dir := range s.sitePackagesDirs() {
+		files = append(files,
+			filepath.Join(dir, relPath+".py"),
+			filepath.Join(dir, relPath, "__init__.py"),
+		)
+	}
+	return files
+}
+
+// sitePackagesDirs returns the directories where the interpreter may find installed packages:
+// the virtualenv or prefix of the interpreter, then the user's site-packages
+func (s *pythonScript) sitePackagesDirs() []string {
+	interpreter := s.interpreter
+	if !filepath.IsAbs(interpreter) {
+		if path, err := exec.LookPath(interpreter); err == nil {
+			interpreter = path
+		}
+	}
+
+	// <prefix>/bin/python -> <prefix>
+	prefixes := []string{filepath.Dir(filepath.Dir(interpreter))}
+	if home, err := os.UserHomeDir(); err == nil {
+		prefixes = append(prefixes, filepath.Join(home, ".local"))
+	}
+
+	var dirs []string
+	for _, prefix := range prefixes {
+		for _, pattern := range []string{
+			"lib/python3*/site-packages",
+			"lib/python3*/dist-packages",
+			"lib/python3/dist-packages",
+			"local/lib/python3*/dist-packages",
+		} {
+			matches, _ := filepath.Glob(filepath.Join(prefix, pattern))
+			dirs = append(dirs, matches...)
+		}
+	}
+	return dirs
+}
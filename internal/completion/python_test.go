@@ -0,0 +1,80 @@
+package completion
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInspectPythonScript(t *testing.T) {
+	python := writeMockTool(t, "pytool", "#!/usr/bin/python3\nprint('hi')\n")
+	script := inspectPythonScript(python)
+	require.NotNil(t, script)
+	assert.Equal(t, "/usr/bin/python3", script.interpreter)
+
+	envPython := writeMockTool(t, "envtool", "#!/usr/bin/env python3\nprint('hi')\n")
+	script = inspectPythonScript(envPython)
+	require.NotNil(t, script)
+	assert.Equal(t, "python3", script.interpreter)
+
+	assert.Nil(t, inspectPythonScript(writeMockTool(t, "shtool", "#!/bin/bash\necho hi\n")))
+	assert.Nil(t, inspectPythonScript(writeMockTool(t, "binary", "\x7fELF")))
+	assert.Nil(t, inspectPythonScript("dirvana-nonexistent-tool-xyz"))
+}
+
+func TestPythonScript_SourceContains(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	script := inspectPythonScript(writeMockTool(t, "marked", "#!/usr/bin/python3\n# PYTHON_ARGCOMPLETE_OK\n"))
+	require.NotNil(t, script)
+	assert.True(t, script.sourceContains(argcompleteMarker))
+	assert.False(t, script.sourceContains("import click"))
+}
+
+func TestPythonScript_SourceContains_EntryPoint(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	// Virtualenv with a console_scripts wrapper, like pip generates
+	venv := t.TempDir()
+	sitePackages := filepath.Join(venv, "lib", "python3.12", "site-packages")
+	require.NoError(t, os.MkdirAll(filepath.Join(sitePackages, "mytool"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(sitePackages, "clicktool"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(sitePackages, "mytool", "cli.py"), []byte("# PYTHON_ARGCOMPLETE_OK\nimport argparse\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(sitePackages, "clicktool", "__init__.py"), []byte("import click\n"), 0644))
+
+	wrapper := func(module string) string {
+		return "#!" + filepath.Join(venv, "bin", "python") + "\n# -*- coding: utf-8 -*-\nimport re\nimport sys\nfrom " + module + " import main\n" +
+			"if __name__ == \"__main__\":\n    sys.exit(main())\n"
+	}
+
+	script := inspectPythonScript(writeMockTool(t, "mytool", wrapper("mytool.cli")))
+	require.NotNil(t, script)
+	assert.True(t, script.sourceContains(argcompleteMarker))
+	assert.False(t, script.sourceContains("import click"))
+
+	script = inspectPythonScript(writeMockTool(t, "clicktool", wrapper("clicktool")))
+	require.NotNil(t, script)
+	assert.True(t, script.sourceContains("import click"))
+
+	script = inspectPythonScript(writeMockTool(t, "missing", wrapper("missing.module")))
+	require.NotNil(t, script)
+	assert.False(t, script.sourceContains(argcompleteMarker))
+}
+
+func TestPythonScript_PackageVersion(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	venv := t.TempDir()
+	sitePackages := filepath.Join(venv, "lib", "python3.11", "site-packages")
+	require.NoError(t, os.MkdirAll(filepath.Join(sitePackages, "click-8.1.7.dist-info"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(sitePackages, "argcomplete-3.1.2.egg-info"), 0755))
+
+	script := inspectPythonScript(writeMockTool(t, "pytool", "#!"+filepath.Join(venv, "bin", "python")+"\n"))
+	require.NotNil(t, script)
+	assert.Equal(t, "8.1.7", script.packageVersion("click"))
+	assert.Equal(t, "3.1.2", script.packageVersion("argcomplete"))
+	assert.Equal(t, "", script.packageVersion("typer"))
+}
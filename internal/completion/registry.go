@@ -9,6 +9,7 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 
@@ -41,7 +42,7 @@ var httpClient = http.DefaultClient
 var registryMemCache struct {
 	mu        sync.RWMutex
 	config    *RegistryConfig
-	cacheDir  string
+	key       string // Cache directory and registries the config was loaded from
 	expiresAt time.Time
 }
 
@@ -50,7 +51,7 @@ func clearRegistryCache() {
 	registryMemCache.mu.Lock()
 	defer registryMemCache.mu.Unlock()
 	registryMemCache.config = nil
-	registryMemCache.cacheDir = ""
+	registryMemCache.key = ""
 	registryMemCache.expiresAt = time.Time{}
 }
 
@@ -65,7 +66,25 @@ type RegistryConfig struct {
 type RegistryTool struct {
 	Description string         `yaml:"description"`
 	Homepage    string         `yaml:"homepage"`
-	Script      RegistryScript `yaml:"script"` // Single bash completion script
+	Script      RegistryScript `yaml:"script"`         // Bash completion script, used by all shells
+	Zsh         RegistryScript `yaml:"zsh,omitempty"`  // Native zsh completion function (#compdef), optional
+	Fish        RegistryScript `yaml:"fish,omitempty"` // Native fish completions, optional
+	Registry    string         `yaml:"-"`              // Name of the registry the tool comes from
+
+	local bool // Comes from a file registry: file:// script URLs are allowed
+}
+
+// ScriptFor returns the completion script of the tool for a shell: the native script for zsh
+// and fish, empty if the tool has none, and the bash script otherwise
+func (t RegistryTool) ScriptFor(shell string) RegistryScript {
+	switch shell {
+	case "zsh":
+		return t.Zsh
+	case "fish":
+		return t.Fish
+	default:
+		return t.Script
+	}
 }
 
 // RegistryScript represents a completion script
@@ -85,9 +104,9 @@ func validateURL(rawURL string) error {
 		return fmt.Errorf("invalid URL: %w", err)
 	}
 
-	// Must be HTTPS for security
-	if u.Scheme != "https" && u.Scheme != "http" {
-		return fmt.Errorf("URL must use HTTP or HTTPS scheme, got: %s", u.Scheme)
+	// Must be HTTPS for security: downloaded scripts are sourced in bash
+	if u.Scheme != "https" {
+		return fmt.Errorf("URL must use HTTPS scheme, got: %s", u.Scheme)
 	}
 
 	// Must have a host
@@ -142,9 +161,9 @@ func getRegistryHashPath(cacheDir, version string) string {
 	return filepath.Join(cacheDir, fmt.Sprintf("completion-registry-%s.hash", version))
 }
 
-// downloadRegistry downloads the registry from GitHub
-func downloadRegistry(version string) ([]byte, error) {
-	downloadURL := fmt.Sprintf("%s/%s/completion-scripts.yml", RegistryBaseURL, version)
+// downloadRegistry downloads the registry located at baseURL
+func downloadRegistry(baseURL, version string) ([]byte, error) {
+	downloadURL := fmt.Sprintf("%s/%s/completion-scripts.yml", strings.TrimSuffix(baseURL, "/"), version)
 
 	// Validate URL
 	if err := validateURL(downloadURL); err != nil {
@@ -185,65 +204,19 @@ func getLocalRegistryPath(version string) string {
 	return ""
 }
 
-// LoadRegistry loads the registry, using cache if valid
+// LoadRegistry loads the default registry, using cache if valid
 // Dev builds: Use local registry/ by default (override with DIRVANA_REGISTRY_MODE=remote)
 // Prod builds: Always use remote registry (no local fallback)
 func LoadRegistry(cacheDir string) (*RegistryConfig, error) {
-	// Check in-memory cache first
-	registryMemCache.mu.RLock()
-	if registryMemCache.config != nil &&
-		registryMemCache.cacheDir == cacheDir &&
-		time.Now().Before(registryMemCache.expiresAt) {
-		config := registryMemCache.config
-		registryMemCache.mu.RUnlock()
-		return config, nil
-	}
-	registryMemCache.mu.RUnlock()
-
-	version := DefaultRegistryVersion
-
-	// Try to load from local registry (dev mode)
-	if config, ok := tryLoadLocalRegistry(version); ok {
-		// Update memory cache
-		updateMemoryCache(cacheDir, config)
-		return config, nil
-	}
-
-	// Try to load from cache
-	registryPath := getRegistryPath(cacheDir, version)
-	if config, ok := tryLoadCachedRegistry(registryPath); ok {
-		// Update memory cache
-		updateMemoryCache(cacheDir, config)
-		return config, nil
-	}
-
-	// Download fresh registry
-	data, err := downloadRegistry(version)
-	if err != nil {
-		// If download fails, try to use expired cache
-		if config, ok := tryLoadExpiredCache(registryPath); ok {
-			// Update memory cache
-			updateMemoryCache(cacheDir, config)
-			return config, nil
-		}
-		return nil, err
-	}
-
-	// Save to cache and parse
-	config, err := saveCachedRegistry(cacheDir, version, data)
-	if err == nil {
-		// Update memory cache
-		updateMemoryCache(cacheDir, config)
-	}
-	return config, err
+	return LoadRegistries(cacheDir, []RegistrySource{DefaultRegistrySource()})
 }
 
 // updateMemoryCache updates the in-memory registry cache
-func updateMemoryCache(cacheDir string, config *RegistryConfig) {
+func updateMemoryCache(key string, config *RegistryConfig) {
 	registryMemCache.mu.Lock()
 	defer registryMemCache.mu.Unlock()
 	registryMemCache.config = config
-	registryMemCache.cacheDir = cacheDir
+	registryMemCache.key = key
 	registryMemCache.expiresAt = time.Now().Add(RegistryCacheTTL)
 }
 
@@ -282,7 +255,7 @@ func tryLoadLocalRegistry(version string) (*RegistryConfig, bool) {
 }
 
 // tryLoadCachedRegistry attempts to load valid (non-expired) cache
-func tryLoadCachedRegistry(registryPath string) (*RegistryConfig, bool) {
+func tryLoadCachedRegistry(registryPath string, ttl time.Duration) (*RegistryConfig, bool) {
 	data, err := os.ReadFile(registryPath)
 	if err != nil {
 		return nil, false
@@ -290,7 +263,7 @@ func tryLoadCachedRegistry(registryPath string) (*RegistryConfig, bool) {
 
 	// Check if cache is still valid (based on TTL)
 	info, err := os.Stat(registryPath)
-	if err != nil || time.Since(info.ModTime()) >= RegistryTTL {
+	if err != nil || time.Since(info.ModTime()) >= ttl {
 		return nil, false
 	}
 
@@ -320,9 +293,11 @@ func tryLoadExpiredCache(registryPath string) (*RegistryConfig, bool) {
 
 // saveCachedRegistry saves registry data to cache and parses it
 func saveCachedRegistry(cacheDir, version string, data []byte) (*RegistryConfig, error) {
-	registryPath := getRegistryPath(cacheDir, version)
-	hashPath := getRegistryHashPath(cacheDir, version)
+	return saveRegistryFile(getRegistryPath(cacheDir, version), getRegistryHashPath(cacheDir, version), data)
+}
 
+// saveRegistryFile saves registry data and its hash to the given paths and parses it
+func saveRegistryFile(registryPath, hashPath string, data []byte) (*RegistryConfig, error) {
 	// Compute and store hash
 	hash := computeHash(data)
 
@@ -348,6 +323,40 @@ func saveCachedRegistry(cacheDir, version string, data []byte) (*RegistryConfig,
 	return &config, nil
 }
 
+// fetchRegistryScript downloads a completion script of a registry tool, or reads it for file
+// registries, and verifies its checksum when the registry gives one. It returns the script and its hash.
+func fetchRegistryScript(tool string, toolInfo RegistryTool, scriptInfo RegistryScript) ([]byte, string, error) {
+	var data []byte
+	if path, ok := localScriptPath(scriptInfo.URL); ok && toolInfo.local {
+		// Scripts of file registries are read from the filesystem
+		script, err := readFileWithSizeLimit(path, MaxScriptSize)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to read script for %s: %w", tool, err)
+		}
+		data = script
+	} else {
+		// Validate URL
+		if err := validateURL(scriptInfo.URL); err != nil {
+			return nil, "", fmt.Errorf("invalid script URL for %s: %w", tool, err)
+		}
+
+		// Download script with size limit
+		script, err := downloadWithSizeLimit(scriptInfo.URL, MaxScriptSize)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to download script for %s: %w", tool, err)
+		}
+		data = script
+	}
+
+	// Verify checksum if provided
+	hash := computeHash(data)
+	if scriptInfo.SHA256 != "" && hash != scriptInfo.SHA256 {
+		return nil, "", fmt.Errorf("checksum mismatch for %s: expected %s, got %s", tool, scriptInfo.SHA256, hash)
+	}
+
+	return data, hash, nil
+}
+
 // GetCompletionScriptPath returns the cache path for a completion script
 func GetCompletionScriptPath(cacheDir, tool, shell string) string {
 	return filepath.Join(cacheDir, "completion-scripts", shell, tool)
@@ -358,39 +367,64 @@ func GetCompletionScriptPath(cacheDir, tool, shell string) string {
 //
 //nolint:revive // shell parameter kept for API compatibility
 func DownloadCompletionScript(cacheDir, tool, shell string, registry *RegistryConfig) error {
+	return InstallCompletionScript(cacheDir, tool, registry, false)
+}
+
+// InstallCompletionScript downloads the completion script of a tool from the registry and records
+// its hash in the script lockfile. In strict mode, scripts without a checksum in the registry are refused.
+func InstallCompletionScript(cacheDir, tool string, registry *RegistryConfig, strict bool) error {
+	return InstallShellScript(cacheDir, tool, "bash", registry, strict)
+}
+
+// InstallShellScript downloads the completion script of a tool for a shell (bash, or the native
+// zsh and fish scripts) and records its hash in the script lockfile.
+// In strict mode, scripts without a checksum in the registry are refused.
+func InstallShellScript(cacheDir, tool, shell string, registry *RegistryConfig, strict bool) error {
 	// Check if tool is in registry
 	toolInfo, ok := registry.Tools[tool]
 	if !ok {
 		return fmt.Errorf("tool %s not found in registry", tool)
 	}
 
-	// Get the bash completion script
-	if toolInfo.Script.URL == "" {
-		return fmt.Errorf("no completion script available for %s", tool)
+	scriptInfo := toolInfo.ScriptFor(shell)
+	if scriptInfo.URL == "" {
+		return fmt.Errorf("no %s completion script available for %s", shell, tool)
 	}
-	scriptInfo := toolInfo.Script
-
-	// Validate URL
-	if err := validateURL(scriptInfo.URL); err != nil {
-		return fmt.Errorf("invalid script URL for %s: %w", tool, err)
+	if strict && scriptInfo.SHA256 == "" {
+		return fmt.Errorf("no checksum for the %s completion script of %s (required in strict mode)", shell, tool)
 	}
 
-	// Download script with size limit
-	data, err := downloadWithSizeLimit(scriptInfo.URL, MaxScriptSize)
+	data, hash, err := fetchRegistryScript(tool, toolInfo, scriptInfo)
 	if err != nil {
-		return fmt.Errorf("failed to download script for %s: %w", tool, err)
+		return err
 	}
 
-	// Verify checksum if provided
-	if scriptInfo.SHA256 != "" {
-		hash := computeHash(data)
-		if hash != scriptInfo.SHA256 {
-			return fmt.Errorf("checksum mismatch for %s: expected %s, got %s", tool, scriptInfo.SHA256, hash)
-		}
+	if err := writeCompletionScript(cacheDir, tool, shell, data); err != nil {
+		return err
 	}
 
-	// Save script (always to bash location, regardless of shell parameter)
-	scriptPath := GetCompletionScriptPath(cacheDir, tool, "bash")
+	// Record the hash, to detect later changes of the script on disk
+	lock, err := LoadScriptLock(cacheDir)
+	if err != nil {
+		return fmt.Errorf("failed to load script lockfile: %w", err)
+	}
+	lock.Record(scriptLockKey(tool, shell), ScriptLockEntry{
+		SHA256:      hash,
+		URL:         scriptInfo.URL,
+		Registry:    toolInfo.Registry,
+		Verified:    scriptInfo.SHA256 != "",
+		InstalledAt: time.Now(),
+	})
+	if err := lock.Save(); err != nil {
+		return fmt.Errorf("failed to save script lockfile: %w", err)
+	}
+
+	return nil
+}
+
+// writeCompletionScript saves the completion script of a tool for a shell in the cache directory
+func writeCompletionScript(cacheDir, tool, shell string, data []byte) error {
+	scriptPath := GetCompletionScriptPath(cacheDir, tool, shell)
 	if err := os.MkdirAll(filepath.Dir(scriptPath), 0755); err != nil {
 		return fmt.Errorf("failed to create script dir: %w", err)
 	}
@@ -398,6 +432,5 @@ func DownloadCompletionScript(cacheDir, tool, shell string, registry *RegistryCo
 	if err := os.WriteFile(scriptPath, data, 0644); err != nil {
 		return fmt.Errorf("failed to save script: %w", err)
 	}
-
 	return nil
 }
@@ -0,0 +1,322 @@
+package completion
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultRegistryName is the name of the built-in registry on GitHub
+const DefaultRegistryName = "default"
+
+// RegistrySource is the location of a completion registry.
+// Registries are listed by precedence: a tool found in several registries is taken from the first one.
+type RegistrySource struct {
+	Name string        // Name of the registry, used for its cache file
+	URL  string        // https:// URL, file:// URL or local directory containing <version>/completion-scripts.yml
+	TTL  time.Duration // How long a downloaded registry is cached, RegistryTTL if 0
+}
+
+// RegistryState describes a registry and its local copy
+type RegistryState struct {
+	Source    RegistrySource
+	Path      string    // Registry file read, or cached copy of a remote registry
+	UpdatedAt time.Time // When the registry file was written (downloaded for remote registries)
+	Tools     int       // Number of tools of the registry
+	Err       error     // Why the registry could not be loaded
+}
+
+// DefaultRegistrySource returns the built-in registry
+func DefaultRegistrySource() RegistrySource {
+	return RegistrySource{Name: DefaultRegistryName, URL: RegistryBaseURL, TTL: RegistryTTL}
+}
+
+// IsLocal returns true if the registry is read from the filesystem instead of downloaded
+func (s RegistrySource) IsLocal() bool {
+	return !strings.HasPrefix(s.URL, "https://") && !strings.HasPrefix(s.URL, "http://")
+}
+
+// ttl returns how long a downloaded copy of the registry is valid
+func (s RegistrySource) ttl() time.Duration {
+	if s.TTL > 0 {
+		return s.TTL
+	}
+	return RegistryTTL
+}
+
+// localFile returns the registry file of a file registry
+func (s RegistrySource) localFile(version string) string {
+	dir := s.URL
+	if path, ok := localScriptPath(s.URL); ok {
+		dir = path
+	}
+	return filepath.Join(dir, version, "completion-scripts.yml")
+}
+
+// cachePath returns where the downloaded registry is cached.
+// The default registry keeps the cache file it always had.
+func (s RegistrySource) cachePath(cacheDir, version string) string {
+	if s.Name == DefaultRegistryName {
+		return getRegistryPath(cacheDir, version)
+	}
+	return filepath.Join(cacheDir, fmt.Sprintf("completion-registry-%s-%s.yml", s.Name, version))
+}
+
+// hashPath returns where the hash of the downloaded registry is stored
+func (s RegistrySource) hashPath(cacheDir, version string) string {
+	if s.Name == DefaultRegistryName {
+		return getRegistryHashPath(cacheDir, version)
+	}
+	return filepath.Join(cacheDir, fmt.Sprintf("completion-registry-%s-%s.hash", s.Name, version))
+}
+
+// path returns the registry file read for the source: the registry itself or its cached copy
+func (s RegistrySource) path(cacheDir, version string) string {
+	if s.IsLocal() {
+		return s.localFile(version)
+	}
+	return s.cachePath(cacheDir, version)
+}
+
+// registriesKey identifies a cache directory and a list of registries in the memory cache
+func registriesKey(cacheDir string, sources []RegistrySource) string {
+	var b strings.Builder
+	b.WriteString(cacheDir)
+	for _, source := range sources {
+		fmt.Fprintf(&b, "\x1f%s=%s@%s", source.Name, source.URL, source.TTL)
+	}
+	return b.String()
+}
+
+// LoadRegistries loads the registries and merges them by precedence, using caches if valid.
+// Registries that cannot be loaded are skipped; an error is returned if none could be loaded.
+func LoadRegistries(cacheDir string, sources []RegistrySource) (*RegistryConfig, error) {
+	key := registriesKey(cacheDir, sources)
+
+	// Check in-memory cache first
+	registryMemCache.mu.RLock()
+	if registryMemCache.config != nil &&
+		registryMemCache.key == key &&
+		time.Now().Before(registryMemCache.expiresAt) {
+		config := registryMemCache.config
+		registryMemCache.mu.RUnlock()
+		return config, nil
+	}
+	registryMemCache.mu.RUnlock()
+
+	configs := make([]*RegistryConfig, 0, len(sources))
+	var errs []error
+	for _, source := range sources {
+		config, err := loadRegistrySource(cacheDir, source, false)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("registry %s: %w", source.Name, err))
+			continue
+		}
+		configs = append(configs, config)
+	}
+	if len(configs) == 0 {
+		if len(errs) == 0 {
+			return nil, fmt.Errorf("no completion registry configured")
+		}
+		return nil, errors.Join(errs...)
+	}
+
+	config := mergeRegistries(configs)
+	updateMemoryCache(key, config)
+	return config, nil
+}
+
+// mergeRegistries merges registries listed by precedence: the first definition of a tool wins
+func mergeRegistries(configs []*RegistryConfig) *RegistryConfig {
+	if len(configs) == 1 {
+		return configs[0]
+	}
+
+	merged := &RegistryConfig{
+		Version:     configs[0].Version,
+		Description: configs[0].Description,
+		Tools:       make(map[string]RegistryTool),
+	}
+	for _, config := range configs {
+		for name, tool := range config.Tools {
+			if _, exists := merged.Tools[name]; !exists {
+				merged.Tools[name] = tool
+			}
+		}
+	}
+	return merged
+}
+
+// loadRegistrySource loads a single registry. File registries are read directly; remote registries
+// are downloaded when their cached copy is expired, or always if refresh is true.
+func loadRegistrySource(cacheDir string, source RegistrySource, refresh bool) (*RegistryConfig, error) {
+	version := DefaultRegistryVersion
+
+	var config *RegistryConfig
+	switch {
+	case source.Name == DefaultRegistryName && source.URL == RegistryBaseURL:
+		// Try to load from local registry (dev mode)
+		if local, ok := tryLoadLocalRegistry(version); ok {
+			config = local
+			break
+		}
+		fallthrough
+	case !source.IsLocal():
+		remote, err := loadRemoteRegistry(cacheDir, source, version, refresh)
+		if err != nil {
+			return nil, err
+		}
+		config = remote
+	default:
+		local, err := loadFileRegistry(source.localFile(version))
+		if err != nil {
+			return nil, err
+		}
+		config = local
+	}
+
+	for name, tool := range config.Tools {
+		tool.Registry = source.Name
+		config.Tools[name] = tool
+	}
+	return config, nil
+}
+
+// loadRemoteRegistry loads a registry from its cached copy, or downloads it
+func loadRemoteRegistry(cacheDir string, source RegistrySource, version string, refresh bool) (*RegistryConfig, error) {
+	registryPath := source.cachePath(cacheDir, version)
+	if !refresh {
+		if config, ok := tryLoadCachedRegistry(registryPath, source.ttl()); ok {
+			return config, nil
+		}
+	}
+
+	// Download fresh registry
+	data, err := downloadRegistry(source.URL, version)
+	if err != nil {
+		// If download fails, try to use expired cache
+		if !refresh {
+			if config, ok := tryLoadExpiredCache(registryPath); ok {
+				return config, nil
+			}
+		}
+		return nil, err
+	}
+
+	return saveRegistryFile(registryPath, source.hashPath(cacheDir, version), data)
+}
+
+// loadFileRegistry reads a registry from the filesystem.
+// Relative script paths are resolved against the directory of the registry file.
+func loadFileRegistry(registryPath string) (*RegistryConfig, error) {
+	data, err := readFileWithSizeLimit(registryPath, MaxRegistrySize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read registry: %w", err)
+	}
+
+	var config RegistryConfig
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse registry: %w", err)
+	}
+
+	for name, tool := range config.Tools {
+		for _, script := range []*RegistryScript{&tool.Script, &tool.Zsh, &tool.Fish} {
+			if script.URL != "" && !strings.Contains(script.URL, "://") && !filepath.IsAbs(script.URL) {
+				script.URL = filepath.Join(filepath.Dir(registryPath), script.URL)
+			}
+		}
+		tool.local = true
+		config.Tools[name] = tool
+	}
+	return &config, nil
+}
+
+// localScriptPath returns the filesystem path of a file:// URL or of a path
+func localScriptPath(rawURL string) (string, bool) {
+	if strings.HasPrefix(rawURL, "file://") {
+		u, err := url.Parse(rawURL)
+		if err != nil || u.Path == "" {
+			return "", false
+		}
+		return u.Path, true
+	}
+	if !strings.Contains(rawURL, "://") {
+		return rawURL, rawURL != ""
+	}
+	return "", false
+}
+
+// readFileWithSizeLimit reads a file, failing if it is larger than maxSize
+func readFileWithSizeLimit(path string, maxSize int64) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	data, err := io.ReadAll(io.LimitReader(f, maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > maxSize {
+		return nil, fmt.Errorf("content too large: exceeds %d bytes", maxSize)
+	}
+	return data, nil
+}
+
+// RegistryStates returns the state of the local copy of each registry, without downloading anything
+func RegistryStates(cacheDir string, sources []RegistrySource) []RegistryState {
+	states := make([]RegistryState, 0, len(sources))
+	for _, source := range sources {
+		state := RegistryState{Source: source, Path: source.path(cacheDir, DefaultRegistryVersion)}
+		if info, err := os.Stat(state.Path); err == nil {
+			state.UpdatedAt = info.ModTime()
+		}
+
+		var config *RegistryConfig
+		var err error
+		if source.IsLocal() {
+			config, err = loadFileRegistry(state.Path)
+		} else if cached, ok := tryLoadExpiredCache(state.Path); ok {
+			config = cached
+		} else {
+			err = fmt.Errorf("not downloaded yet")
+		}
+		if err != nil {
+			state.Err = err
+		} else {
+			state.Tools = len(config.Tools)
+		}
+		states = append(states, state)
+	}
+	return states
+}
+
+// UpdateRegistries downloads all remote registries again, checks file registries,
+// and returns their new state
+func UpdateRegistries(cacheDir string, sources []RegistrySource) []RegistryState {
+	clearRegistryCache()
+
+	states := make([]RegistryState, 0, len(sources))
+	for _, source := range sources {
+		state := RegistryState{Source: source, Path: source.path(cacheDir, DefaultRegistryVersion)}
+		config, err := loadRegistrySource(cacheDir, source, true)
+		if err != nil {
+			state.Err = err
+		} else {
+			state.Tools = len(config.Tools)
+		}
+		if info, err := os.Stat(state.Path); err == nil {
+			state.UpdatedAt = info.ModTime()
+		}
+		states = append(states, state)
+	}
+	return states
+}
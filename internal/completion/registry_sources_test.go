@@ -0,0 +1,264 @@
+package completion
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeFileRegistry creates a file registry in dir with the given registry file content
+func writeFileRegistry(t *testing.T, dir, content string) {
+	t.Helper()
+	path := filepath.Join(dir, DefaultRegistryVersion, "completion-scripts.yml")
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
+
+// newRegistryServer serves a registry over HTTPS and counts the downloads
+func newRegistryServer(t *testing.T, content string) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var downloads atomic.Int32
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/registry/v1/completion-scripts.yml" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		downloads.Add(1)
+		_, _ = w.Write([]byte(content))
+	}))
+	t.Cleanup(server.Close)
+	t.Cleanup(setupTestHTTPClient(server))
+	return server, &downloads
+}
+
+func TestRegistrySource_Paths(t *testing.T) {
+	assert.Equal(t, "/tmp/cache/completion-registry-v1.yml", DefaultRegistrySource().cachePath(testCacheDir, "v1"))
+	assert.Equal(t, "/tmp/cache/completion-registry-v1.hash", DefaultRegistrySource().hashPath(testCacheDir, "v1"))
+
+	corp := RegistrySource{Name: "corp", URL: "https://tools.example.com/dirvana"}
+	assert.False(t, corp.IsLocal())
+	assert.Equal(t, "/tmp/cache/completion-registry-corp-v1.yml", corp.path(testCacheDir, "v1"))
+	assert.Equal(t, "/tmp/cache/completion-registry-corp-v1.hash", corp.hashPath(testCacheDir, "v1"))
+	assert.Equal(t, RegistryTTL, corp.ttl())
+
+	shared := RegistrySource{Name: "shared", URL: "file:///srv/completions", TTL: time.Hour}
+	assert.True(t, shared.IsLocal())
+	assert.Equal(t, "/srv/completions/v1/completion-scripts.yml", shared.path(testCacheDir, "v1"))
+	assert.Equal(t, time.Hour, shared.ttl())
+
+	dir := RegistrySource{Name: "dir", URL: "/srv/completions"}
+	assert.Equal(t, "/srv/completions/v1/completion-scripts.yml", dir.path(testCacheDir, "v1"))
+}
+
+func TestLocalScriptPath(t *testing.T) {
+	tests := []struct {
+		url      string
+		wantPath string
+		wantOK   bool
+	}{
+		{"file:///srv/completions/foo.bash", "/srv/completions/foo.bash", true},
+		{"/srv/completions/foo.bash", "/srv/completions/foo.bash", true},
+		{"https://example.com/foo.bash", "", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			path, ok := localScriptPath(tt.url)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.wantPath, path)
+		})
+	}
+}
+
+func TestLoadRegistries_Precedence(t *testing.T) {
+	clearRegistryCache()
+	t.Cleanup(clearRegistryCache)
+	cacheDir := t.TempDir()
+
+	corp := t.TempDir()
+	writeFileRegistry(t, corp, `version: v1
+tools:
+  deployctl:
+    description: Internal deployment tool
+    script:
+      url: scripts/deployctl.bash
+  kubectl:
+    description: Patched kubectl completion
+    script:
+      url: file:///srv/kubectl.bash
+`)
+	shared := t.TempDir()
+	writeFileRegistry(t, shared, `version: v1
+tools:
+  kubectl:
+    script:
+      url: https://example.com/kubectl.bash
+  helm:
+    script:
+      url: https://example.com/helm.bash
+`)
+
+	config, err := LoadRegistries(cacheDir, []RegistrySource{
+		{Name: "corp", URL: corp},
+		{Name: "shared", URL: "file://" + shared},
+	})
+	require.NoError(t, err)
+	require.Len(t, config.Tools, 3)
+
+	assert.Equal(t, "corp", config.Tools["kubectl"].Registry)
+	assert.Equal(t, "file:///srv/kubectl.bash", config.Tools["kubectl"].Script.URL)
+	assert.Equal(t, "shared", config.Tools["helm"].Registry)
+
+	// Relative script paths are resolved against the registry file
+	assert.Equal(t, filepath.Join(corp, "v1", "scripts", "deployctl.bash"), config.Tools["deployctl"].Script.URL)
+}
+
+func TestLoadRegistries_SkipsBrokenRegistries(t *testing.T) {
+	clearRegistryCache()
+	t.Cleanup(clearRegistryCache)
+	cacheDir := t.TempDir()
+
+	team := t.TempDir()
+	writeFileRegistry(t, team, "tools:\n  foo:\n    script:\n      url: foo.bash\n")
+
+	config, err := LoadRegistries(cacheDir, []RegistrySource{
+		{Name: "missing", URL: filepath.Join(t.TempDir(), "missing")},
+		{Name: "team", URL: team},
+	})
+	require.NoError(t, err)
+	assert.Contains(t, config.Tools, "foo")
+
+	clearRegistryCache()
+	_, err = LoadRegistries(cacheDir, []RegistrySource{{Name: "missing", URL: filepath.Join(t.TempDir(), "missing")}})
+	assert.ErrorContains(t, err, "registry missing")
+
+	_, err = LoadRegistries(cacheDir, nil)
+	assert.ErrorContains(t, err, "no completion registry configured")
+}
+
+func TestLoadRegistries_Remote(t *testing.T) {
+	clearRegistryCache()
+	t.Cleanup(clearRegistryCache)
+	cacheDir := t.TempDir()
+
+	server, downloads := newRegistryServer(t, "version: v1\ntools:\n  deployctl:\n    script:\n      url: https://example.com/deployctl.bash\n")
+	source := RegistrySource{Name: "corp", URL: server.URL + "/registry/", TTL: time.Hour}
+
+	config, err := LoadRegistries(cacheDir, []RegistrySource{source})
+	require.NoError(t, err)
+	assert.Equal(t, "corp", config.Tools["deployctl"].Registry)
+	assert.Equal(t, int32(1), downloads.Load())
+	assert.FileExists(t, filepath.Join(cacheDir, "completion-registry-corp-v1.yml"))
+
+	// Cached copy is valid for the TTL of the registry
+	clearRegistryCache()
+	_, err = LoadRegistries(cacheDir, []RegistrySource{source})
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), downloads.Load())
+
+	// Expired copy is downloaded again
+	clearRegistryCache()
+	past := time.Now().Add(-2 * time.Hour)
+	require.NoError(t, os.Chtimes(filepath.Join(cacheDir, "completion-registry-corp-v1.yml"), past, past))
+	_, err = LoadRegistries(cacheDir, []RegistrySource{source})
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), downloads.Load())
+
+	// Expired copy is used when the server is down
+	clearRegistryCache()
+	require.NoError(t, os.Chtimes(filepath.Join(cacheDir, "completion-registry-corp-v1.yml"), past, past))
+	server.Close()
+	config, err = LoadRegistries(cacheDir, []RegistrySource{source})
+	require.NoError(t, err)
+	assert.Contains(t, config.Tools, "deployctl")
+}
+
+func TestRegistryStatesAndUpdate(t *testing.T) {
+	clearRegistryCache()
+	t.Cleanup(clearRegistryCache)
+	cacheDir := t.TempDir()
+
+	server, downloads := newRegistryServer(t, "version: v1\ntools:\n  a: {}\n  b: {}\n")
+	team := t.TempDir()
+	writeFileRegistry(t, team, "tools:\n  foo: {}\n")
+	sources := []RegistrySource{
+		{Name: "corp", URL: server.URL + "/registry"},
+		{Name: "team", URL: team},
+		{Name: "broken", URL: server.URL + "/missing"},
+	}
+
+	states := RegistryStates(cacheDir, sources)
+	require.Len(t, states, 3)
+	assert.ErrorContains(t, states[0].Err, "not downloaded yet")
+	assert.NoError(t, states[1].Err)
+	assert.Equal(t, 1, states[1].Tools)
+	assert.Equal(t, filepath.Join(team, "v1", "completion-scripts.yml"), states[1].Path)
+	assert.Zero(t, downloads.Load())
+
+	states = UpdateRegistries(cacheDir, sources)
+	require.Len(t, states, 3)
+	assert.NoError(t, states[0].Err)
+	assert.Equal(t, 2, states[0].Tools)
+	assert.False(t, states[0].UpdatedAt.IsZero())
+	assert.NoError(t, states[1].Err)
+	assert.ErrorContains(t, states[2].Err, "HTTP 404")
+
+	// Update ignores the TTL of the cached copy
+	UpdateRegistries(cacheDir, sources)
+	assert.Equal(t, int32(2), downloads.Load())
+
+	states = RegistryStates(cacheDir, sources)
+	assert.NoError(t, states[0].Err)
+	assert.Equal(t, filepath.Join(cacheDir, "completion-registry-corp-v1.yml"), states[0].Path)
+}
+
+func TestDownloadCompletionScript_FileRegistry(t *testing.T) {
+	clearRegistryCache()
+	t.Cleanup(clearRegistryCache)
+	cacheDir := t.TempDir()
+
+	team := t.TempDir()
+	writeFileRegistry(t, team, "tools:\n  foo:\n    script:\n      url: scripts/foo.bash\n")
+	scriptContent := "complete -W 'alpha beta' foo\n"
+	scriptPath := filepath.Join(team, "v1", "scripts", "foo.bash")
+	require.NoError(t, os.MkdirAll(filepath.Dir(scriptPath), 0755))
+	require.NoError(t, os.WriteFile(scriptPath, []byte(scriptContent), 0644))
+
+	registry, err := LoadRegistries(cacheDir, []RegistrySource{{Name: "team", URL: team}})
+	require.NoError(t, err)
+	require.NoError(t, DownloadCompletionScript(cacheDir, "foo", "bash", registry))
+
+	data, err := os.ReadFile(GetCompletionScriptPath(cacheDir, "foo", "bash"))
+	require.NoError(t, err)
+	assert.Equal(t, scriptContent, string(data))
+
+	// Remote registries cannot point to local files
+	remote := &RegistryConfig{Tools: map[string]RegistryTool{
+		"bar": {Script: RegistryScript{URL: "file://" + scriptPath}},
+	}}
+	err = DownloadCompletionScript(cacheDir, "bar", "bash", remote)
+	assert.ErrorContains(t, err, "invalid script URL")
+}
+
+func TestScriptCompleter_Registries(t *testing.T) {
+	clearRegistryCache()
+	t.Cleanup(clearRegistryCache)
+	cacheDir := t.TempDir()
+
+	team := t.TempDir()
+	writeFileRegistry(t, team, "tools:\n  dirvana-test-tool:\n    script:\n      url: tool.bash\n")
+
+	engine := NewEngine(cacheDir)
+	engine.SetRegistries([]RegistrySource{{Name: "team", URL: team}})
+
+	script := engine.completerByName["Script"].(*ScriptCompleter)
+	assert.True(t, script.Supports("dirvana-test-tool", nil))
+	assert.False(t, script.Supports("dirvana-unknown-tool", nil))
+}
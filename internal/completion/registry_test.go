@@ -39,21 +39,22 @@ func TestValidateURL(t *testing.T) {
 		assert.NoError(t, err)
 	})
 
-	t.Run("accepts valid HTTP URL", func(t *testing.T) {
+	t.Run("rejects plain HTTP URL", func(t *testing.T) {
 		err := validateURL("http://example.com/script.sh")
-		assert.NoError(t, err)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "HTTPS scheme")
 	})
 
 	t.Run("rejects URL without scheme", func(t *testing.T) {
 		err := validateURL("example.com/script.sh")
 		assert.Error(t, err)
-		assert.Contains(t, err.Error(), "HTTP or HTTPS scheme")
+		assert.Contains(t, err.Error(), "HTTPS scheme")
 	})
 
 	t.Run("rejects unsupported scheme", func(t *testing.T) {
 		err := validateURL("ftp://example.com/script.sh")
 		assert.Error(t, err)
-		assert.Contains(t, err.Error(), "HTTP or HTTPS scheme")
+		assert.Contains(t, err.Error(), "HTTPS scheme")
 	})
 
 	t.Run("rejects URL without host", func(t *testing.T) {
@@ -311,7 +312,7 @@ func TestTryLoadCachedRegistry(t *testing.T) {
 		err = os.WriteFile(registryPath, data, 0644)
 		require.NoError(t, err)
 
-		config, ok := tryLoadCachedRegistry(registryPath)
+		config, ok := tryLoadCachedRegistry(registryPath, RegistryTTL)
 		assert.True(t, ok)
 		assert.NotNil(t, config)
 		assert.Equal(t, "v1", config.Version)
@@ -335,13 +336,13 @@ func TestTryLoadCachedRegistry(t *testing.T) {
 		err = os.Chtimes(registryPath, oldTime, oldTime)
 		require.NoError(t, err)
 
-		config, ok := tryLoadCachedRegistry(registryPath)
+		config, ok := tryLoadCachedRegistry(registryPath, RegistryTTL)
 		assert.False(t, ok)
 		assert.Nil(t, config)
 	})
 
 	t.Run("returns false when file doesn't exist", func(t *testing.T) {
-		config, ok := tryLoadCachedRegistry("/nonexistent/path")
+		config, ok := tryLoadCachedRegistry("/nonexistent/path", RegistryTTL)
 		assert.False(t, ok)
 		assert.Nil(t, config)
 	})
@@ -681,6 +682,71 @@ func TestDownloadCompletionScript(t *testing.T) {
 		assert.Contains(t, err.Error(), "checksum mismatch")
 	})
 
+	t.Run("records installed script in lockfile", func(t *testing.T) {
+		tmpDir := t.TempDir()
+
+		scriptContent := "test script"
+		server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte(scriptContent))
+		}))
+		defer server.Close()
+		cleanup := setupTestHTTPClient(server)
+		defer cleanup()
+
+		registry := &RegistryConfig{
+			Version: "v1",
+			Tools: map[string]RegistryTool{
+				"test-tool": {
+					Script:   RegistryScript{URL: server.URL + "/completion.sh", SHA256: computeHash([]byte(scriptContent))},
+					Registry: "corp",
+				},
+			},
+		}
+
+		err := DownloadCompletionScript(tmpDir, "test-tool", "bash", registry)
+		require.NoError(t, err)
+
+		lock, err := LoadScriptLock(tmpDir)
+		require.NoError(t, err)
+		entry := lock.Scripts["test-tool"]
+		assert.Equal(t, computeHash([]byte(scriptContent)), entry.SHA256)
+		assert.Equal(t, server.URL+"/completion.sh", entry.URL)
+		assert.Equal(t, "corp", entry.Registry)
+		assert.True(t, entry.Verified)
+		assert.False(t, entry.InstalledAt.IsZero())
+	})
+
+	t.Run("strict mode requires a checksum", func(t *testing.T) {
+		tmpDir := t.TempDir()
+
+		server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte("test script"))
+		}))
+		defer server.Close()
+		cleanup := setupTestHTTPClient(server)
+		defer cleanup()
+
+		registry := &RegistryConfig{
+			Version: "v1",
+			Tools: map[string]RegistryTool{
+				"unpinned": {Script: RegistryScript{URL: server.URL + "/completion.sh"}},
+				"pinned":   {Script: RegistryScript{URL: server.URL + "/completion.sh", SHA256: computeHash([]byte("test script"))}},
+			},
+		}
+
+		err := InstallCompletionScript(tmpDir, "unpinned", registry, true)
+		assert.ErrorContains(t, err, "required in strict mode")
+		assert.NoFileExists(t, GetCompletionScriptPath(tmpDir, "unpinned", "bash"))
+
+		require.NoError(t, InstallCompletionScript(tmpDir, "pinned", registry, true))
+		require.NoError(t, InstallCompletionScript(tmpDir, "unpinned", registry, false))
+
+		lock, err := LoadScriptLock(tmpDir)
+		require.NoError(t, err)
+		assert.True(t, lock.Scripts["pinned"].Verified)
+		assert.False(t, lock.Scripts["unpinned"].Verified)
+	})
+
 	t.Run("fails when tool not in registry", func(t *testing.T) {
 		tmpDir := t.TempDir()
 
@@ -844,6 +910,36 @@ tools:
 		assert.Equal(t, "abc123", config.Tools["govc"].Script.SHA256)
 	})
 
+	t.Run("unmarshals native scripts", func(t *testing.T) {
+		yamlData := `
+tools:
+  kubectl:
+    script:
+      url: "https://example.com/kubectl.bash"
+    zsh:
+      url: "https://example.com/_kubectl"
+      sha256: "def456"
+    fish:
+      url: "https://example.com/kubectl.fish"
+`
+
+		var config RegistryConfig
+		err := yaml.Unmarshal([]byte(yamlData), &config)
+		require.NoError(t, err)
+
+		tool := config.Tools["kubectl"]
+		assert.Equal(t, "https://example.com/kubectl.bash", tool.ScriptFor("bash").URL)
+		assert.Equal(t, "https://example.com/kubectl.bash", tool.ScriptFor("").URL)
+		assert.Equal(t, RegistryScript{URL: "https://example.com/_kubectl", SHA256: "def456"}, tool.ScriptFor("zsh"))
+		assert.Equal(t, "https://example.com/kubectl.fish", tool.ScriptFor("fish").URL)
+
+		// Tools without native scripts are written without them
+		data, err := yaml.Marshal(RegistryTool{Script: RegistryScript{URL: "https://example.com/a.bash"}})
+		require.NoError(t, err)
+		assert.NotContains(t, string(data), "zsh")
+		assert.NotContains(t, string(data), "fish")
+	})
+
 	t.Run("handles empty tools map", func(t *testing.T) {
 		yamlData := `
 version: "v1"
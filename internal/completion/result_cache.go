@@ -0,0 +1,256 @@
+package completion
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ResultCacheMaxStale is how long expired results are still returned while they are refreshed.
+// Older results are removed from the cache.
+const ResultCacheMaxStale = 24 * time.Hour
+
+// ResultState is the freshness of a cached completion result
+type ResultState int
+
+const (
+	// ResultMissing means no usable result is cached
+	ResultMissing ResultState = iota
+	// ResultFresh means the result is younger than its time to live
+	ResultFresh
+	// ResultStale means the result expired: it can be returned but should be refreshed
+	ResultStale
+)
+
+// ResultCacheEntry stores the completion result of a command line
+type ResultCacheEntry struct {
+	Suggestions []Suggestion `json:"suggestions"`
+	Source      string       `json:"source"`
+	Directive   int          `json:"directive,omitempty"` // Cobra shell completion directive of the tool
+	Timestamp   time.Time    `json:"timestamp"`
+}
+
+// ResultCacheStats counts the lookups of the result cache
+type ResultCacheStats struct {
+	Hits      int64 `json:"hits"`       // Fresh results returned
+	StaleHits int64 `json:"stale_hits"` // Expired results returned while refreshed
+	Misses    int64 `json:"misses"`     // Results computed by the tool
+}
+
+// resultCacheFile is the persisted form of the result cache
+type resultCacheFile struct {
+	Entries map[string]ResultCacheEntry `json:"entries"`
+	Stats   ResultCacheStats            `json:"stats,omitzero"` // Written by older versions
+}
+
+// ResultCache persists completion results of slow tools.
+// Several completions may run at once (and background refreshes): only the entries and
+// statistics changed by this process are written, over the latest content of the files.
+// The statistics have their own small file, so that a cache hit does not rewrite the results.
+type ResultCache struct {
+	mu      sync.Mutex
+	path    string
+	data    resultCacheFile
+	saved   ResultCacheStats // Statistics on disk when the cache was loaded
+	updates map[string]ResultCacheEntry
+	stats   ResultCacheStats // Lookups counted since the cache was loaded
+}
+
+// NewResultCache creates or loads a result cache
+func NewResultCache(cachePath string) (*ResultCache, error) {
+	c := &ResultCache{
+		path:    cachePath,
+		updates: make(map[string]ResultCacheEntry),
+	}
+
+	data, err := readResultCacheFile(cachePath)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	c.data = data
+	c.saved = readResultCacheStats(cachePath, data)
+
+	return c, nil
+}
+
+// resultCacheStatsPath returns the path of the statistics file of a result cache
+func resultCacheStatsPath(cachePath string) string {
+	return strings.TrimSuffix(cachePath, filepath.Ext(cachePath)) + "-stats.json"
+}
+
+// readResultCacheStats returns the statistics of a result cache: those of its statistics file,
+// and those older versions kept in the results file
+func readResultCacheStats(cachePath string, data resultCacheFile) ResultCacheStats {
+	var stats ResultCacheStats
+	if content, err := os.ReadFile(resultCacheStatsPath(cachePath)); err == nil {
+		_ = json.Unmarshal(content, &stats)
+	}
+	return stats.add(data.Stats)
+}
+
+// add returns the sum of two statistics
+func (s ResultCacheStats) add(other ResultCacheStats) ResultCacheStats {
+	return ResultCacheStats{
+		Hits:      s.Hits + other.Hits,
+		StaleHits: s.StaleHits + other.StaleHits,
+		Misses:    s.Misses + other.Misses,
+	}
+}
+
+// ResultCacheKey returns the key of a completion: the tool, the shell asking (bash if empty),
+// the directory and the words. Native zsh and fish scripts give other results than bash.
+func ResultCacheKey(tool, shell, dir string, args []string) string {
+	if shell == "" {
+		shell = "bash"
+	}
+	return strings.Join(append([]string{tool, shell, dir}, args...), "\x1f")
+}
+
+// Get returns the cached result for a key and its freshness for the time to live.
+// The lookup is counted in the statistics.
+func (c *ResultCache) Get(key string, ttl time.Duration) (ResultCacheEntry, ResultState) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.data.Entries[key]
+	age := time.Since(entry.Timestamp)
+	switch {
+	case !ok || age > ttl+ResultCacheMaxStale:
+		c.stats.Misses++
+		return ResultCacheEntry{}, ResultMissing
+	case age > ttl:
+		c.stats.StaleHits++
+		return entry, ResultStale
+	default:
+		c.stats.Hits++
+		return entry, ResultFresh
+	}
+}
+
+// Set stores the result for a key
+func (c *ResultCache) Set(key string, result *Result) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := ResultCacheEntry{
+		Suggestions: result.Suggestions,
+		Source:      result.Source,
+		Directive:   result.Directive,
+		Timestamp:   time.Now(),
+	}
+	if c.data.Entries == nil {
+		c.data.Entries = make(map[string]ResultCacheEntry)
+	}
+	c.data.Entries[key] = entry
+	c.updates[key] = entry
+}
+
+// Stats returns the statistics of the cache, including the lookups of this process
+func (c *ResultCache) Stats() ResultCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.saved.add(c.stats)
+}
+
+// Save writes the results and statistics of this process to disk, over the current content
+// of the files, and removes results too old to be returned. The results file is only
+// rewritten when results changed. Files are replaced atomically.
+func (c *ResultCache) Save() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.updates) == 0 && c.stats == (ResultCacheStats{}) {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return err
+	}
+
+	if len(c.updates) > 0 {
+		if err := c.saveEntries(); err != nil {
+			return err
+		}
+		c.updates = make(map[string]ResultCacheEntry)
+	}
+
+	if c.stats != (ResultCacheStats{}) {
+		var stats ResultCacheStats
+		if content, err := os.ReadFile(resultCacheStatsPath(c.path)); err == nil {
+			_ = json.Unmarshal(content, &stats)
+		}
+		stats = stats.add(c.stats)
+		content, err := json.Marshal(stats)
+		if err != nil {
+			return err
+		}
+		if err := writeFileAtomic(resultCacheStatsPath(c.path), content); err != nil {
+			return err
+		}
+		c.saved = stats.add(c.data.Stats)
+		c.stats = ResultCacheStats{}
+	}
+
+	return nil
+}
+
+// saveEntries writes the results of this process over the current content of the results file
+func (c *ResultCache) saveEntries() error {
+	data, err := readResultCacheFile(c.path)
+	if err != nil && !os.IsNotExist(err) {
+		// Corrupted cache: start over
+		data = resultCacheFile{}
+	}
+	if data.Entries == nil {
+		data.Entries = make(map[string]ResultCacheEntry)
+	}
+	for key, entry := range c.updates {
+		data.Entries[key] = entry
+	}
+	for key, entry := range data.Entries {
+		if time.Since(entry.Timestamp) > ResultCacheMaxStale {
+			delete(data.Entries, key)
+		}
+	}
+
+	content, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(c.path, content); err != nil {
+		return err
+	}
+	c.data = data
+	return nil
+}
+
+// writeFileAtomic replaces a file with new content through a temporary file
+func writeFileAtomic(path string, content []byte) error {
+	tmpFile, err := os.CreateTemp(filepath.Dir(path), ".completion-results-*")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmpFile.Name()) }()
+	if _, err := tmpFile.Write(content); err != nil {
+		_ = tmpFile.Close()
+		return err
+	}
+	if err := tmpFile.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmpFile.Name(), path)
+}
+
+// readResultCacheFile reads the persisted result cache
+func readResultCacheFile(path string) (resultCacheFile, error) {
+	var data resultCacheFile
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return data, err
+	}
+	err = json.Unmarshal(content, &data)
+	return data, err
+}
@@ -0,0 +1,146 @@
+package completion
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResultCache_GetSet(t *testing.T) {
+	cache, err := NewResultCache(filepath.Join(t.TempDir(), "results.json"))
+	require.NoError(t, err)
+	key := ResultCacheKey("helm", "bash", "/project", []string{"install", ""})
+
+	_, state := cache.Get(key, time.Minute)
+	assert.Equal(t, ResultMissing, state)
+
+	cache.Set(key, &Result{Suggestions: []Suggestion{{Value: "--wait"}}, Source: "Cobra", Directive: ShellCompDirectiveKeepOrder})
+	entry, state := cache.Get(key, time.Minute)
+	assert.Equal(t, ResultFresh, state)
+	assert.Equal(t, []Suggestion{{Value: "--wait"}}, entry.Suggestions)
+	assert.Equal(t, "Cobra", entry.Source)
+	assert.Equal(t, ShellCompDirectiveKeepOrder, entry.Directive)
+
+	// Expired: still returned, to refresh
+	_, state = cache.Get(key, 0)
+	assert.Equal(t, ResultStale, state)
+
+	assert.Equal(t, ResultCacheStats{Hits: 1, StaleHits: 1, Misses: 1}, cache.Stats())
+}
+
+func TestResultCacheKey(t *testing.T) {
+	args := []string{"install", ""}
+	assert.Equal(t, ResultCacheKey("helm", "bash", "/project", args), ResultCacheKey("helm", "", "/project", args))
+	// Native zsh and fish scripts give other results than bash
+	assert.NotEqual(t, ResultCacheKey("helm", "bash", "/project", args), ResultCacheKey("helm", "zsh", "/project", args))
+	assert.NotEqual(t, ResultCacheKey("helm", "zsh", "/project", args), ResultCacheKey("helm", "fish", "/project", args))
+}
+
+func TestResultCache_TooOld(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "results.json")
+	old := time.Now().Add(-ResultCacheMaxStale - 2*time.Minute).Format(time.RFC3339)
+	require.NoError(t, os.WriteFile(path, []byte(`{"entries":{"old":{"suggestions":[],"source":"Cobra","timestamp":"`+old+`"}}}`), 0644))
+
+	cache, err := NewResultCache(path)
+	require.NoError(t, err)
+
+	_, state := cache.Get("old", time.Minute)
+	assert.Equal(t, ResultMissing, state)
+
+	// Removed on save
+	cache.Set("new", &Result{Source: "Cobra"})
+	require.NoError(t, cache.Save())
+	data, err := readResultCacheFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, data.Entries, "old")
+	assert.Contains(t, data.Entries, "new")
+}
+
+func TestResultCache_SaveMergesConcurrentWrites(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sub", "results.json")
+
+	first, err := NewResultCache(path)
+	require.NoError(t, err)
+	second, err := NewResultCache(path)
+	require.NoError(t, err)
+
+	first.Get("a", time.Minute)
+	first.Set("a", &Result{Suggestions: []Suggestion{{Value: "one"}}, Source: "Cobra"})
+	second.Get("b", time.Minute)
+	second.Set("b", &Result{Suggestions: []Suggestion{{Value: "two"}}, Source: "Flag"})
+	require.NoError(t, first.Save())
+	require.NoError(t, second.Save())
+
+	loaded, err := NewResultCache(path)
+	require.NoError(t, err)
+	_, state := loaded.Get("a", time.Minute)
+	assert.Equal(t, ResultFresh, state)
+	entry, state := loaded.Get("b", time.Minute)
+	assert.Equal(t, ResultFresh, state)
+	assert.Equal(t, []Suggestion{{Value: "two"}}, entry.Suggestions)
+
+	// Misses of both processes, then the two hits of this one
+	assert.Equal(t, ResultCacheStats{Hits: 2, Misses: 2}, loaded.Stats())
+}
+
+func TestResultCache_SaveNothing(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "results.json")
+	cache, err := NewResultCache(path)
+	require.NoError(t, err)
+
+	require.NoError(t, cache.Save())
+	assert.NoFileExists(t, path)
+}
+
+func TestResultCache_SaveHitsWithoutRewritingResults(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "results.json")
+	cache, err := NewResultCache(path)
+	require.NoError(t, err)
+	cache.Set("a", &Result{Suggestions: []Suggestion{{Value: "one"}}, Source: "Cobra"})
+	require.NoError(t, cache.Save())
+	written, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	// Two completions served from the cache, one right after the other
+	for i := 0; i < 2; i++ {
+		cache, err = NewResultCache(path)
+		require.NoError(t, err)
+		_, state := cache.Get("a", time.Minute)
+		require.Equal(t, ResultFresh, state)
+		require.NoError(t, cache.Save())
+	}
+
+	// Both hits are counted, the results are not rewritten
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, written, content)
+	loaded, err := NewResultCache(path)
+	require.NoError(t, err)
+	assert.Equal(t, ResultCacheStats{Hits: 2}, loaded.Stats())
+}
+
+func TestResultCache_LegacyStats(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "results.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"entries":{},"stats":{"hits":3,"stale_hits":1,"misses":2}}`), 0644))
+
+	cache, err := NewResultCache(path)
+	require.NoError(t, err)
+	cache.Get("a", time.Minute)
+	require.NoError(t, cache.Save())
+
+	loaded, err := NewResultCache(path)
+	require.NoError(t, err)
+	assert.Equal(t, ResultCacheStats{Hits: 3, StaleHits: 1, Misses: 3}, loaded.Stats())
+}
+
+func TestResultCache_InvalidFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "results.json")
+	require.NoError(t, os.WriteFile(path, []byte("not json"), 0644))
+
+	_, err := NewResultCache(path)
+	assert.Error(t, err)
+}
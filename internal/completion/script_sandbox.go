@@ -0,0 +1,156 @@
+package completion
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// DefaultScriptTimeout is how long a bash completion script may run, bash_completion included
+const DefaultScriptTimeout = time.Second
+
+// sandboxEnv lists the variables passed to every bash completion script in the sandbox
+var sandboxEnv = []string{"PATH", "LANG", "LANGUAGE", "LC_ALL", "LC_CTYPE", "LC_MESSAGES", "TERM", "USER", "LOGNAME", "SHELL", "TZ"}
+
+// errOutputLimit stops the copy of the output of a script once the limit is reached
+var errOutputLimit = errors.New("output limit reached")
+
+// ScriptSandbox is the policy for running completion scripts. By default, scripts get a minimal
+// environment and a temporary HOME, and are stopped after DefaultScriptTimeout or MaxOutputSize bytes.
+type ScriptSandbox struct {
+	Disabled  bool                // Run scripts with the full environment and HOME of the user
+	ReadOnly  bool                // Run scripts in a read-only view of the filesystem with bwrap, when installed
+	Timeout   time.Duration       // How long a script may run, DefaultScriptTimeout if 0
+	MaxOutput int                 // Maximum output read from a script in bytes, MaxOutputSize if 0
+	Env       []string            // Variables passed to all scripts, in addition to the default ones
+	Tools     map[string][]string // Variables passed to the scripts of a tool; HOME keeps the real home
+}
+
+// timeout returns how long a script may run
+func (p ScriptSandbox) timeout() time.Duration {
+	if p.Timeout > 0 {
+		return p.Timeout
+	}
+	return DefaultScriptTimeout
+}
+
+// maxOutput returns the maximum output read from a script
+func (p ScriptSandbox) maxOutput() int {
+	if p.MaxOutput > 0 {
+		return p.MaxOutput
+	}
+	return MaxOutputSize
+}
+
+// environ returns the environment of the scripts of a tool: the allowed variables of environ,
+// with HOME and TMPDIR set to home unless the tool opted in for HOME
+func (p ScriptSandbox) environ(environ []string, tool, home string) []string {
+	allowed := append(append(append([]string{}, sandboxEnv...), p.Env...), p.Tools[filepath.Base(tool)]...)
+
+	env := make([]string, 0, len(allowed)+2)
+	for _, entry := range environ {
+		name, _, _ := strings.Cut(entry, "=")
+		if slices.Contains(allowed, name) && name != "TMPDIR" {
+			env = append(env, entry)
+		}
+	}
+	if !slices.Contains(allowed, "HOME") {
+		env = append(env, "HOME="+home)
+	}
+	return append(env, "TMPDIR="+home)
+}
+
+// bwrapArgs returns the bwrap arguments running a command in a read-only view of the filesystem,
+// where only the temporary home is writable
+func bwrapArgs(home, dir string, command []string) []string {
+	args := []string{
+		"--ro-bind", "/", "/",
+		"--dev", "/dev",
+		"--proc", "/proc",
+		"--bind", home, home,
+		"--chdir", dir,
+		"--die-with-parent",
+		"--",
+	}
+	return append(args, command...)
+}
+
+// run runs a completion script of a tool under the policy; command is the shell running
+// it with its arguments (e.g. bash -c <script>)
+func (p ScriptSandbox) run(tool string, command ...string) ([]byte, error) {
+	timeout := p.timeout()
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if p.Disabled {
+		cmd := exec.CommandContext(ctx, command[0], command[1:]...)
+		return runWithOutputLimit(ctx, cmd, timeout, p.maxOutput())
+	}
+
+	home, err := os.MkdirTemp("", "dirvana-completion-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create sandbox home: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(home) }()
+
+	name, args := command[0], command[1:]
+	if p.ReadOnly {
+		dir, dirErr := os.Getwd()
+		if bwrap, err := exec.LookPath("bwrap"); err == nil && dirErr == nil {
+			name, args = bwrap, bwrapArgs(home, dir, command)
+		}
+	}
+
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Env = p.environ(os.Environ(), tool, home)
+	return runWithOutputLimit(ctx, cmd, timeout, p.maxOutput())
+}
+
+// runWithOutputLimit runs a command, reading at most maxOutput bytes of its output.
+// A truncated output is cut after its last complete line.
+func runWithOutputLimit(ctx context.Context, cmd *exec.Cmd, timeout time.Duration, maxOutput int) ([]byte, error) {
+	out := &limitedBuffer{max: maxOutput}
+	cmd.Stdout = out
+	// Don't wait for children keeping the output open after the script was killed
+	cmd.WaitDelay = 100 * time.Millisecond
+
+	err := cmd.Run()
+	if ctx.Err() == context.DeadlineExceeded {
+		return nil, fmt.Errorf("command timeout after %v", timeout)
+	}
+	if out.truncated {
+		data := out.buf.Bytes()
+		if i := bytes.LastIndexByte(data, '\n'); i >= 0 {
+			data = data[:i+1]
+		}
+		return data, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return out.buf.Bytes(), nil
+}
+
+// limitedBuffer keeps the first max bytes written, and fails once they are reached
+type limitedBuffer struct {
+	buf       bytes.Buffer
+	max       int
+	truncated bool
+}
+
+// Write implements io.Writer
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if room := b.max - b.buf.Len(); len(p) > room {
+		b.buf.Write(p[:room])
+		b.truncated = true
+		return room, errOutputLimit
+	}
+	return b.buf.Write(p)
+}
@@ -0,0 +1,123 @@
+package completion
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScriptSandbox_Environ(t *testing.T) {
+	environ := []string{
+		"PATH=/usr/bin",
+		"LANG=C.UTF-8",
+		"HOME=/home/user",
+		"TMPDIR=/var/tmp",
+		"GITHUB_TOKEN=secret",
+		"DOCKER_HOST=tcp://docker:2375",
+		"KUBECONFIG=/home/user/.kube/config",
+		"BASH_ENV=/home/user/.evil",
+	}
+
+	env := ScriptSandbox{}.environ(environ, "docker", "/tmp/sandbox")
+	assert.Equal(t, []string{"PATH=/usr/bin", "LANG=C.UTF-8", "HOME=/tmp/sandbox", "TMPDIR=/tmp/sandbox"}, env)
+
+	policy := ScriptSandbox{
+		Env:   []string{"KUBECONFIG"},
+		Tools: map[string][]string{"docker": {"DOCKER_HOST"}, "git": {"HOME"}},
+	}
+	env = policy.environ(environ, "/usr/bin/docker", "/tmp/sandbox")
+	assert.Equal(t, []string{
+		"PATH=/usr/bin",
+		"LANG=C.UTF-8",
+		"DOCKER_HOST=tcp://docker:2375",
+		"KUBECONFIG=/home/user/.kube/config",
+		"HOME=/tmp/sandbox",
+		"TMPDIR=/tmp/sandbox",
+	}, env)
+
+	// Opting in for HOME keeps the real home
+	env = policy.environ(environ, "git", "/tmp/sandbox")
+	assert.Contains(t, env, "HOME=/home/user")
+	assert.NotContains(t, env, "HOME=/tmp/sandbox")
+	assert.NotContains(t, env, "DOCKER_HOST=tcp://docker:2375")
+}
+
+func TestBwrapArgs(t *testing.T) {
+	args := bwrapArgs("/tmp/sandbox", "/work", []string{"bash", "-c", "echo ok"})
+	assert.Equal(t, []string{"--ro-bind", "/", "/"}, args[:3])
+	assert.Contains(t, strings.Join(args, " "), "--bind /tmp/sandbox /tmp/sandbox")
+	assert.Contains(t, strings.Join(args, " "), "--chdir /work")
+	assert.Equal(t, []string{"--", "bash", "-c", "echo ok"}, args[len(args)-4:])
+}
+
+func TestScriptSandbox_Run(t *testing.T) {
+	if _, err := exec.LookPath("bash"); err != nil {
+		t.Skip("bash not available")
+	}
+	t.Setenv("DIRVANA_TEST_SECRET", "secret")
+	t.Setenv("DIRVANA_TEST_OPTIN", "visible")
+	realHome, err := os.UserHomeDir()
+	require.NoError(t, err)
+
+	t.Run("isolates environment and home", func(t *testing.T) {
+		policy := ScriptSandbox{Tools: map[string][]string{"mytool": {"DIRVANA_TEST_OPTIN"}}}
+		output, err := policy.run("mytool", "bash", "-c", `echo "secret=$DIRVANA_TEST_SECRET"; echo "optin=$DIRVANA_TEST_OPTIN"; echo "home=$HOME"; touch "$HOME/written"`)
+		require.NoError(t, err)
+
+		lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+		require.Len(t, lines, 3)
+		assert.Equal(t, "secret=", lines[0])
+		assert.Equal(t, "optin=visible", lines[1])
+		home := strings.TrimPrefix(lines[2], "home=")
+		assert.NotEqual(t, realHome, home)
+		assert.NoDirExists(t, home, "Temporary home is removed")
+	})
+
+	t.Run("disabled keeps the environment", func(t *testing.T) {
+		output, err := ScriptSandbox{Disabled: true}.run("mytool", "bash", "-c", `echo "$DIRVANA_TEST_SECRET"`)
+		require.NoError(t, err)
+		assert.Equal(t, "secret\n", string(output))
+	})
+
+	t.Run("stops slow scripts", func(t *testing.T) {
+		start := time.Now()
+		_, err := ScriptSandbox{Timeout: 200 * time.Millisecond}.run("mytool", "bash", "-c", "sleep 5; echo late")
+		assert.ErrorContains(t, err, "timeout after 200ms")
+		assert.Less(t, time.Since(start), 2*time.Second)
+	})
+
+	t.Run("limits output to complete lines", func(t *testing.T) {
+		output, err := ScriptSandbox{MaxOutput: 100}.run("mytool", "bash", "-c", `for i in $(seq 1 100000); do echo "suggestion$i"; done`)
+		require.NoError(t, err)
+		assert.LessOrEqual(t, len(output), 100)
+		assert.True(t, strings.HasPrefix(string(output), "suggestion1\nsuggestion2\n"))
+		assert.True(t, strings.HasSuffix(string(output), "\n"))
+	})
+
+	t.Run("read-only falls back without bwrap", func(t *testing.T) {
+		if _, err := exec.LookPath("bwrap"); err == nil {
+			t.Skip("bwrap is installed")
+		}
+		output, err := ScriptSandbox{ReadOnly: true}.run("mytool", "bash", "-c", "echo ok")
+		require.NoError(t, err)
+		assert.Equal(t, "ok\n", string(output))
+	})
+}
+
+func TestLimitedBuffer(t *testing.T) {
+	b := &limitedBuffer{max: 5}
+	n, err := b.Write([]byte("abc"))
+	assert.NoError(t, err)
+	assert.Equal(t, 3, n)
+
+	n, err = b.Write([]byte("defg"))
+	assert.ErrorIs(t, err, errOutputLimit)
+	assert.Equal(t, 2, n)
+	assert.True(t, b.truncated)
+	assert.Equal(t, "abcde", b.buf.String())
+}
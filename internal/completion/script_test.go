@@ -1,11 +1,14 @@
 package completion
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestScriptCompleter_New(t *testing.T) {
@@ -433,3 +436,86 @@ exit 42
 		}
 	})
 }
+
+func TestScriptCompleter_Strict(t *testing.T) {
+	clearRegistryCache()
+	t.Cleanup(clearRegistryCache)
+
+	tmpDir := t.TempDir()
+	registryContent := `version: v1
+tools:
+  pinned-tool:
+    script:
+      url: https://example.com/pinned-tool
+      sha256: abc123
+  unpinned-tool:
+    script:
+      url: https://example.com/unpinned-tool
+`
+	err := os.WriteFile(filepath.Join(tmpDir, "completion-registry-v1.yml"), []byte(registryContent), 0644)
+	assert.NoError(t, err)
+
+	s := NewScriptCompleter(tmpDir)
+	assert.True(t, s.Supports("unpinned-tool", nil))
+
+	s.strict = true
+	assert.True(t, s.Supports("pinned-tool", nil))
+	assert.False(t, s.Supports("unpinned-tool", nil), "Registry tools without checksum are refused in strict mode")
+
+	// Installed script modified on disk
+	scriptContent := "complete -W 'alpha' local-tool\n"
+	path := installTestScript(t, tmpDir, "local-tool", scriptContent)
+	lock, err := LoadScriptLock(tmpDir)
+	assert.NoError(t, err)
+	lock.Record("local-tool", ScriptLockEntry{SHA256: computeHash([]byte(scriptContent)), Verified: true})
+	assert.NoError(t, lock.Save())
+	assert.Equal(t, path, s.findCompletionScript("local-tool"))
+
+	assert.NoError(t, os.WriteFile(path, []byte("echo tampered\n"), 0644))
+	assert.Equal(t, "", s.findCompletionScript("local-tool"))
+
+	s.strict = false
+	assert.Equal(t, path, s.findCompletionScript("local-tool"))
+}
+
+func TestScriptCompleter_StrictSkipsUnverifiedScripts(t *testing.T) {
+	clearRegistryCache()
+	t.Cleanup(clearRegistryCache)
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("_unpinned-tool() { COMPREPLY=(alpha beta); }\ncomplete -F _unpinned-tool unpinned-tool\n"))
+	}))
+	defer server.Close()
+	cleanup := setupTestHTTPClient(server)
+	defer cleanup()
+
+	tmpDir := t.TempDir()
+	registryContent := `version: v1
+tools:
+  unpinned-tool:
+    script:
+      url: ` + server.URL + `/unpinned-tool.bash
+    fish:
+      url: ` + server.URL + `/unpinned-tool.fish
+`
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "completion-registry-v1.yml"), []byte(registryContent), 0644))
+
+	// Installed without a checksum in non-strict mode
+	s := NewScriptCompleter(tmpDir)
+	suggestions, err := s.Complete("unpinned-tool", []string{""})
+	require.NoError(t, err)
+	assert.Len(t, suggestions, 2)
+
+	s.shell = "fish"
+	nativePath := s.ensureNativeScript("unpinned-tool")
+	require.Equal(t, GetCompletionScriptPath(tmpDir, "unpinned-tool", "fish"), nativePath)
+
+	// Intact, but never verified: refused in strict mode
+	s.strict = true
+	assert.Equal(t, "", s.findCompletionScript("unpinned-tool"))
+	assert.Equal(t, "", s.findNativeScript("unpinned-tool"))
+
+	s.shell = ""
+	_, err = s.Complete("unpinned-tool", []string{""})
+	assert.Error(t, err)
+}
@@ -0,0 +1,66 @@
+package completion
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// TabtabCompleter handles Node.js tools using tabtab or npm's completion protocol (npm, pnpm, ...)
+// The tool is called as 'tool completion -- <words>' with COMP_CWORD, COMP_LINE and COMP_POINT
+type TabtabCompleter struct{}
+
+// NewTabtabCompleter creates a new tabtab completer
+func NewTabtabCompleter() *TabtabCompleter {
+	return &TabtabCompleter{}
+}
+
+// Supports checks if the tool is a Node.js script answering the protocol with a word list.
+// A 'completion' command printing a shell script instead is rejected by the validation.
+func (t *TabtabCompleter) Supports(tool string, _ []string) bool {
+	if !isNodeScript(tool) {
+		return false
+	}
+
+	output, err := t.run(tool, nil, "/bin/bash")
+	return err == nil && validateWordList(output)
+}
+
+// Complete calls the tool with the tabtab protocol.
+// SHELL is set to fish so tabtab prints 'value<TAB>description' items.
+func (t *TabtabCompleter) Complete(tool string, args []string) ([]Suggestion, error) {
+	output, err := t.run(tool, args, "/usr/bin/fish")
+	if err != nil {
+		return nil, err
+	}
+
+	return parseTabtabOutput(output), nil
+}
+
+// run executes the tool in completion mode
+func (t *TabtabCompleter) run(tool string, args []string, shell string) ([]byte, error) {
+	words := append([]string{filepath.Base(tool)}, args...)
+	if len(args) == 0 {
+		words = append(words, "")
+	}
+	compLine := strings.Join(words, " ")
+
+	ctx, cancel := context.WithTimeout(context.Background(), NodeCommandTimeout)
+	defer cancel()
+
+	env := append(os.Environ(),
+		"SHELL="+shell,
+		fmt.Sprintf("COMP_CWORD=%d", len(words)-1),
+		"COMP_LINE="+compLine,
+		fmt.Sprintf("COMP_POINT=%d", len(compLine)),
+	)
+	return execWithTimeoutAndEnv(ctx, env, tool, append([]string{"completion", "--"}, words...)...)
+}
+
+// parseTabtabOutput parses tabtab fish completion output
+// Format: one suggestion per line, description after a tab
+func parseTabtabOutput(output []byte) []Suggestion {
+	return parseCompletionOutput(output, true)
+}
@@ -0,0 +1,51 @@
+package completion
+
+import (
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTabtabCompleter_Supports(t *testing.T) {
+	if _, err := exec.LookPath("node"); err != nil {
+		t.Skip("node not available")
+	}
+	c := NewTabtabCompleter()
+
+	assert.True(t, c.Supports(writeMockTool(t, "mock-tabtab", `#!/usr/bin/env node
+if (process.argv[2] !== 'completion' || !process.env.COMP_LINE) process.exit(1)
+console.log('install\nrun')
+`), nil))
+
+	// A completion command printing a shell script
+	assert.False(t, c.Supports(writeMockTool(t, "mock-script", `#!/usr/bin/env node
+console.log('_mock_completion () {\n  COMPREPLY=()\n}')
+`), nil))
+
+	// Not a Node.js script: never executed
+	assert.False(t, c.Supports(writeMockTool(t, "mock-sh", "#!/bin/sh\necho install\n"), nil))
+}
+
+func TestTabtabCompleter_Complete(t *testing.T) {
+	tool := writeMockTool(t, "mock-tabtab", `#!/bin/bash
+[ "$1" = "completion" ] && [ "$2" = "--" ] || exit 1
+shift 2
+printf 'words=%s\n' "$(IFS='|'; echo "$*")"
+printf 'cword=%s\n' "$COMP_CWORD"
+printf 'line=%s\n' "$COMP_LINE"
+printf 'point=%s\n' "$COMP_POINT"
+printf 'install\tInstall a package\n'
+`)
+
+	suggestions, err := NewTabtabCompleter().Complete(tool, []string{"run", "b"})
+	require.NoError(t, err)
+	assert.Equal(t, []Suggestion{
+		{Value: "words=mock-tabtab|run|b"},
+		{Value: "cword=2"},
+		{Value: "line=mock-tabtab run b"},
+		{Value: "point=17"},
+		{Value: "install", Description: "Install a package"},
+	}, suggestions)
+}
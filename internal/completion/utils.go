@@ -5,7 +5,10 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io"
+	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 	"time"
 )
@@ -115,3 +118,62 @@ func validateSimpleOutput(output []byte, minLines int) bool {
 
 	return validLines >= minLines
 }
+
+// scriptHeadSize is how much of an executable is read to find its interpreter
+const scriptHeadSize = 1024
+
+// inspectScript finds a tool in PATH and reads the beginning of its executable,
+// without running it. The interpreter is the program named by the shebang
+// ('#!/usr/bin/env node' names node), empty for binaries and unknown tools.
+func inspectScript(tool string) (path, interpreter, head string) {
+	path, err := exec.LookPath(tool)
+	if err != nil {
+		return "", "", ""
+	}
+
+	head, err = readFileHead(path, scriptHeadSize)
+	if err != nil || !strings.HasPrefix(head, "#!") {
+		return path, "", head
+	}
+
+	shebang, _, _ := strings.Cut(head, "\n")
+	fields := strings.Fields(strings.TrimPrefix(shebang, "#!"))
+	if len(fields) == 0 {
+		return path, "", head
+	}
+
+	interpreter = fields[0]
+	if filepath.Base(interpreter) == "env" && len(fields) > 1 {
+		interpreter = fields[1]
+		// env -S splits the rest of the line into arguments
+		if interpreter == "-S" && len(fields) > 2 {
+			interpreter = fields[2]
+		}
+	}
+	return path, interpreter, head
+}
+
+// readFileHead reads at most size bytes from the beginning of a file
+func readFileHead(path string, size int64) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = file.Close() }()
+
+	data, err := io.ReadAll(io.LimitReader(file, size))
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// containsAny reports whether s contains one of the substrings
+func containsAny(s string, substrs []string) bool {
+	for _, substr := range substrs {
+		if strings.Contains(s, substr) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,62 @@
+package completion
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+)
+
+// yargsCompletionFlag is the hidden flag of yargs programs with completion enabled
+const yargsCompletionFlag = "--get-yargs-completions"
+
+// YargsCompleter handles Node.js tools built with yargs (nx, and CLIs calling .completion())
+// The tool is called with --get-yargs-completions followed by the command line
+type YargsCompleter struct{}
+
+// NewYargsCompleter creates a new yargs completer
+func NewYargsCompleter() *YargsCompleter {
+	return &YargsCompleter{}
+}
+
+// Supports checks if the tool is a Node.js script answering --get-yargs-completions with a word list.
+// Other tools are never executed: an unknown flag could run their default command.
+func (y *YargsCompleter) Supports(tool string, _ []string) bool {
+	if !isNodeScript(tool) {
+		return false
+	}
+
+	output, err := y.run(tool, nil, "/bin/bash")
+	return err == nil && validateWordList(output)
+}
+
+// Complete calls the tool with the yargs completion protocol.
+// SHELL is set to zsh so yargs prints 'name:description' items.
+func (y *YargsCompleter) Complete(tool string, args []string) ([]Suggestion, error) {
+	output, err := y.run(tool, args, "/bin/zsh")
+	if err != nil {
+		return nil, err
+	}
+
+	return parseYargsOutput(output), nil
+}
+
+// run executes the tool in completion mode. Like yargs' own shell scripts,
+// the command line is passed with the program name and the current word.
+func (y *YargsCompleter) run(tool string, args []string, shell string) ([]byte, error) {
+	words := append([]string{yargsCompletionFlag, filepath.Base(tool)}, args...)
+	if len(args) == 0 {
+		words = append(words, "")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), NodeCommandTimeout)
+	defer cancel()
+	env := append(os.Environ(), "SHELL="+shell)
+	return execWithTimeoutAndEnv(ctx, env, tool, words...)
+}
+
+// parseYargsOutput parses yargs zsh completion output
+// Format: one suggestion per line, optionally followed by ':description'
+// (colons in values are escaped as '\:')
+func parseYargsOutput(output []byte) []Suggestion {
+	return parseFlagOutput(output)
+}
@@ -0,0 +1,51 @@
+package completion
+
+import (
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestYargsCompleter_Supports(t *testing.T) {
+	if _, err := exec.LookPath("node"); err != nil {
+		t.Skip("node not available")
+	}
+	c := NewYargsCompleter()
+
+	assert.True(t, c.Supports(writeMockTool(t, "mock-yargs", `#!/usr/bin/env node
+if (process.argv[2] !== '--get-yargs-completions') process.exit(1)
+console.log('build\nserve')
+`), nil))
+
+	// Not answering with completions
+	assert.False(t, c.Supports(writeMockTool(t, "mock-help", "#!/usr/bin/env node\nconsole.log('Usage: mock-help <command>')\n"), nil))
+
+	// Not a Node.js script: never executed
+	assert.False(t, c.Supports(writeMockTool(t, "mock-sh", "#!/bin/sh\necho build\n"), nil))
+}
+
+func TestYargsCompleter_Complete(t *testing.T) {
+	tool := writeMockTool(t, "mock-yargs", `#!/bin/bash
+[ "$1" = "--get-yargs-completions" ] || exit 1
+shift
+printf 'args=%s\n' "$(IFS='|'; echo "$*")"
+printf 'shell=%s\n' "$SHELL"
+printf 'serve:Start the dev server\n'
+printf 'app\\:build:Build with a colon\n'
+`)
+
+	suggestions, err := NewYargsCompleter().Complete(tool, []string{"run", "s"})
+	require.NoError(t, err)
+	assert.Equal(t, []Suggestion{
+		{Value: "args=mock-yargs|run|s"},
+		{Value: "shell=/bin/zsh"},
+		{Value: "serve", Description: "Start the dev server"},
+		{Value: "app:build", Description: "Build with a colon"},
+	}, suggestions)
+
+	suggestions, err = NewYargsCompleter().Complete(tool, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "args=mock-yargs|", suggestions[0].Value)
+}
@@ -0,0 +1,260 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/NikitaCOEUR/dirvana/internal/completion"
+)
+
+// DefaultCompletionCacheTTL is the time to live of cached completion results when no TTL is set
+const DefaultCompletionCacheTTL = time.Minute
+
+// CompletionSettings configures the completion engine. Settings are only read from the global config.
+type CompletionSettings struct {
+	Cache      CompletionCacheSettings  `koanf:"cache"`
+	Registries []CompletionRegistry     `koanf:"registries"`
+	Scripts    CompletionScriptSettings `koanf:"scripts"`
+	Plugins    []CompletionPlugin       `koanf:"plugins"`
+	Match      string                   `koanf:"match"` // Matching mode of suggestions, prefix if empty (see completion.MatchModes)
+}
+
+// CompletionPlugin declares an external completer: an executable speaking dirvana's JSON
+// completion protocol. Executables named dirvana-completer-<name> on PATH are found without it.
+type CompletionPlugin struct {
+	Name    string `koanf:"name"`    // Name of the completer
+	Path    string `koanf:"path"`    // Executable, dirvana-completer-<name> on PATH if empty
+	Timeout string `koanf:"timeout"` // How long the completer may take to answer (e.g. 2s), 1s if empty
+}
+
+// TimeoutDuration returns how long the completer may take to answer, 0 for the default
+func (p CompletionPlugin) TimeoutDuration() time.Duration {
+	timeout, err := time.ParseDuration(p.Timeout)
+	if err != nil || timeout < 0 {
+		return 0
+	}
+	return timeout
+}
+
+// Validate checks the name and timeout of an external completer
+func (p CompletionPlugin) Validate() error {
+	if !namePattern.MatchString(p.Name) {
+		return fmt.Errorf("invalid name '%s' (letters, digits, '-' and '_' only)", p.Name)
+	}
+	if err := validateTTL(p.Timeout); err != nil {
+		return fmt.Errorf("plugin '%s' timeout: %w", p.Name, err)
+	}
+	return nil
+}
+
+// validateMatchMode checks that a matching mode is empty or one of completion.MatchModes
+func validateMatchMode(mode string) error {
+	if mode == "" || slices.Contains(completion.MatchModes, completion.MatchMode(mode)) {
+		return nil
+	}
+	names := make([]string, len(completion.MatchModes))
+	for i, m := range completion.MatchModes {
+		names[i] = string(m)
+	}
+	return fmt.Errorf("invalid match mode '%s' (expected %s)", mode, strings.Join(names, ", "))
+}
+
+// CompletionScriptSettings configures the bash completion scripts downloaded from registries
+type CompletionScriptSettings struct {
+	Strict  bool                      `koanf:"strict"` // Refuse scripts without a matching checksum, or modified since installed
+	Sandbox CompletionSandboxSettings `koanf:"sandbox"`
+}
+
+// CompletionSandboxSettings configures how bash completion scripts are run. By default they get
+// a minimal environment and a temporary HOME.
+type CompletionSandboxSettings struct {
+	Disabled  bool                `koanf:"disabled"`   // Run scripts with the full environment of the user
+	ReadOnly  bool                `koanf:"read_only"`  // Read-only view of the filesystem with bwrap, when installed
+	Timeout   string              `koanf:"timeout"`    // How long a script may run (e.g. 2s), 1s if empty
+	MaxOutput int                 `koanf:"max_output"` // Maximum output read from a script in bytes
+	Env       []string            `koanf:"env"`        // Variables passed to all scripts
+	Tools     map[string][]string `koanf:"tools"`      // Variables passed to the scripts of a tool (HOME for the real home)
+}
+
+// envNamePattern matches environment variable names
+var envNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// TimeoutDuration returns how long a script may run, 0 for the default
+func (s CompletionSandboxSettings) TimeoutDuration() time.Duration {
+	timeout, err := time.ParseDuration(s.Timeout)
+	if err != nil || timeout < 0 {
+		return 0
+	}
+	return timeout
+}
+
+// validate checks the timeout, output limit and variable names of the sandbox
+func (s CompletionSandboxSettings) validate() error {
+	if err := validateTTL(s.Timeout); err != nil {
+		return fmt.Errorf("timeout: %w", err)
+	}
+	if s.MaxOutput < 0 {
+		return fmt.Errorf("negative max_output %d", s.MaxOutput)
+	}
+	for _, name := range s.Env {
+		if !envNamePattern.MatchString(name) {
+			return fmt.Errorf("invalid variable name '%s'", name)
+		}
+	}
+
+	tools := make([]string, 0, len(s.Tools))
+	for tool := range s.Tools {
+		tools = append(tools, tool)
+	}
+	sort.Strings(tools)
+	for _, tool := range tools {
+		for _, name := range s.Tools[tool] {
+			if !envNamePattern.MatchString(name) {
+				return fmt.Errorf("invalid variable name '%s' for %s", name, tool)
+			}
+		}
+	}
+	return nil
+}
+
+// DefaultCompletionRegistry is the name of the built-in registry, used last unless listed in registries
+const DefaultCompletionRegistry = "default"
+
+// namePattern restricts registry and plugin names to what can be used in a file name
+var namePattern = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// CompletionRegistry is a source of completion scripts. Registries are listed by precedence:
+// a tool found in several registries is taken from the first one.
+type CompletionRegistry struct {
+	Name string `koanf:"name"` // Name of the registry; "default" is the built-in registry
+	URL  string `koanf:"url"`  // https:// URL, file:// URL or local directory; optional for the default registry
+	TTL  string `koanf:"ttl"`  // How long a downloaded registry is cached (e.g. 24h), 7 days if empty
+}
+
+// TTLDuration returns how long a downloaded copy of the registry is cached, 0 for the default
+func (r CompletionRegistry) TTLDuration() time.Duration {
+	ttl, err := time.ParseDuration(r.TTL)
+	if err != nil || ttl < 0 {
+		return 0
+	}
+	return ttl
+}
+
+// CompletionCacheSettings configures the cache of completion results, for tools that are slow to answer.
+// Results are cached per tool, previous words and directory. Expired results are still
+// returned while they are refreshed in the background (stale-while-revalidate).
+type CompletionCacheSettings struct {
+	Enabled bool              `koanf:"enabled"` // Cache the results of all tools
+	TTL     string            `koanf:"ttl"`     // Default time to live (e.g. 30s), DefaultCompletionCacheTTL if empty
+	Tools   map[string]string `koanf:"tools"`   // Time to live per tool, cached even if not enabled; 0 disables the cache
+}
+
+// TTLFor returns the time to live of the results of a tool, 0 if they are not cached
+func (s CompletionCacheSettings) TTLFor(tool string) time.Duration {
+	value, ok := s.Tools[filepath.Base(tool)]
+	if !ok {
+		if !s.Enabled {
+			return 0
+		}
+		value = s.TTL
+	}
+	if value == "" {
+		return DefaultCompletionCacheTTL
+	}
+
+	ttl, err := time.ParseDuration(value)
+	if err != nil || ttl < 0 {
+		return 0
+	}
+	return ttl
+}
+
+// Validate checks that the durations, registries, plugins and matching mode of the settings are valid
+func (s CompletionSettings) Validate() error {
+	if err := validateTTL(s.Cache.TTL); err != nil {
+		return fmt.Errorf("cache ttl: %w", err)
+	}
+
+	tools := make([]string, 0, len(s.Cache.Tools))
+	for tool := range s.Cache.Tools {
+		tools = append(tools, tool)
+	}
+	sort.Strings(tools)
+	for _, tool := range tools {
+		if err := validateTTL(s.Cache.Tools[tool]); err != nil {
+			return fmt.Errorf("cache ttl of %s: %w", tool, err)
+		}
+	}
+
+	if err := s.Scripts.Sandbox.validate(); err != nil {
+		return fmt.Errorf("scripts sandbox: %w", err)
+	}
+
+	if err := validateMatchMode(s.Match); err != nil {
+		return err
+	}
+
+	names := make(map[string]bool, len(s.Registries))
+	for i, registry := range s.Registries {
+		if err := registry.Validate(); err != nil {
+			return fmt.Errorf("registries[%d]: %w", i, err)
+		}
+		if names[registry.Name] {
+			return fmt.Errorf("registries[%d]: duplicate registry name '%s'", i, registry.Name)
+		}
+		names[registry.Name] = true
+	}
+
+	plugins := make(map[string]bool, len(s.Plugins))
+	for i, plugin := range s.Plugins {
+		if err := plugin.Validate(); err != nil {
+			return fmt.Errorf("plugins[%d]: %w", i, err)
+		}
+		if plugins[plugin.Name] {
+			return fmt.Errorf("plugins[%d]: duplicate plugin name '%s'", i, plugin.Name)
+		}
+		plugins[plugin.Name] = true
+	}
+	return nil
+}
+
+// Validate checks the name, URL and time to live of a registry
+func (r CompletionRegistry) Validate() error {
+	if !namePattern.MatchString(r.Name) {
+		return fmt.Errorf("invalid name '%s' (letters, digits, '-' and '_' only)", r.Name)
+	}
+	if r.URL == "" && r.Name != DefaultCompletionRegistry {
+		return fmt.Errorf("registry '%s' has no url", r.Name)
+	}
+	if scheme, _, found := strings.Cut(r.URL, "://"); found {
+		switch scheme {
+		case "https", "file":
+		default:
+			return fmt.Errorf("registry '%s': unsupported scheme '%s' (expected https or file)", r.Name, scheme)
+		}
+	}
+	if err := validateTTL(r.TTL); err != nil {
+		return fmt.Errorf("registry '%s' ttl: %w", r.Name, err)
+	}
+	return nil
+}
+
+// validateTTL checks that a time to live is empty or a positive duration
+func validateTTL(value string) error {
+	if value == "" {
+		return nil
+	}
+	ttl, err := time.ParseDuration(value)
+	if err != nil {
+		return fmt.Errorf("invalid duration '%s' (expected e.g. 30s, 5m)", value)
+	}
+	if ttl < 0 {
+		return fmt.Errorf("negative duration '%s'", value)
+	}
+	return nil
+}
@@ -0,0 +1,197 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompletionCacheSettings_TTLFor(t *testing.T) {
+	disabled := CompletionCacheSettings{Tools: map[string]string{"helm": "5m"}}
+	assert.Equal(t, 5*time.Minute, disabled.TTLFor("helm"))
+	assert.Equal(t, 5*time.Minute, disabled.TTLFor("/usr/local/bin/helm"))
+	assert.Zero(t, disabled.TTLFor("kubectl"))
+
+	enabled := CompletionCacheSettings{Enabled: true, TTL: "30s", Tools: map[string]string{"git": "0"}}
+	assert.Equal(t, 30*time.Second, enabled.TTLFor("kubectl"))
+	assert.Zero(t, enabled.TTLFor("git"))
+
+	defaults := CompletionCacheSettings{Enabled: true}
+	assert.Equal(t, DefaultCompletionCacheTTL, defaults.TTLFor("kubectl"))
+
+	invalid := CompletionCacheSettings{Enabled: true, TTL: "soon"}
+	assert.Zero(t, invalid.TTLFor("kubectl"))
+}
+
+func TestCompletionSettings_Validate(t *testing.T) {
+	assert.NoError(t, CompletionSettings{}.Validate())
+	assert.NoError(t, CompletionSettings{Cache: CompletionCacheSettings{TTL: "1m30s", Tools: map[string]string{"helm": "0"}}}.Validate())
+
+	err := CompletionSettings{Cache: CompletionCacheSettings{TTL: "soon"}}.Validate()
+	assert.ErrorContains(t, err, "invalid duration 'soon'")
+
+	err = CompletionSettings{Cache: CompletionCacheSettings{Tools: map[string]string{"helm": "-1m"}}}.Validate()
+	assert.ErrorContains(t, err, "helm")
+
+	assert.NoError(t, CompletionSettings{Match: "fuzzy"}.Validate())
+	assert.ErrorContains(t, CompletionSettings{Match: "regex"}.Validate(), "invalid match mode 'regex'")
+}
+
+func TestCompletionSettings_ValidateRegistries(t *testing.T) {
+	valid := CompletionSettings{Registries: []CompletionRegistry{
+		{Name: "corp", URL: "https://tools.example.com/dirvana", TTL: "24h"},
+		{Name: "team", URL: "file:///srv/completions"},
+		{Name: "shared", URL: "/mnt/shared/completions"},
+		{Name: "default"},
+	}}
+	assert.NoError(t, valid.Validate())
+
+	tests := []struct {
+		name     string
+		registry CompletionRegistry
+		want     string
+	}{
+		{"invalid name", CompletionRegistry{Name: "my registry", URL: "/srv"}, "invalid name"},
+		{"missing url", CompletionRegistry{Name: "corp"}, "has no url"},
+		{"unsupported scheme", CompletionRegistry{Name: "corp", URL: "ftp://example.com"}, "unsupported scheme 'ftp'"},
+		{"plain http", CompletionRegistry{Name: "corp", URL: "http://example.com"}, "unsupported scheme 'http'"},
+		{"invalid ttl", CompletionRegistry{Name: "corp", URL: "/srv", TTL: "weekly"}, "invalid duration 'weekly'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CompletionSettings{Registries: []CompletionRegistry{tt.registry}}.Validate()
+			assert.ErrorContains(t, err, tt.want)
+		})
+	}
+
+	duplicate := CompletionSettings{Registries: []CompletionRegistry{{Name: "corp", URL: "/a"}, {Name: "corp", URL: "/b"}}}
+	assert.ErrorContains(t, duplicate.Validate(), "duplicate registry name 'corp'")
+}
+
+func TestCompletionSettings_ValidatePlugins(t *testing.T) {
+	valid := CompletionSettings{Plugins: []CompletionPlugin{
+		{Name: "corp", Path: "/opt/corp/bin/corp-completer", Timeout: "2s"},
+		{Name: "team"},
+	}}
+	assert.NoError(t, valid.Validate())
+
+	err := CompletionSettings{Plugins: []CompletionPlugin{{Name: "my plugin"}}}.Validate()
+	assert.ErrorContains(t, err, "plugins[0]: invalid name 'my plugin'")
+
+	err = CompletionSettings{Plugins: []CompletionPlugin{{Name: "corp", Timeout: "soon"}}}.Validate()
+	assert.ErrorContains(t, err, "invalid duration 'soon'")
+
+	err = CompletionSettings{Plugins: []CompletionPlugin{{Name: "corp"}, {Name: "corp"}}}.Validate()
+	assert.ErrorContains(t, err, "duplicate plugin name 'corp'")
+}
+
+func TestCompletionPlugin_TimeoutDuration(t *testing.T) {
+	assert.Equal(t, 2*time.Second, CompletionPlugin{Timeout: "2s"}.TimeoutDuration())
+	assert.Zero(t, CompletionPlugin{}.TimeoutDuration())
+	assert.Zero(t, CompletionPlugin{Timeout: "soon"}.TimeoutDuration())
+}
+
+func TestCompletionRegistry_TTLDuration(t *testing.T) {
+	assert.Equal(t, 24*time.Hour, CompletionRegistry{TTL: "24h"}.TTLDuration())
+	assert.Zero(t, CompletionRegistry{}.TTLDuration())
+	assert.Zero(t, CompletionRegistry{TTL: "weekly"}.TTLDuration())
+}
+
+func TestCompletionSandboxSettings_Validate(t *testing.T) {
+	valid := CompletionSettings{Scripts: CompletionScriptSettings{Sandbox: CompletionSandboxSettings{
+		Timeout:   "2s",
+		MaxOutput: 4096,
+		Env:       []string{"KUBECONFIG"},
+		Tools:     map[string][]string{"docker": {"DOCKER_HOST", "HOME"}},
+	}}}
+	assert.NoError(t, valid.Validate())
+
+	tests := []struct {
+		name    string
+		sandbox CompletionSandboxSettings
+		want    string
+	}{
+		{"invalid timeout", CompletionSandboxSettings{Timeout: "soon"}, "invalid duration 'soon'"},
+		{"negative max output", CompletionSandboxSettings{MaxOutput: -1}, "negative max_output"},
+		{"invalid variable", CompletionSandboxSettings{Env: []string{"MY-VAR"}}, "invalid variable name 'MY-VAR'"},
+		{"invalid tool variable", CompletionSandboxSettings{Tools: map[string][]string{"docker": {"1HOST"}}}, "invalid variable name '1HOST' for docker"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CompletionSettings{Scripts: CompletionScriptSettings{Sandbox: tt.sandbox}}.Validate()
+			assert.ErrorContains(t, err, "scripts sandbox")
+			assert.ErrorContains(t, err, tt.want)
+		})
+	}
+}
+
+func TestCompletionSandboxSettings_TimeoutDuration(t *testing.T) {
+	assert.Equal(t, 2*time.Second, CompletionSandboxSettings{Timeout: "2s"}.TimeoutDuration())
+	assert.Zero(t, CompletionSandboxSettings{}.TimeoutDuration())
+	assert.Zero(t, CompletionSandboxSettings{Timeout: "soon"}.TimeoutDuration())
+}
+
+func TestConfig_LoadCompletionSettings(t *testing.T) {
+	configHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+
+	loader := New()
+	cfg, err := loader.LoadGlobal()
+	require.NoError(t, err)
+	assert.Nil(t, cfg)
+
+	globalPath := filepath.Join(configHome, "dirvana", GlobalConfigName)
+	require.NoError(t, os.MkdirAll(filepath.Dir(globalPath), 0755))
+	require.NoError(t, os.WriteFile(globalPath, []byte(`completion:
+  cache:
+    enabled: true
+    ttl: 30s
+    tools:
+      helm: 5m
+      terraform: 10m
+  registries:
+    - name: corp
+      url: https://tools.example.com/dirvana
+      ttl: 24h
+    - name: default
+  scripts:
+    strict: true
+    sandbox:
+      read_only: true
+      timeout: 2s
+      max_output: 4096
+      env: [KUBECONFIG]
+      tools:
+        docker: [DOCKER_HOST]
+  plugins:
+    - name: corp
+      path: /opt/corp/bin/corp-completer
+      timeout: 2s
+  match: fuzzy
+`), 0644))
+
+	cfg, err = loader.LoadGlobal()
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+	assert.True(t, cfg.Completion.Cache.Enabled)
+	assert.Equal(t, "30s", cfg.Completion.Cache.TTL)
+	assert.Equal(t, map[string]string{"helm": "5m", "terraform": "10m"}, cfg.Completion.Cache.Tools)
+	assert.Equal(t, []CompletionRegistry{
+		{Name: "corp", URL: "https://tools.example.com/dirvana", TTL: "24h"},
+		{Name: "default"},
+	}, cfg.Completion.Registries)
+	assert.True(t, cfg.Completion.Scripts.Strict)
+	assert.Equal(t, CompletionSandboxSettings{
+		ReadOnly:  true,
+		Timeout:   "2s",
+		MaxOutput: 4096,
+		Env:       []string{"KUBECONFIG"},
+		Tools:     map[string][]string{"docker": {"DOCKER_HOST"}},
+	}, cfg.Completion.Scripts.Sandbox)
+	assert.Equal(t, []CompletionPlugin{{Name: "corp", Path: "/opt/corp/bin/corp-completer", Timeout: "2s"}}, cfg.Completion.Plugins)
+	assert.Equal(t, "fuzzy", cfg.Completion.Match)
+}
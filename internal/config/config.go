@@ -8,6 +8,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 	"sync"
 	"text/template"
@@ -58,8 +60,39 @@ type EnvVar struct {
 
 // CompletionConfig represents shell completion configuration for an alias
 type CompletionConfig struct {
-	Bash string `koanf:"bash"` // Bash completion code
-	Zsh  string `koanf:"zsh"`  // Zsh completion code
+	Bash    string           `koanf:"bash" json:"bash,omitempty"`       // Bash completion code (deprecated, not used)
+	Zsh     string           `koanf:"zsh" json:"zsh,omitempty"`         // Zsh completion code (deprecated, not used)
+	Values  []CompletionItem `koanf:"values" json:"values,omitempty"`   // Static candidates
+	Command string           `koanf:"command" json:"command,omitempty"` // Command printing candidates, one per line
+	Files   []string         `koanf:"files" json:"files,omitempty"`     // Glob patterns of files to complete
+	Dirs    bool             `koanf:"dirs" json:"dirs,omitempty"`       // Complete directories
+	Match   string           `koanf:"match" json:"match,omitempty"`     // Matching mode of suggestions, the global one if empty
+}
+
+// CompletionItem is a static completion candidate with an optional description
+type CompletionItem struct {
+	Value       string `koanf:"value" json:"value"`
+	Description string `koanf:"description" json:"description,omitempty"`
+}
+
+// HasSource reports whether the completion declares custom candidates
+func (c CompletionConfig) HasSource() bool {
+	return len(c.Values) > 0 || c.Command != "" || len(c.Files) > 0 || c.Dirs
+}
+
+// Validate checks that custom completion sources are well-formed
+func (c CompletionConfig) Validate() error {
+	for i, item := range c.Values {
+		if item.Value == "" {
+			return fmt.Errorf("completion value %d is empty", i)
+		}
+	}
+	for _, pattern := range c.Files {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid files pattern '%s': %w", pattern, err)
+		}
+	}
+	return validateMatchMode(c.Match)
 }
 
 // When represents conditions that must be met for an alias to execute
@@ -75,22 +108,120 @@ type When struct {
 	Any []When `koanf:"any"` // At least one condition must be true (OR)
 }
 
+// Alias execution modes
+const (
+	ExecModeAuto   = "auto"   // Direct when the command has no shell metacharacters, shell otherwise
+	ExecModeShell  = "shell"  // Always run through the user's shell
+	ExecModeDirect = "direct" // Split the command into argv and execute the binary directly
+)
+
 // AliasConfig represents an alias with optional completion override
 type AliasConfig struct {
-	Command    string      // The command to execute
-	Completion interface{} // Can be: string (inherit), false (disable), or CompletionConfig object
-	When       *When       // Conditions that must be met for the alias to execute
-	Else       string      // Fallback command if conditions are not met
+	Command    string            // The command to execute
+	Completion interface{}       // Can be: string (inherit), false (disable), or CompletionConfig object
+	When       *When             // Conditions that must be met for the alias to execute
+	Else       string            // Fallback command if conditions are not met
+	Exec       string            // Execution mode: auto (default), shell or direct
+	Env        map[string]string // Environment variables set only for this command
+	Cwd        string            // Directory the command runs from
+	Args       AliasArgs         // Default arguments around the user's arguments
+	Template   bool              // Command is a template rendered at execution time
+	ConfigDir  string            // Directory of the config defining a template alias
+	Params     []AliasParam      // Typed positional parameters
+}
+
+// Alias parameter types
+const (
+	ParamTypeString = "string"
+	ParamTypeEnum   = "enum"
+	ParamTypeFile   = "file"
+	ParamTypeDir    = "dir"
+	ParamTypeInt    = "int"
+)
+
+// AliasParam describes a positional alias parameter, used to validate
+// arguments, generate --help and complete values
+type AliasParam struct {
+	Name        string   `json:"name"`
+	Type        string   `json:"type,omitempty"`    // string (default), enum, file, dir or int
+	Values      []string `json:"values,omitempty"`  // Allowed values for enum parameters
+	Pattern     string   `json:"pattern,omitempty"` // Glob filtering file completions (e.g. *.yaml)
+	Required    bool     `json:"required,omitempty"`
+	Default     string   `json:"default,omitempty"`
+	Description string   `json:"description,omitempty"`
+}
+
+// Validate checks that a parameter definition is consistent
+func (p AliasParam) Validate() error {
+	if p.Name == "" {
+		return fmt.Errorf("parameter name is required")
+	}
+
+	switch p.Type {
+	case "", ParamTypeString, ParamTypeFile, ParamTypeDir:
+	case ParamTypeEnum:
+		if len(p.Values) == 0 {
+			return fmt.Errorf("enum parameter '%s' requires values", p.Name)
+		}
+		if p.Default != "" && !slices.Contains(p.Values, p.Default) {
+			return fmt.Errorf("default value '%s' of parameter '%s' is not one of its values", p.Default, p.Name)
+		}
+	case ParamTypeInt:
+		if p.Default != "" {
+			if _, err := strconv.Atoi(p.Default); err != nil {
+				return fmt.Errorf("default value '%s' of parameter '%s' is not an integer", p.Default, p.Name)
+			}
+		}
+	default:
+		return fmt.Errorf("invalid type '%s' for parameter '%s' (expected string, enum, file, dir or int)", p.Type, p.Name)
+	}
+
+	if p.Required && p.Default != "" {
+		return fmt.Errorf("parameter '%s' can't be both required and have a default", p.Name)
+	}
+
+	return nil
+}
+
+// TypeName returns the parameter type, defaulting to string
+func (p AliasParam) TypeName() string {
+	if p.Type == "" {
+		return ParamTypeString
+	}
+	return p.Type
+}
+
+// aliasConfigDirKey stores the config directory of template aliases, which
+// are not expanded at load time (internal key, not part of the schema)
+const aliasConfigDirKey = "__dirvana_dir"
+
+// AliasArgs holds default arguments added by 'dirvana exec'
+type AliasArgs struct {
+	Prepend []string // Inserted before the user's arguments
+	Append  []string // Added after the user's arguments
+}
+
+// IsValidExecMode checks if an alias execution mode is supported (empty means auto)
+func IsValidExecMode(mode string) bool {
+	switch mode {
+	case "", ExecModeAuto, ExecModeShell, ExecModeDirect:
+		return true
+	default:
+		return false
+	}
 }
 
 // Config represents a dirvana configuration
 type Config struct {
-	Aliases      map[string]interface{} `koanf:"aliases"` // Can be string or AliasConfig struct
-	Functions    map[string]string      `koanf:"functions"`
-	Env          map[string]interface{} `koanf:"env"` // Can be string or EnvVar struct
-	LocalOnly    bool                   `koanf:"local_only"`
-	IgnoreGlobal bool                   `koanf:"ignore_global"`
-	ConfigDir    string                 // Directory containing the config file (not persisted in YAML)
+	Aliases             map[string]interface{} `koanf:"aliases"` // Can be string or AliasConfig struct
+	Functions           map[string]string      `koanf:"functions"`
+	FunctionCompletions map[string]interface{} `koanf:"-"`   // Completion of functions: string (inherit), false (disable) or CompletionConfig
+	Env                 map[string]interface{} `koanf:"env"` // Can be string or EnvVar struct
+	LocalOnly           bool                   `koanf:"local_only"`
+	IgnoreGlobal        bool                   `koanf:"ignore_global"`
+	Shims               bool                   `koanf:"shims"`      // Generate executable shims for aliases and put them on PATH
+	Completion          CompletionSettings     `koanf:"completion"` // Completion engine settings (global config only)
+	ConfigDir           string                 // Directory containing the config file (not persisted in YAML)
 }
 
 // expandTemplate expands a template string using Sprig functions and Dirvana variables
@@ -151,12 +282,18 @@ func (c *Config) expandAliasVars() error {
 		case string:
 			c.Aliases[name] = c.expandTemplate(v)
 		case map[string]interface{}:
-			if cmd, ok := v["command"].(string); ok {
-				v["command"] = c.expandTemplate(cmd)
-			}
-			if elseCmd, ok := v["else"].(string); ok {
-				v["else"] = c.expandTemplate(elseCmd)
+			if isTemplate, _ := v["template"].(bool); isTemplate {
+				// Commands are rendered by 'dirvana exec', which still needs the config directory
+				v[aliasConfigDirKey] = c.ConfigDir
+			} else {
+				if cmd, ok := v["command"].(string); ok {
+					v["command"] = c.expandTemplate(cmd)
+				}
+				if elseCmd, ok := v["else"].(string); ok {
+					v["else"] = c.expandTemplate(elseCmd)
+				}
 			}
+			c.expandAliasExecOptions(v)
 			// Expand conditions
 			if whenData, ok := v["when"].(map[string]interface{}); ok {
 				if err := c.expandWhenVars(whenData); err != nil {
@@ -168,6 +305,39 @@ func (c *Config) expandAliasVars() error {
 	return nil
 }
 
+// expandAliasExecOptions expands template variables in alias env, cwd and args
+func (c *Config) expandAliasExecOptions(v map[string]interface{}) {
+	if envData, ok := v["env"].(map[string]interface{}); ok {
+		for key, value := range envData {
+			if s, ok := value.(string); ok {
+				envData[key] = c.expandTemplate(s)
+			}
+		}
+	}
+
+	if cwd, ok := v["cwd"].(string); ok {
+		v["cwd"] = c.expandTemplate(cwd)
+	}
+
+	expandList := func(items []interface{}) {
+		for i, item := range items {
+			if s, ok := item.(string); ok {
+				items[i] = c.expandTemplate(s)
+			}
+		}
+	}
+	switch args := v["args"].(type) {
+	case []interface{}:
+		expandList(args)
+	case map[string]interface{}:
+		for _, key := range []string{"prepend", "append"} {
+			if items, ok := args[key].([]interface{}); ok {
+				expandList(items)
+			}
+		}
+	}
+}
+
 // expandFunctionVars expands template variables in functions
 func (c *Config) expandFunctionVars() error {
 	for name, body := range c.Functions {
@@ -248,26 +418,7 @@ func (c *Config) GetAliases() map[string]AliasConfig {
 			}
 
 			if comp, exists := v["completion"]; exists {
-				switch c := comp.(type) {
-				case string:
-					// Inherit from command: "completion: git"
-					alias.Completion = c
-				case bool:
-					// Disable: "completion: false"
-					if !c {
-						alias.Completion = false
-					}
-				case map[string]interface{}:
-					// Custom completion with bash/zsh
-					compCfg := CompletionConfig{}
-					if bash, ok := c["bash"].(string); ok {
-						compCfg.Bash = bash
-					}
-					if zsh, ok := c["zsh"].(string); ok {
-						compCfg.Zsh = zsh
-					}
-					alias.Completion = compCfg
-				}
+				alias.Completion = parseCompletion(comp)
 			}
 
 			// Parse 'when' conditions
@@ -282,6 +433,8 @@ func (c *Config) GetAliases() map[string]AliasConfig {
 				alias.Else = elseCmd
 			}
 
+			parseAliasExecOptions(&alias, v)
+
 			result[name] = alias
 		}
 	}
@@ -289,6 +442,176 @@ func (c *Config) GetAliases() map[string]AliasConfig {
 	return result
 }
 
+// parseAliasExecOptions parses the options applied by 'dirvana exec' (exec, env, cwd, args, template, params)
+func parseAliasExecOptions(alias *AliasConfig, v map[string]interface{}) {
+	if mode, ok := v["exec"].(string); ok {
+		alias.Exec = mode
+	}
+
+	if envData, ok := v["env"].(map[string]interface{}); ok {
+		alias.Env = make(map[string]string, len(envData))
+		for key, value := range envData {
+			alias.Env[key] = fmt.Sprintf("%v", value)
+		}
+	}
+
+	if cwd, ok := v["cwd"].(string); ok {
+		alias.Cwd = cwd
+	}
+
+	if isTemplate, ok := v["template"].(bool); ok {
+		alias.Template = isTemplate
+	}
+	if configDir, ok := v[aliasConfigDirKey].(string); ok {
+		alias.ConfigDir = configDir
+	}
+
+	if params, ok := v["params"].([]interface{}); ok {
+		alias.Params = parseAliasParams(params)
+	}
+
+	// args: [...] is a shorthand for args: {prepend: [...]}
+	switch args := v["args"].(type) {
+	case []interface{}:
+		alias.Args.Prepend = toStringSlice(args)
+	case map[string]interface{}:
+		if prepend, ok := args["prepend"].([]interface{}); ok {
+			alias.Args.Prepend = toStringSlice(prepend)
+		}
+		if appendArgs, ok := args["append"].([]interface{}); ok {
+			alias.Args.Append = toStringSlice(appendArgs)
+		}
+	}
+}
+
+// parseAliasParams parses the params list of an alias
+func parseAliasParams(items []interface{}) []AliasParam {
+	params := make([]AliasParam, 0, len(items))
+	for _, item := range items {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		param := AliasParam{}
+		param.Name, _ = m["name"].(string)
+		param.Type, _ = m["type"].(string)
+		param.Pattern, _ = m["pattern"].(string)
+		param.Required, _ = m["required"].(bool)
+		param.Description, _ = m["description"].(string)
+		if values, ok := m["values"].([]interface{}); ok {
+			param.Values = toStringSlice(values)
+		}
+		if def, ok := m["default"]; ok && def != nil {
+			param.Default = fmt.Sprintf("%v", def)
+		}
+
+		params = append(params, param)
+	}
+	return params
+}
+
+// toStringSlice converts a YAML list to strings
+func toStringSlice(items []interface{}) []string {
+	result := make([]string, 0, len(items))
+	for _, item := range items {
+		result = append(result, fmt.Sprintf("%v", item))
+	}
+	return result
+}
+
+// parseCompletion parses a completion setting of an alias or a function:
+// a command to inherit from, false to disable, or custom completion sources
+func parseCompletion(comp interface{}) interface{} {
+	switch c := comp.(type) {
+	case string:
+		// Inherit from command: "completion: git"
+		return c
+	case bool:
+		// Disable: "completion: false"
+		if !c {
+			return false
+		}
+	case map[string]interface{}:
+		// Custom completion sources
+		return parseCompletionConfig(c)
+	}
+	return nil
+}
+
+// parseFunctions splits raw function definitions into their bodies and completion settings.
+// A function is either its body or an object with a body and a completion.
+func parseFunctions(raw map[string]interface{}) (functions map[string]string, completions map[string]interface{}, err error) {
+	functions = make(map[string]string, len(raw))
+	completions = make(map[string]interface{})
+
+	for name, value := range raw {
+		v, ok := value.(map[string]interface{})
+		if !ok {
+			functions[name] = fmt.Sprintf("%v", value)
+			continue
+		}
+
+		body, ok := v["body"].(string)
+		if !ok {
+			return nil, nil, fmt.Errorf("function %s: missing body", name)
+		}
+		functions[name] = body
+		if comp, exists := v["completion"]; exists {
+			if completion := parseCompletion(comp); completion != nil {
+				completions[name] = completion
+			}
+		}
+	}
+
+	return functions, completions, nil
+}
+
+// parseCompletionConfig parses a custom completion object
+// (values, command, files, dirs, the matching mode and the legacy bash/zsh code)
+func parseCompletionConfig(c map[string]interface{}) CompletionConfig {
+	compCfg := CompletionConfig{}
+	if bash, ok := c["bash"].(string); ok {
+		compCfg.Bash = bash
+	}
+	if zsh, ok := c["zsh"].(string); ok {
+		compCfg.Zsh = zsh
+	}
+	if command, ok := c["command"].(string); ok {
+		compCfg.Command = command
+	}
+	if match, ok := c["match"].(string); ok {
+		compCfg.Match = match
+	}
+	if dirs, ok := c["dirs"].(bool); ok {
+		compCfg.Dirs = dirs
+	}
+
+	// files: a single glob or a list of globs
+	switch files := c["files"].(type) {
+	case string:
+		compCfg.Files = []string{files}
+	case []interface{}:
+		compCfg.Files = toStringSlice(files)
+	}
+
+	// values: a list of strings or of {value, description} objects
+	if values, ok := c["values"].([]interface{}); ok {
+		for _, item := range values {
+			switch v := item.(type) {
+			case map[string]interface{}:
+				value, _ := v["value"].(string)
+				description, _ := v["description"].(string)
+				compCfg.Values = append(compCfg.Values, CompletionItem{Value: value, Description: description})
+			default:
+				compCfg.Values = append(compCfg.Values, CompletionItem{Value: fmt.Sprintf("%v", v)})
+			}
+		}
+	}
+
+	return compCfg
+}
+
 // parseWhen recursively parses a when condition map into a When struct
 func parseWhen(m map[string]interface{}) *When {
 	when := &When{}
@@ -490,10 +813,19 @@ func (l *Loader) Load(path string) (*Config, error) {
 		Env:       make(map[string]interface{}),
 	}
 
+	// Functions can be objects with a body and a completion: parse them separately
+	rawFunctions, _ := k.Get("functions").(map[string]interface{})
+	k.Delete("functions")
+
 	if err := k.Unmarshal("", cfg); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
 	}
 
+	cfg.Functions, cfg.FunctionCompletions, err = parseFunctions(rawFunctions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse functions: %w", err)
+	}
+
 	// Store the config directory for template expansion
 	cfg.ConfigDir = filepath.Dir(path)
 
@@ -568,11 +900,14 @@ func Merge(parent, child *Config) *Config {
 	}
 
 	merged := &Config{
-		Aliases:      make(map[string]interface{}),
-		Functions:    make(map[string]string),
-		Env:          make(map[string]interface{}),
-		LocalOnly:    child.LocalOnly,
-		IgnoreGlobal: child.IgnoreGlobal,
+		Aliases:             make(map[string]interface{}),
+		Functions:           make(map[string]string),
+		FunctionCompletions: make(map[string]interface{}),
+		Env:                 make(map[string]interface{}),
+		LocalOnly:           child.LocalOnly,
+		IgnoreGlobal:        child.IgnoreGlobal,
+		// Shims enabled anywhere in the hierarchy (e.g. globally) stay enabled
+		Shims: parent.Shims || child.Shims,
 	}
 
 	// Merge aliases (parent first, child overrides)
@@ -583,12 +918,19 @@ func Merge(parent, child *Config) *Config {
 		merged.Aliases[k] = v
 	}
 
-	// Merge functions
+	// Merge functions, a redefined function doesn't keep the completion of the parent
 	for k, v := range parent.Functions {
 		merged.Functions[k] = v
+		if comp, ok := parent.FunctionCompletions[k]; ok {
+			merged.FunctionCompletions[k] = comp
+		}
 	}
 	for k, v := range child.Functions {
 		merged.Functions[k] = v
+		delete(merged.FunctionCompletions, k)
+		if comp, ok := child.FunctionCompletions[k]; ok {
+			merged.FunctionCompletions[k] = comp
+		}
 	}
 
 	// Merge env vars (interface{} type)
@@ -618,6 +960,19 @@ func GetGlobalConfigPath() (string, error) {
 	return filepath.Join(configHome, "dirvana", GlobalConfigName), nil
 }
 
+// LoadGlobal loads the global config, or returns nil if there is none
+func (l *Loader) LoadGlobal() (*Config, error) {
+	globalPath, err := GetGlobalConfigPath()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(globalPath); os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	return l.Load(globalPath)
+}
+
 // FindConfigFiles searches for config files from current dir up to root
 // Returns paths in order from root to leaf (for proper merging)
 func FindConfigFiles(startDir string) ([]string, error) {
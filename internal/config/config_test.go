@@ -891,6 +891,72 @@ func TestConfig_GetAliases_WithCustomCompletion(t *testing.T) {
 	assert.Equal(t, "compdef _mt mt", compCfg.Zsh)
 }
 
+func TestConfig_GetAliases_WithCompletionSources(t *testing.T) {
+	cfg := &Config{
+		Aliases: map[string]interface{}{
+			"deploy": map[string]interface{}{
+				"command": "./deploy.sh",
+				"completion": map[string]interface{}{
+					"values": []interface{}{
+						"dev",
+						map[string]interface{}{"value": "prod", "description": "Production"},
+					},
+					"command": "ls envs",
+					"files":   "*.yml",
+					"dirs":    true,
+				},
+			},
+			"apply": map[string]interface{}{
+				"command": "kubectl apply -f",
+				"completion": map[string]interface{}{
+					"files": []interface{}{"*.yaml", "*.yml"},
+				},
+			},
+			"k": map[string]interface{}{
+				"command": "kubectl",
+				"completion": map[string]interface{}{
+					"match": "fuzzy",
+				},
+			},
+		},
+	}
+
+	aliases := cfg.GetAliases()
+
+	compCfg, ok := aliases["deploy"].Completion.(CompletionConfig)
+	require.True(t, ok)
+	assert.Equal(t, []CompletionItem{{Value: "dev"}, {Value: "prod", Description: "Production"}}, compCfg.Values)
+	assert.Equal(t, "ls envs", compCfg.Command)
+	assert.Equal(t, []string{"*.yml"}, compCfg.Files)
+	assert.True(t, compCfg.Dirs)
+	assert.True(t, compCfg.HasSource())
+
+	compCfg, ok = aliases["apply"].Completion.(CompletionConfig)
+	require.True(t, ok)
+	assert.Equal(t, []string{"*.yaml", "*.yml"}, compCfg.Files)
+
+	// A matching mode alone keeps the completion of the tool
+	compCfg, ok = aliases["k"].Completion.(CompletionConfig)
+	require.True(t, ok)
+	assert.Equal(t, "fuzzy", compCfg.Match)
+	assert.False(t, compCfg.HasSource())
+}
+
+func TestCompletionConfig_HasSource(t *testing.T) {
+	assert.False(t, CompletionConfig{}.HasSource())
+	assert.False(t, CompletionConfig{Bash: "complete -W 'a b' x"}.HasSource())
+	assert.True(t, CompletionConfig{Dirs: true}.HasSource())
+	assert.True(t, CompletionConfig{Command: "echo a"}.HasSource())
+}
+
+func TestCompletionConfig_Validate(t *testing.T) {
+	assert.NoError(t, CompletionConfig{Values: []CompletionItem{{Value: "a"}}, Files: []string{"*.go"}}.Validate())
+	assert.Error(t, CompletionConfig{Values: []CompletionItem{{Description: "no value"}}}.Validate())
+	assert.Error(t, CompletionConfig{Files: []string{"[a"}}.Validate())
+	assert.NoError(t, CompletionConfig{Match: "substring"}.Validate())
+	assert.ErrorContains(t, CompletionConfig{Match: "exact"}.Validate(), "invalid match mode 'exact'")
+}
+
 func TestConfig_GetAliases_WithConditionalSimple(t *testing.T) {
 	cfg := &Config{
 		Aliases: map[string]interface{}{
@@ -1062,3 +1128,182 @@ aliases:
 	assert.NotNil(t, deployAlias.When)
 	assert.Equal(t, ".env", deployAlias.When.File)
 }
+
+func TestConfig_MergeShims(t *testing.T) {
+	// Shims enabled anywhere in the hierarchy stay enabled
+	merged := Merge(&Config{Shims: true}, &Config{})
+	assert.True(t, merged.Shims)
+
+	merged = Merge(&Config{}, &Config{Shims: true})
+	assert.True(t, merged.Shims)
+
+	merged = Merge(&Config{}, &Config{})
+	assert.False(t, merged.Shims)
+}
+
+func TestConfig_LoadFunctionsWithCompletion(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, ".dirvana.yml")
+
+	yamlContent := `
+functions:
+  greet: echo "Hello, $1!"
+  gsw:
+    body: git switch "$@"
+    completion: git switch
+  deploy:
+    body: ./deploy.sh "$1"
+    completion:
+      values: [staging, prod]
+  quiet:
+    body: echo quiet
+    completion: false
+`
+	require.NoError(t, os.WriteFile(configPath, []byte(yamlContent), 0644))
+
+	cfg, err := New().Load(configPath)
+	require.NoError(t, err)
+
+	assert.Equal(t, `echo "Hello, $1!"`, cfg.Functions["greet"])
+	assert.Equal(t, `git switch "$@"`, cfg.Functions["gsw"])
+	assert.Equal(t, `./deploy.sh "$1"`, cfg.Functions["deploy"])
+
+	assert.NotContains(t, cfg.FunctionCompletions, "greet")
+	assert.Equal(t, "git switch", cfg.FunctionCompletions["gsw"])
+	assert.Equal(t, false, cfg.FunctionCompletions["quiet"])
+	compCfg, ok := cfg.FunctionCompletions["deploy"].(CompletionConfig)
+	require.True(t, ok)
+	assert.Equal(t, []CompletionItem{{Value: "staging"}, {Value: "prod"}}, compCfg.Values)
+}
+
+func TestConfig_LoadFunctionWithoutBody(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, ".dirvana.yml")
+
+	yamlContent := `
+functions:
+  gsw:
+    completion: git switch
+`
+	require.NoError(t, os.WriteFile(configPath, []byte(yamlContent), 0644))
+
+	_, err := New().Load(configPath)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "function gsw: missing body")
+}
+
+func TestConfig_MergeFunctionCompletions(t *testing.T) {
+	parent := &Config{
+		Functions:           map[string]string{"gsw": "git switch", "gco": "git checkout"},
+		FunctionCompletions: map[string]interface{}{"gsw": "git", "gco": "git"},
+	}
+	child := &Config{
+		Functions: map[string]string{"gsw": "git switch -c"},
+	}
+
+	merged := Merge(parent, child)
+
+	// A redefined function doesn't inherit the completion of the parent
+	assert.NotContains(t, merged.FunctionCompletions, "gsw")
+	assert.Equal(t, "git", merged.FunctionCompletions["gco"])
+}
+
+func TestConfig_GetAliasesExecMode(t *testing.T) {
+	cfg := &Config{
+		Aliases: map[string]interface{}{
+			"k":  map[string]interface{}{"command": "kubectl", "exec": "direct"},
+			"ll": "ls -la",
+		},
+	}
+
+	aliases := cfg.GetAliases()
+	assert.Equal(t, ExecModeDirect, aliases["k"].Exec)
+	assert.Empty(t, aliases["ll"].Exec)
+	assert.True(t, IsValidExecMode(aliases["ll"].Exec))
+	assert.False(t, IsValidExecMode("sometimes"))
+}
+
+func TestConfig_GetAliasesExecOptions(t *testing.T) {
+	cfg := &Config{
+		Aliases: map[string]interface{}{
+			"k": map[string]interface{}{
+				"command": "kubectl",
+				"env":     map[string]interface{}{"KUBECONFIG": "/tmp/kubeconfig", "RETRIES": 3},
+				"cwd":     "/srv",
+				"args":    []interface{}{"--context", "prod"},
+			},
+			"tf": map[string]interface{}{
+				"command": "terraform",
+				"args": map[string]interface{}{
+					"prepend": []interface{}{"-chdir=infra"},
+					"append":  []interface{}{"-no-color"},
+				},
+			},
+		},
+	}
+
+	aliases := cfg.GetAliases()
+
+	assert.Equal(t, map[string]string{"KUBECONFIG": "/tmp/kubeconfig", "RETRIES": "3"}, aliases["k"].Env)
+	assert.Equal(t, "/srv", aliases["k"].Cwd)
+	assert.Equal(t, []string{"--context", "prod"}, aliases["k"].Args.Prepend)
+	assert.Empty(t, aliases["k"].Args.Append)
+
+	assert.Equal(t, []string{"-chdir=infra"}, aliases["tf"].Args.Prepend)
+	assert.Equal(t, []string{"-no-color"}, aliases["tf"].Args.Append)
+}
+
+func TestConfig_GetAliasesParams(t *testing.T) {
+	cfg := &Config{
+		Aliases: map[string]interface{}{
+			"deploy": map[string]interface{}{
+				"command": "helm upgrade",
+				"params": []interface{}{
+					map[string]interface{}{
+						"name":        "env",
+						"type":        "enum",
+						"values":      []interface{}{"dev", "prod"},
+						"required":    true,
+						"description": "Target environment",
+					},
+					map[string]interface{}{"name": "replicas", "type": "int", "default": 2},
+					"ignored",
+				},
+			},
+		},
+	}
+
+	params := cfg.GetAliases()["deploy"].Params
+	require.Len(t, params, 2)
+	assert.Equal(t, AliasParam{Name: "env", Type: ParamTypeEnum, Values: []string{"dev", "prod"}, Required: true, Description: "Target environment"}, params[0])
+	assert.Equal(t, AliasParam{Name: "replicas", Type: ParamTypeInt, Default: "2"}, params[1])
+}
+
+func TestAliasParam_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		param   AliasParam
+		wantErr string
+	}{
+		{"valid string", AliasParam{Name: "name"}, ""},
+		{"valid enum", AliasParam{Name: "env", Type: ParamTypeEnum, Values: []string{"dev"}, Default: "dev"}, ""},
+		{"missing name", AliasParam{}, "name is required"},
+		{"enum without values", AliasParam{Name: "env", Type: ParamTypeEnum}, "requires values"},
+		{"enum default not in values", AliasParam{Name: "env", Type: ParamTypeEnum, Values: []string{"dev"}, Default: "prod"}, "not one of its values"},
+		{"int default", AliasParam{Name: "n", Type: ParamTypeInt, Default: "x"}, "not an integer"},
+		{"unknown type", AliasParam{Name: "n", Type: "float"}, "invalid type"},
+		{"required with default", AliasParam{Name: "n", Required: true, Default: "x"}, "both required"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.param.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+			}
+		})
+	}
+}
@@ -169,6 +169,9 @@ func GetConfigDetails(merged *Config, authMgr *auth.Auth, currentDir string) *De
 	if merged.IgnoreGlobal {
 		details.Flags = append(details.Flags, "ignore_global")
 	}
+	if merged.Shims {
+		details.Flags = append(details.Flags, "shims")
+	}
 
 	return details
 }
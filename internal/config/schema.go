@@ -66,6 +66,7 @@ func ValidateWithSchema(path string, content []byte) (*ValidationResult, error)
 			"env":           cfg.Env,
 			"local_only":    cfg.LocalOnly,
 			"ignore_global": cfg.IgnoreGlobal,
+			"shims":         cfg.Shims,
 		}
 	default:
 		return nil, fmt.Errorf("unsupported file format")
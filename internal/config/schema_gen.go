@@ -13,11 +13,59 @@ import (
 
 // SchemaConfig represents the root configuration for schema generation
 type SchemaConfig struct {
-	Aliases      map[string]AliasValue `json:"aliases,omitempty" jsonschema:"description=Shell aliases - shortcuts for common commands"`
-	Functions    map[string]string     `json:"functions,omitempty" jsonschema:"description=Shell functions - reusable command sequences"`
-	Env          map[string]EnvValue   `json:"env,omitempty" jsonschema:"description=Environment variables (static or dynamic via shell commands)"`
-	LocalOnly    bool                  `json:"local_only,omitempty" jsonschema:"description=If true only use this directory's config (don't merge with parent configs),default=false"`
-	IgnoreGlobal bool                  `json:"ignore_global,omitempty" jsonschema:"description=If true ignore global config (start fresh from this directory),default=false"`
+	Aliases      map[string]AliasValue    `json:"aliases,omitempty" jsonschema:"description=Shell aliases - shortcuts for common commands"`
+	Functions    map[string]FunctionValue `json:"functions,omitempty" jsonschema:"description=Shell functions - reusable command sequences"`
+	Env          map[string]EnvValue      `json:"env,omitempty" jsonschema:"description=Environment variables (static or dynamic via shell commands)"`
+	LocalOnly    bool                     `json:"local_only,omitempty" jsonschema:"description=If true only use this directory's config (don't merge with parent configs),default=false"`
+	IgnoreGlobal bool                     `json:"ignore_global,omitempty" jsonschema:"description=If true ignore global config (start fresh from this directory),default=false"`
+	Shims        bool                     `json:"shims,omitempty" jsonschema:"description=If true generate executable shims for aliases and add them to PATH (for scripts/Makefiles/IDEs),default=false"`
+	Completion   *CompletionSettings      `json:"completion,omitempty" jsonschema:"description=Completion engine settings (only read from the global config)"`
+}
+
+// CompletionSettings configures the completion engine
+type CompletionSettings struct {
+	Cache      *CompletionCacheSettings  `json:"cache,omitempty" jsonschema:"description=Cache of completion results for tools slow to answer"`
+	Registries []CompletionRegistry      `json:"registries,omitempty" jsonschema:"description=Registries of completion scripts by precedence (a tool is taken from the first registry that has it); the default registry is used last unless listed"`
+	Scripts    *CompletionScriptSettings `json:"scripts,omitempty" jsonschema:"description=Completion scripts downloaded from registries"`
+	Plugins    []CompletionPlugin        `json:"plugins,omitempty" jsonschema:"description=External completers speaking the JSON completion protocol; executables named dirvana-completer-<name> on PATH are found without being declared"`
+	Match      string                    `json:"match,omitempty" jsonschema:"enum=prefix,enum=ignore-case,enum=substring,enum=fuzzy,default=prefix,description=How suggestions are matched against the word being completed: prefix; ignore-case (prefix ignoring case); substring or fuzzy (characters in order; ranked by score)"`
+}
+
+// CompletionScriptSettings configures the bash completion scripts downloaded from registries
+type CompletionScriptSettings struct {
+	Strict  bool                       `json:"strict,omitempty" jsonschema:"description=If true refuse scripts without a checksum in their registry and scripts modified since they were installed,default=false"`
+	Sandbox *CompletionSandboxSettings `json:"sandbox,omitempty" jsonschema:"description=How bash completion scripts are run: minimal environment and temporary HOME by default"`
+}
+
+// CompletionSandboxSettings configures how bash completion scripts are run
+type CompletionSandboxSettings struct {
+	Disabled  bool                `json:"disabled,omitempty" jsonschema:"description=If true run scripts with the full environment and HOME of the user,default=false"`
+	ReadOnly  bool                `json:"read_only,omitempty" jsonschema:"description=If true run scripts in a read-only view of the filesystem with bwrap when it is installed,default=false"`
+	Timeout   string              `json:"timeout,omitempty" jsonschema:"pattern=^([0-9]+(\\.[0-9]+)?(ns|us|ms|s|m|h))+$,default=1s,description=How long a script may run (e.g. 2s)"`
+	MaxOutput int                 `json:"max_output,omitempty" jsonschema:"minimum=0,default=1048576,description=Maximum output read from a script in bytes"`
+	Env       []string            `json:"env,omitempty" jsonschema:"description=Environment variables passed to all scripts in addition to PATH; locale and terminal variables"`
+	Tools     map[string][]string `json:"tools,omitempty" jsonschema:"description=Environment variables passed to the scripts of a tool (e.g. docker: [DOCKER_HOST]); HOME keeps the real home"`
+}
+
+// CompletionRegistry is a source of completion scripts
+type CompletionRegistry struct {
+	Name string `json:"name" jsonschema:"required,pattern=^[a-zA-Z0-9_-]+$,description=Name of the registry; default is the built-in registry"`
+	URL  string `json:"url,omitempty" jsonschema:"description=https:// URL; file:// URL or local directory containing v1/completion-scripts.yml (optional for the default registry)"`
+	TTL  string `json:"ttl,omitempty" jsonschema:"pattern=^([0-9]+(\\.[0-9]+)?(ns|us|ms|s|m|h))+$,default=168h,description=How long a downloaded registry is cached (e.g. 24h)"`
+}
+
+// CompletionPlugin declares an external completer
+type CompletionPlugin struct {
+	Name    string `json:"name" jsonschema:"required,pattern=^[a-zA-Z0-9_-]+$,description=Name of the completer"`
+	Path    string `json:"path,omitempty" jsonschema:"description=Executable of the completer; dirvana-completer-<name> on PATH if empty"`
+	Timeout string `json:"timeout,omitempty" jsonschema:"pattern=^([0-9]+(\\.[0-9]+)?(ns|us|ms|s|m|h))+$,default=1s,description=How long the completer may take to answer (e.g. 2s)"`
+}
+
+// CompletionCacheSettings configures the cache of completion results
+type CompletionCacheSettings struct {
+	Enabled bool              `json:"enabled,omitempty" jsonschema:"description=If true cache the completion results of all tools,default=false"`
+	TTL     string            `json:"ttl,omitempty" jsonschema:"pattern=^(0|([0-9]+(\\.[0-9]+)?(ns|us|ms|s|m|h))+)$,default=1m,description=Time to live of cached results (e.g. 30s; 5m); expired results are returned while refreshed in the background"`
+	Tools   map[string]string `json:"tools,omitempty" jsonschema:"description=Time to live per tool (e.g. helm: 5m); listed tools are cached even if enabled is false; 0 disables the cache"`
 }
 
 // AliasValue represents either a simple string command or a complex alias config
@@ -26,12 +74,53 @@ type AliasValue struct {
 	Complex *AliasConfig `json:"-"`
 }
 
+// FunctionValue represents either a function body or a function config
+type FunctionValue struct {
+	Simple  string          `json:"-"`
+	Complex *FunctionConfig `json:"-"`
+}
+
+// FunctionConfig represents a function with a completion setting
+type FunctionConfig struct {
+	Body       string           `json:"body" jsonschema:"required,minLength=1,description=Shell code of the function"`
+	Completion *CompletionValue `json:"completion,omitempty" jsonschema:"description=Completion configuration (optional): command to inherit from; false to disable; or custom sources"`
+}
+
 // AliasConfig represents an advanced alias with completion and conditions
 type AliasConfig struct {
-	Command    string           `json:"command" jsonschema:"required,minLength=1,description=Command to execute"`
-	Completion *CompletionValue `json:"completion,omitempty" jsonschema:"description=Completion configuration (optional)"`
-	When       *Condition       `json:"when,omitempty" jsonschema:"description=Conditions that must be met for the alias to execute"`
-	Else       string           `json:"else,omitempty" jsonschema:"description=Fallback command to execute if conditions are not met"`
+	Command    string            `json:"command" jsonschema:"required,minLength=1,description=Command to execute"`
+	Completion *CompletionValue  `json:"completion,omitempty" jsonschema:"description=Completion configuration (optional)"`
+	When       *Condition        `json:"when,omitempty" jsonschema:"description=Conditions that must be met for the alias to execute"`
+	Else       string            `json:"else,omitempty" jsonschema:"description=Fallback command to execute if conditions are not met"`
+	Exec       string            `json:"exec,omitempty" jsonschema:"enum=auto,enum=shell,enum=direct,default=auto,description=Execution mode: direct runs the binary without a shell; shell always uses the shell; auto uses direct when the command has no shell metacharacters"`
+	Env        map[string]string `json:"env,omitempty" jsonschema:"description=Environment variables set only for this command (not exported to the shell)"`
+	Cwd        string            `json:"cwd,omitempty" jsonschema:"description=Directory the command runs from (e.g. {{.DIRVANA_DIR}})"`
+	Args       *ArgsValue        `json:"args,omitempty" jsonschema:"description=Default arguments: a list inserted before the user's arguments or an object with prepend/append lists"`
+	Template   bool              `json:"template,omitempty" jsonschema:"description=If true the command is a template rendered at execution time with .Args .Env .GitBranch and .Cwd (arguments are not appended automatically),default=false"`
+	Params     []AliasParam      `json:"params,omitempty" jsonschema:"description=Typed positional parameters: validated by dirvana exec and used for --help and completion"`
+}
+
+// AliasParam describes a positional alias parameter
+type AliasParam struct {
+	Name        string   `json:"name" jsonschema:"required,minLength=1,description=Parameter name (shown in --help)"`
+	Type        string   `json:"type,omitempty" jsonschema:"enum=string,enum=enum,enum=file,enum=dir,enum=int,default=string,description=Parameter type"`
+	Values      []string `json:"values,omitempty" jsonschema:"description=Allowed values (required for enum parameters)"`
+	Pattern     string   `json:"pattern,omitempty" jsonschema:"description=Glob filtering completed files (e.g. *.yaml)"`
+	Required    bool     `json:"required,omitempty" jsonschema:"description=If true the parameter must be provided,default=false"`
+	Default     string   `json:"default,omitempty" jsonschema:"description=Value used when the parameter is omitted"`
+	Description string   `json:"description,omitempty" jsonschema:"description=Help text shown in --help and completion"`
+}
+
+// ArgsValue can be a list of arguments or an AliasArgs object
+type ArgsValue struct {
+	Prepend []string   `json:"-"`
+	Custom  *AliasArgs `json:"-"`
+}
+
+// AliasArgs for default alias arguments
+type AliasArgs struct {
+	Prepend []string `json:"prepend,omitempty" jsonschema:"description=Arguments inserted before the user's arguments"`
+	Append  []string `json:"append,omitempty" jsonschema:"description=Arguments added after the user's arguments"`
 }
 
 // CompletionValue can be string, bool, or CompletionConfig
@@ -43,8 +132,31 @@ type CompletionValue struct {
 
 // CompletionConfig for custom shell completion
 type CompletionConfig struct {
-	Bash string `json:"bash,omitempty" jsonschema:"description=Bash completion code"`
-	Zsh  string `json:"zsh,omitempty" jsonschema:"description=Zsh completion code"`
+	Values  []CompletionItemValue `json:"values,omitempty" jsonschema:"description=Static candidates: strings or objects with value and description"`
+	Command string                `json:"command,omitempty" jsonschema:"description=Shell command printing candidates one per line (value<TAB>description); receives the alias arguments as $@"`
+	Files   *FilesValue           `json:"files,omitempty" jsonschema:"description=Glob pattern(s) of files to complete (e.g. *.yaml); directories are always offered"`
+	Dirs    bool                  `json:"dirs,omitempty" jsonschema:"description=If true complete directories,default=false"`
+	Match   string                `json:"match,omitempty" jsonschema:"enum=prefix,enum=ignore-case,enum=substring,enum=fuzzy,description=How suggestions of this alias are matched against the word being completed (overrides the global completion.match)"`
+	Bash    string                `json:"bash,omitempty" jsonschema:"description=Deprecated and ignored: use values/command/files/dirs"`
+	Zsh     string                `json:"zsh,omitempty" jsonschema:"description=Deprecated and ignored: use values/command/files/dirs"`
+}
+
+// CompletionItemValue can be a string or a CompletionItem
+type CompletionItemValue struct {
+	Simple string          `json:"-"`
+	Item   *CompletionItem `json:"-"`
+}
+
+// CompletionItem is a static completion candidate with a description
+type CompletionItem struct {
+	Value       string `json:"value" jsonschema:"required,minLength=1,description=Candidate value"`
+	Description string `json:"description,omitempty" jsonschema:"description=Description shown next to the candidate"`
+}
+
+// FilesValue can be a single glob or a list of globs
+type FilesValue struct {
+	Single   string   `json:"-"`
+	Patterns []string `json:"-"`
 }
 
 // Condition represents conditions for alias execution
@@ -89,6 +201,22 @@ func (AliasValue) JSONSchema() *jsonschema.Schema {
 	}
 }
 
+// JSONSchema implements custom schema generation for FunctionValue
+func (FunctionValue) JSONSchema() *jsonschema.Schema {
+	return &jsonschema.Schema{
+		OneOf: []*jsonschema.Schema{
+			{
+				Type:        "string",
+				MinLength:   uint64Ptr(1),
+				Description: "Shell code of the function",
+			},
+			{
+				Ref: "#/$defs/FunctionConfig",
+			},
+		},
+	}
+}
+
 // JSONSchema implements custom schema generation for CompletionValue
 func (CompletionValue) JSONSchema() *jsonschema.Schema {
 	return &jsonschema.Schema{
@@ -109,6 +237,56 @@ func (CompletionValue) JSONSchema() *jsonschema.Schema {
 	}
 }
 
+// JSONSchema implements custom schema generation for CompletionItemValue
+func (CompletionItemValue) JSONSchema() *jsonschema.Schema {
+	return &jsonschema.Schema{
+		OneOf: []*jsonschema.Schema{
+			{
+				Type:        "string",
+				MinLength:   uint64Ptr(1),
+				Description: "Candidate value",
+			},
+			{
+				Ref: "#/$defs/CompletionItem",
+			},
+		},
+	}
+}
+
+// JSONSchema implements custom schema generation for FilesValue
+func (FilesValue) JSONSchema() *jsonschema.Schema {
+	return &jsonschema.Schema{
+		OneOf: []*jsonschema.Schema{
+			{
+				Type:        "string",
+				MinLength:   uint64Ptr(1),
+				Description: "Glob pattern of files to complete",
+			},
+			{
+				Type:        "array",
+				Items:       &jsonschema.Schema{Type: "string"},
+				Description: "Glob patterns of files to complete",
+			},
+		},
+	}
+}
+
+// JSONSchema implements custom schema generation for ArgsValue
+func (ArgsValue) JSONSchema() *jsonschema.Schema {
+	return &jsonschema.Schema{
+		OneOf: []*jsonschema.Schema{
+			{
+				Type:        "array",
+				Items:       &jsonschema.Schema{Type: "string"},
+				Description: "Arguments inserted before the user's arguments",
+			},
+			{
+				Ref: "#/$defs/AliasArgs",
+			},
+		},
+	}
+}
+
 // JSONSchema implements custom schema generation for EnvValue
 func (EnvValue) JSONSchema() *jsonschema.Schema {
 	return &jsonschema.Schema{
@@ -140,6 +318,9 @@ func main() {
 	completionConfigSchema := r.ReflectFromType(reflect.TypeOf(CompletionConfig{}))
 	conditionSchema := r.ReflectFromType(reflect.TypeOf(Condition{}))
 	envConfigSchema := r.ReflectFromType(reflect.TypeOf(EnvConfig{}))
+	aliasArgsSchema := r.ReflectFromType(reflect.TypeOf(AliasArgs{}))
+	completionItemSchema := r.ReflectFromType(reflect.TypeOf(CompletionItem{}))
+	functionConfigSchema := r.ReflectFromType(reflect.TypeOf(FunctionConfig{}))
 
 	// Get the actual definition from each schema's $defs
 	if def, ok := aliasConfigSchema.Definitions["AliasConfig"]; ok {
@@ -151,8 +332,9 @@ func main() {
 			}
 		}
 	}
-	if def, ok := completionConfigSchema.Definitions["CompletionConfig"]; ok {
-		schema.Definitions["CompletionConfig"] = def
+	// CompletionConfig and its nested defs (CompletionItemValue, FilesValue)
+	for k, v := range completionConfigSchema.Definitions {
+		schema.Definitions[k] = v
 	}
 	if def, ok := conditionSchema.Definitions["Condition"]; ok {
 		schema.Definitions["Condition"] = def
@@ -160,6 +342,15 @@ func main() {
 	if def, ok := envConfigSchema.Definitions["EnvConfig"]; ok {
 		schema.Definitions["EnvConfig"] = def
 	}
+	if def, ok := aliasArgsSchema.Definitions["AliasArgs"]; ok {
+		schema.Definitions["AliasArgs"] = def
+	}
+	if def, ok := completionItemSchema.Definitions["CompletionItem"]; ok {
+		schema.Definitions["CompletionItem"] = def
+	}
+	if def, ok := functionConfigSchema.Definitions["FunctionConfig"]; ok {
+		schema.Definitions["FunctionConfig"] = def
+	}
 
 	// Customize SchemaConfig to use patternProperties for aliases, functions, and env
 	if schemaConfig, ok := schema.Definitions["SchemaConfig"]; ok {
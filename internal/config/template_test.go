@@ -519,3 +519,47 @@ func TestExpandTemplate_WithStringFunctions(t *testing.T) {
 		})
 	}
 }
+
+func TestExpandAliasVars_WithExecOptions(t *testing.T) {
+	cfg := &Config{
+		ConfigDir: "/tmp/test/project",
+		Aliases: map[string]interface{}{
+			"tf": map[string]interface{}{
+				"command": "terraform",
+				"cwd":     "{{.DIRVANA_DIR}}/infra",
+				"env":     map[string]interface{}{"TF_DATA_DIR": "{{.DIRVANA_DIR}}/.terraform"},
+				"args": map[string]interface{}{
+					"append": []interface{}{"-var-file={{.DIRVANA_DIR}}/prod.tfvars"},
+				},
+			},
+		},
+	}
+
+	err := cfg.expandAliasVars()
+	require.NoError(t, err)
+
+	alias := cfg.GetAliases()["tf"]
+	assert.Equal(t, "/tmp/test/project/infra", alias.Cwd)
+	assert.Equal(t, "/tmp/test/project/.terraform", alias.Env["TF_DATA_DIR"])
+	assert.Equal(t, []string{"-var-file=/tmp/test/project/prod.tfvars"}, alias.Args.Append)
+}
+
+func TestExpandAliasVars_TemplateAliasNotExpanded(t *testing.T) {
+	cfg := &Config{
+		ConfigDir: "/tmp/test/project",
+		Aliases: map[string]interface{}{
+			"deploy": map[string]interface{}{
+				"command":  "helm upgrade {{.Args.0}} {{.DIRVANA_DIR}}/charts",
+				"template": true,
+			},
+		},
+	}
+
+	err := cfg.expandAliasVars()
+	require.NoError(t, err)
+
+	alias := cfg.GetAliases()["deploy"]
+	assert.True(t, alias.Template)
+	assert.Equal(t, "helm upgrade {{.Args.0}} {{.DIRVANA_DIR}}/charts", alias.Command)
+	assert.Equal(t, "/tmp/test/project", alias.ConfigDir)
+}
@@ -53,6 +53,44 @@ func Validate(path string) (*ValidationResult, error) {
 		}
 	}
 
+	// Validate alias execution modes, completion sources and parameters
+	for aliasName, alias := range cfg.GetAliases() {
+		if !IsValidExecMode(alias.Exec) {
+			result.Valid = false
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   "aliases/" + aliasName + "/exec",
+				Message: fmt.Sprintf("Invalid execution mode '%s' (expected auto, shell or direct)", alias.Exec),
+			})
+		}
+		if compCfg, ok := alias.Completion.(CompletionConfig); ok {
+			if err := compCfg.Validate(); err != nil {
+				result.Valid = false
+				result.Errors = append(result.Errors, ValidationError{
+					Field:   "aliases/" + aliasName + "/completion",
+					Message: err.Error(),
+				})
+			}
+		}
+		for i, param := range alias.Params {
+			if err := param.Validate(); err != nil {
+				result.Valid = false
+				result.Errors = append(result.Errors, ValidationError{
+					Field:   fmt.Sprintf("aliases/%s/params/%d", aliasName, i),
+					Message: err.Error(),
+				})
+			}
+		}
+	}
+
+	// Validate completion settings
+	if err := cfg.Completion.Validate(); err != nil {
+		result.Valid = false
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "completion",
+			Message: err.Error(),
+		})
+	}
+
 	// Validate environment variables
 	for name, value := range cfg.Env {
 		switch v := value.(type) {
@@ -112,5 +150,18 @@ func Validate(path string) (*ValidationResult, error) {
 		}
 	}
 
+	// Validate function completion sources
+	for name, comp := range cfg.FunctionCompletions {
+		if compCfg, ok := comp.(CompletionConfig); ok {
+			if err := compCfg.Validate(); err != nil {
+				result.Valid = false
+				result.Errors = append(result.Errors, ValidationError{
+					Field:   "functions/" + name + "/completion",
+					Message: err.Error(),
+				})
+			}
+		}
+	}
+
 	return result, nil
 }
@@ -155,3 +155,101 @@ env:
 	// Should have at least 3 errors: name conflict, empty alias, empty shell command
 	assert.GreaterOrEqual(t, len(result.Errors), 3)
 }
+
+func TestValidate_InvalidExecMode(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, ".dirvana.yml")
+
+	content := `aliases:
+  k:
+    command: kubectl
+    exec: direct
+  bad:
+    command: ls
+    exec: sometimes
+`
+	require.NoError(t, os.WriteFile(configPath, []byte(content), 0644))
+
+	result, err := Validate(configPath)
+	require.NoError(t, err)
+	assert.False(t, result.Valid)
+	require.Len(t, result.Errors, 1)
+	assert.Equal(t, "aliases/bad/exec", result.Errors[0].Field)
+}
+
+func TestValidate_InvalidParams(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, ".dirvana.yml")
+
+	content := `aliases:
+  deploy:
+    command: helm upgrade
+    params:
+      - name: env
+        type: enum
+`
+	require.NoError(t, os.WriteFile(configPath, []byte(content), 0644))
+
+	result, err := Validate(configPath)
+	require.NoError(t, err)
+	assert.False(t, result.Valid)
+	require.Len(t, result.Errors, 1)
+	assert.Equal(t, "aliases/deploy/params/0", result.Errors[0].Field)
+}
+
+func TestValidate_InvalidCompletionSource(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, ".dirvana.yml")
+
+	content := `aliases:
+  deploy:
+    command: ./deploy.sh
+    completion:
+      files: "[*.yml"
+`
+	require.NoError(t, os.WriteFile(configPath, []byte(content), 0644))
+
+	result, err := Validate(configPath)
+	require.NoError(t, err)
+	assert.False(t, result.Valid)
+	require.Len(t, result.Errors, 1)
+	assert.Equal(t, "aliases/deploy/completion", result.Errors[0].Field)
+}
+
+func TestValidate_InvalidFunctionCompletion(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, ".dirvana.yml")
+
+	content := `functions:
+  apply:
+    body: kubectl apply -f "$1"
+    completion:
+      files: "[*.yml"
+`
+	require.NoError(t, os.WriteFile(configPath, []byte(content), 0644))
+
+	result, err := Validate(configPath)
+	require.NoError(t, err)
+	assert.False(t, result.Valid)
+	require.Len(t, result.Errors, 1)
+	assert.Equal(t, "functions/apply/completion", result.Errors[0].Field)
+}
+
+func TestValidate_InvalidCompletionCacheTTL(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "global.yml")
+
+	content := `completion:
+  cache:
+    tools:
+      helm: 5 minutes
+`
+	require.NoError(t, os.WriteFile(configPath, []byte(content), 0644))
+
+	result, err := Validate(configPath)
+	require.NoError(t, err)
+	assert.False(t, result.Valid)
+	require.Len(t, result.Errors, 1)
+	assert.Equal(t, "completion", result.Errors[0].Field)
+	assert.Contains(t, result.Errors[0].Message, "helm")
+}
@@ -0,0 +1,174 @@
+package setup
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/NikitaCOEUR/dirvana/internal/cli"
+)
+
+// NuHookStrategy implements hook installation for Nushell
+// Nushell sources files at parse time: config.nu sources a hook file that must always exist
+type NuHookStrategy struct {
+	hookPath string
+	rcFile   string
+	message  string
+}
+
+// NewNuHookStrategy creates a new Nushell hook strategy
+func NewNuHookStrategy() (*NuHookStrategy, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get home directory: %w", err)
+	}
+
+	configDir := filepath.Join(home, ".config", "dirvana")
+	hookPath := filepath.Join(configDir, "hook.nu")
+
+	rcFile, err := GetRCFilePath(cli.ShellNu)
+	if err != nil {
+		return nil, err
+	}
+
+	return &NuHookStrategy{
+		hookPath: hookPath,
+		rcFile:   rcFile,
+	}, nil
+}
+
+// sourceLine returns the line of config.nu sourcing the hook file
+func (s *NuHookStrategy) sourceLine() string {
+	return fmt.Sprintf("source '%s'", s.hookPath)
+}
+
+// Install installs the hook for Nushell
+func (s *NuHookStrategy) Install() error {
+	// Step 1: Create hook file
+	hookCode := cli.GenerateHookCode(cli.ShellNu)
+
+	if err := os.MkdirAll(filepath.Dir(s.hookPath), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	if err := atomicWrite(s.hookPath, []byte(hookCode)); err != nil {
+		return fmt.Errorf("failed to create hook file: %w", err)
+	}
+
+	// Step 2: Add source line at the end of config.nu
+	data, err := os.ReadFile(s.rcFile)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	content := string(data)
+	if strings.Contains(content, s.hookPath) {
+		s.message = fmt.Sprintf("✓ Hook file updated at %s\n✓ Config file already configured", s.hookPath)
+		return nil
+	}
+
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	content += "\n# Dirvana\n" + s.sourceLine() + "\n"
+
+	if err := os.MkdirAll(filepath.Dir(s.rcFile), 0755); err != nil {
+		return fmt.Errorf("failed to create nushell config directory: %w", err)
+	}
+	if err := atomicWrite(s.rcFile, []byte(strings.TrimLeft(content, "\n"))); err != nil {
+		return fmt.Errorf("failed to update config file: %w", err)
+	}
+
+	s.message = fmt.Sprintf("✓ Hook created at %s\n✓ Added to %s", s.hookPath, s.rcFile)
+	return nil
+}
+
+// Uninstall removes the hook
+func (s *NuHookStrategy) Uninstall() error {
+	// Step 1: Remove lines from config file first: Nushell fails to start when a sourced
+	// file is missing
+	data, err := os.ReadFile(s.rcFile)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	if err == nil {
+		lines := strings.Split(string(data), "\n")
+		var newLines []string
+		skipNext := false
+
+		for _, line := range lines {
+			trimmed := strings.TrimSpace(line)
+
+			// Skip Dirvana comment
+			if trimmed == "# Dirvana" {
+				skipNext = true
+				continue
+			}
+
+			// Skip source line
+			if skipNext && strings.Contains(line, s.hookPath) {
+				skipNext = false
+				continue
+			}
+
+			skipNext = false
+			newLines = append(newLines, line)
+		}
+
+		newContent := strings.Join(newLines, "\n")
+		if err := atomicWrite(s.rcFile, []byte(newContent)); err != nil {
+			return fmt.Errorf("failed to update config file: %w", err)
+		}
+	}
+
+	// Step 2: Remove hook file
+	if err := os.Remove(s.hookPath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove hook file: %w", err)
+	}
+
+	s.message = fmt.Sprintf("✓ Removed hook file: %s\n✓ Removed line from %s", s.hookPath, s.rcFile)
+	return nil
+}
+
+// IsInstalled checks if the hook is installed
+func (s *NuHookStrategy) IsInstalled() bool {
+	// Check if hook file exists
+	if _, err := os.Stat(s.hookPath); os.IsNotExist(err) {
+		return false
+	}
+
+	// Check if config file references it
+	data, err := os.ReadFile(s.rcFile)
+	if err != nil {
+		return false
+	}
+
+	return strings.Contains(string(data), s.hookPath)
+}
+
+// NeedsUpdate checks if the hook needs to be updated
+func (s *NuHookStrategy) NeedsUpdate() bool {
+	// Check if hook file content matches current version
+	currentHook, err := os.ReadFile(s.hookPath)
+	if err != nil {
+		return true
+	}
+
+	expectedHook := cli.GenerateHookCode(cli.ShellNu)
+	return string(currentHook) != expectedHook
+}
+
+// GetMessage returns a user-friendly message
+func (s *NuHookStrategy) GetMessage() string {
+	if s.message == "" {
+		return MsgHookUpToDate
+	}
+	return s.message
+}
+
+// GetRCFile returns the config file path
+func (s *NuHookStrategy) GetRCFile() string {
+	return s.rcFile
+}
@@ -0,0 +1,139 @@
+package setup
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/NikitaCOEUR/dirvana/internal/cli"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNuHookStrategy_Install(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(tmpDir, ".config"))
+
+	strategy, err := NewNuHookStrategy()
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(tmpDir, ".config", "nushell", "config.nu"), strategy.GetRCFile())
+
+	require.NoError(t, strategy.Install())
+
+	// Hook file contains the Nushell hook
+	hook, err := os.ReadFile(strategy.hookPath)
+	require.NoError(t, err)
+	assert.Equal(t, cli.GenerateHookCode(cli.ShellNu), string(hook))
+
+	// config.nu was created and sources the hook file
+	content, err := os.ReadFile(strategy.rcFile)
+	require.NoError(t, err)
+	assert.Equal(t, "# Dirvana\nsource '"+strategy.hookPath+"'\n", string(content))
+
+	assert.True(t, strategy.IsInstalled())
+	assert.Contains(t, strategy.GetMessage(), "Added to")
+}
+
+func TestNuHookStrategy_PreservesExistingConfig(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(tmpDir, ".config"))
+
+	strategy, err := NewNuHookStrategy()
+	require.NoError(t, err)
+
+	existing := "$env.config.show_banner = false"
+	require.NoError(t, os.MkdirAll(filepath.Dir(strategy.rcFile), 0755))
+	require.NoError(t, os.WriteFile(strategy.rcFile, []byte(existing), 0644))
+
+	require.NoError(t, strategy.Install())
+
+	content, err := os.ReadFile(strategy.rcFile)
+	require.NoError(t, err)
+	contentStr := string(content)
+	assert.True(t, strings.HasPrefix(contentStr, existing+"\n\n# Dirvana\n"))
+	assert.Contains(t, contentStr, strategy.sourceLine())
+}
+
+func TestNuHookStrategy_IdempotentInstall(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(tmpDir, ".config"))
+
+	strategy, err := NewNuHookStrategy()
+	require.NoError(t, err)
+
+	require.NoError(t, strategy.Install())
+	require.NoError(t, strategy.Install())
+
+	content, err := os.ReadFile(strategy.rcFile)
+	require.NoError(t, err)
+	assert.Equal(t, 1, strings.Count(string(content), strategy.hookPath))
+	assert.Contains(t, strategy.GetMessage(), "already configured")
+}
+
+func TestNuHookStrategy_Uninstall(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(tmpDir, ".config"))
+
+	strategy, err := NewNuHookStrategy()
+	require.NoError(t, err)
+
+	existing := "$env.config.show_banner = false\n"
+	require.NoError(t, os.MkdirAll(filepath.Dir(strategy.rcFile), 0755))
+	require.NoError(t, os.WriteFile(strategy.rcFile, []byte(existing), 0644))
+
+	require.NoError(t, strategy.Install())
+	require.NoError(t, strategy.Uninstall())
+
+	assert.NoFileExists(t, strategy.hookPath)
+
+	content, err := os.ReadFile(strategy.rcFile)
+	require.NoError(t, err)
+	assert.NotContains(t, string(content), strategy.hookPath)
+	assert.NotContains(t, string(content), "# Dirvana")
+	assert.Contains(t, string(content), "show_banner")
+
+	assert.False(t, strategy.IsInstalled())
+}
+
+func TestNuHookStrategy_UninstallWithoutConfig(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(tmpDir, ".config"))
+
+	strategy, err := NewNuHookStrategy()
+	require.NoError(t, err)
+
+	require.NoError(t, strategy.Uninstall())
+	assert.NoFileExists(t, strategy.rcFile)
+}
+
+func TestNuHookStrategy_NeedsUpdate(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(tmpDir, ".config"))
+
+	strategy, err := NewNuHookStrategy()
+	require.NoError(t, err)
+
+	assert.True(t, strategy.NeedsUpdate())
+
+	require.NoError(t, strategy.Install())
+	assert.False(t, strategy.NeedsUpdate())
+
+	require.NoError(t, os.WriteFile(strategy.hookPath, []byte("old content"), 0644))
+	assert.True(t, strategy.NeedsUpdate())
+}
+
+func TestSelectInstallStrategy_Nu(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", "")
+
+	strategy, err := SelectInstallStrategy(cli.ShellNu)
+	require.NoError(t, err)
+	assert.IsType(t, &NuHookStrategy{}, strategy)
+}
@@ -37,11 +37,25 @@ func GetRCFilePath(shell string) (string, error) {
 		return filepath.Join(home, ".zshrc"), nil
 	case cli.ShellFish:
 		return filepath.Join(home, ".config/fish/config.fish"), nil
+	case cli.ShellNu:
+		return filepath.Join(nuConfigDir(home), "config.nu"), nil
 	default:
-		return "", fmt.Errorf("unsupported shell: %s (use bash, zsh, or fish)", shell)
+		return "", fmt.Errorf("unsupported shell: %s (use bash, zsh, fish, or nu)", shell)
 	}
 }
 
+// nuConfigDir returns the configuration directory of Nushell: under XDG_CONFIG_HOME when set,
+// else under the configuration directory of the platform (~/Library/Application Support on macOS)
+func nuConfigDir(home string) string {
+	if xdg := os.Getenv("XDG_CONFIG_HOME"); xdg != "" {
+		return filepath.Join(xdg, "nushell")
+	}
+	if dir, err := os.UserConfigDir(); err == nil {
+		return filepath.Join(dir, "nushell")
+	}
+	return filepath.Join(home, ".config", "nushell")
+}
+
 // checkDirenvConflict checks if direnv is installed and warns the user
 func checkDirenvConflict(rcFile string) string {
 	// Read RC file to check for direnv
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
 	"testing"
 
@@ -56,6 +57,35 @@ func TestGetRCFilePath(t *testing.T) {
 	}
 }
 
+func TestGetRCFilePath_Nu(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	t.Run("XDG_CONFIG_HOME", func(t *testing.T) {
+		t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, "xdg"))
+
+		got, err := GetRCFilePath("nu")
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(home, "xdg", "nushell", "config.nu"), got)
+	})
+
+	t.Run("platform default", func(t *testing.T) {
+		t.Setenv("XDG_CONFIG_HOME", "")
+
+		want := filepath.Join(home, ".config", "nushell", "config.nu")
+		switch runtime.GOOS {
+		case "darwin":
+			want = filepath.Join(home, "Library", "Application Support", "nushell", "config.nu")
+		case "windows":
+			t.Skip("configuration directory comes from %AppData%")
+		}
+
+		got, err := GetRCFilePath("nu")
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+	})
+}
+
 func TestInstallHook_NewInstallation(t *testing.T) {
 	tmpDir := t.TempDir()
 	rcFile := filepath.Join(tmpDir, ".bashrc")
@@ -36,6 +36,11 @@ func SelectInstallStrategy(shell string) (InstallStrategy, error) {
 		return NewFishHookStrategy()
 	}
 
+	// Nushell has no drop-in directory sourced at runtime
+	if shell == cli.ShellNu {
+		return NewNuHookStrategy()
+	}
+
 	// Try drop-in strategy first (cleanest approach)
 	dropIn, err := NewDropInStrategy(shell)
 	if err == nil && dropIn.IsSupported() {
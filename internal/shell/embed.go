@@ -20,6 +20,9 @@ var fishFunctionTemplate string
 //go:embed templates/completion/zsh_function.tmpl
 var zshFunctionTemplate string
 
+//go:embed templates/completion/nu.tmpl
+var nuTemplate string
+
 // Embedded shell hook templates
 //
 //go:embed templates/hook/bash.tmpl
@@ -30,3 +33,6 @@ var zshHookTemplate string
 
 //go:embed templates/hook/fish.tmpl
 var fishHookTemplate string
+
+//go:embed templates/hook/nu.tmpl
+var nuHookTemplate string
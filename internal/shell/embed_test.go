@@ -1,10 +1,15 @@
 package shell
 
 import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
 	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestBashTemplate_Embedded(t *testing.T) {
@@ -67,6 +72,11 @@ func TestZshFunctionTemplate_HasFallback(t *testing.T) {
 	assert.Contains(t, zshFunctionTemplate, "_files", "should have _files fallback")
 }
 
+func TestZshFunctionTemplate_DirectoriesWithoutSpace(t *testing.T) {
+	// Directory suggestions (trailing /) must not get a trailing space
+	assert.Contains(t, zshFunctionTemplate, "compadd -V 'directories' -S ''", "should add directories without suffix")
+}
+
 func TestBashTemplate_FormatsDescriptions(t *testing.T) {
 	// Bash template should format descriptions
 	assert.Contains(t, bashTemplate, "__dirvana_format_descriptions", "should have format function")
@@ -97,3 +107,120 @@ func TestTemplates_NoHardcodedAliases(t *testing.T) {
 	assert.Contains(t, bashTemplate, "complete -o nosort -F __dirvana_complete %s", "should use placeholder")
 	assert.Contains(t, zshTemplate, "compdef __dirvana_complete_zsh %s", "should use placeholder")
 }
+
+func TestZshFunctionTemplate_KeepsDirvanaMatches(t *testing.T) {
+	// Suggestions matched by dirvana (substring, fuzzy) must not be filtered again by zsh
+	assert.Contains(t, zshFunctionTemplate, "_describe -V 'completions' completions -U", "should not filter completions by prefix")
+	assert.Contains(t, zshFunctionTemplate, "-S '' -U --", "should not filter directories by prefix")
+}
+
+func TestNuTemplates_Embedded(t *testing.T) {
+	assert.Contains(t, nuTemplate, "$env.DIRVANA_COMPLETE = [%s]", "should list the completed aliases")
+	assert.Contains(t, nuHookTemplate, ":<directive>", "should document the directive line")
+	assert.Contains(t, nuHookTemplate, "bits and 4", "should skip the file fallback")
+}
+
+func TestTemplates_ParseDirective(t *testing.T) {
+	for name, template := range map[string]string{"bash": bashTemplate, "zsh": zshFunctionTemplate, "fish": fishFunctionTemplate} {
+		assert.Contains(t, template, ":<directive>", "%s should document the directive line", name)
+	}
+	assert.Contains(t, bashTemplate, "directive & 4", "bash should skip the file fallback")
+	assert.Contains(t, zshFunctionTemplate, "nospace=(-S '')", "zsh should not add a space")
+	assert.Contains(t, fishFunctionTemplate, "__fish_complete_path", "fish should fall back to files")
+	assert.Contains(t, fishTemplate, " -k", "fish should keep the order of dirvana")
+}
+
+func TestBashTemplate_Directives(t *testing.T) {
+	if _, err := exec.LookPath("bash"); err != nil {
+		t.Skip("bash not installed")
+	}
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "local-file"), []byte("x"), 0644))
+
+	// complete runs the completion function with a dirvana printing output
+	complete := func(output string) string {
+		bin := t.TempDir()
+		script := "#!/bin/bash\nprintf '" + output + "'\n"
+		require.NoError(t, os.WriteFile(filepath.Join(bin, "dirvana"), []byte(script), 0755))
+
+		code := fmt.Sprintf(bashTemplate, "mock") + `
+COMP_WORDS=(mock "")
+COMP_CWORD=1
+__dirvana_complete
+printf '%s\n' "${COMPREPLY[@]}"
+`
+		cmd := exec.Command("bash", "--norc", "--noprofile", "-c", code)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), "PATH="+bin+":"+os.Getenv("PATH"))
+		out, err := cmd.Output()
+		require.NoError(t, err)
+		return string(out)
+	}
+
+	assert.Equal(t, "zeta\nalpha\n", complete(`zeta\nalpha\n:36\n`), "the directive line is not a suggestion")
+	assert.Equal(t, "local-file\n", complete(``), "files without suggestions")
+	assert.Equal(t, "\n", complete(`:4\n`), "no file completion")
+	assert.Equal(t, "\n", complete(`apply\n:1\n`), "no completion on error")
+}
+
+func TestBashTemplate_KeepsTypedWord(t *testing.T) {
+	if _, err := exec.LookPath("bash"); err != nil {
+		t.Skip("bash not installed")
+	}
+
+	// complete runs the completion function of "mock <word>" with a dirvana printing output
+	complete := func(word, output string) []string {
+		bin := t.TempDir()
+		script := "#!/bin/bash\nprintf '" + output + "'\n"
+		require.NoError(t, os.WriteFile(filepath.Join(bin, "dirvana"), []byte(script), 0755))
+
+		code := fmt.Sprintf(bashTemplate, "mock") + `
+COMP_WORDS=(mock "` + word + `")
+COMP_CWORD=1
+__dirvana_complete
+printf '[%s]\n' "${COMPREPLY[@]}"
+`
+		cmd := exec.Command("bash", "--norc", "--noprofile", "-c", code)
+		cmd.Env = append(os.Environ(), "PATH="+bin+":"+os.Getenv("PATH"))
+		out, err := cmd.Output()
+		require.NoError(t, err)
+		return strings.Split(strings.TrimSpace(string(out)), "\n")
+	}
+
+	// Fuzzy matches without the typed word as prefix: an empty entry keeps the word
+	assert.Equal(t, []string{"[port-forward]", "[proxy]", "[]"}, complete("pf", `port-forward\nproxy\n`))
+
+	// Prefix matches are inserted by bash as usual
+	assert.Equal(t, []string{"[patch]", "[pause]"}, complete("pa", `patch\npause\n`))
+	assert.Equal(t, []string{"[port-forward]"}, complete("pf", `port-forward\n`))
+}
+
+func TestFishFunctionTemplate_DirectoriesWithoutSpace(t *testing.T) {
+	// A single directory suggestion (trailing /) must not get a trailing space
+	assert.Contains(t, fishFunctionTemplate, "string match -q -- '*/' $value", "should keep completing in directories")
+}
+
+func TestBashTemplate_CustomSources(t *testing.T) {
+	if _, err := exec.LookPath("bash"); err != nil {
+		t.Skip("bash not installed")
+	}
+
+	// Custom sources (values, command, files, dirs) are completed by 'dirvana completion':
+	// the template only renders their suggestions
+	bin := t.TempDir()
+	script := "#!/bin/bash\nprintf 'staging\\nproduction\\tLive\\nenvs/\\n'\n"
+	require.NoError(t, os.WriteFile(filepath.Join(bin, "dirvana"), []byte(script), 0755))
+
+	code := fmt.Sprintf(bashTemplate, "deploy") + `
+COMP_WORDS=(deploy "")
+COMP_CWORD=1
+__dirvana_complete
+printf '[%s]\n' "${COMPREPLY[@]}"
+`
+	cmd := exec.Command("bash", "--norc", "--noprofile", "-c", code)
+	cmd.Env = append(os.Environ(), "PATH="+bin+":"+os.Getenv("PATH"), "COLUMNS=80")
+	out, err := cmd.Output()
+	require.NoError(t, err)
+	assert.Equal(t, "[staging]\n[production  (Live)]\n[envs/]\n", string(out))
+}
@@ -11,6 +11,7 @@ const (
 	shellBash = "bash"
 	shellZsh  = "zsh"
 	shellFish = "fish"
+	shellNu   = "nu"
 )
 
 // CodeGenerator is an interface for shell-specific completion code generation
@@ -85,6 +86,25 @@ func (f *FishCodeGenerator) GenerateCompletionFunction(aliases []string) []strin
 	return lines
 }
 
+// NuCodeGenerator generates Nushell-specific shell completion code
+type NuCodeGenerator struct{}
+
+// Name returns the shell name for Nushell
+func (n *NuCodeGenerator) Name() string {
+	return shellNu
+}
+
+// GenerateCompletionFunction lists the aliases completed by the external completer that the
+// Nushell hook registers
+func (n *NuCodeGenerator) GenerateCompletionFunction(aliases []string) []string {
+	quoted := make([]string, len(aliases))
+	for i, alias := range aliases {
+		quoted[i] = nuQuote(alias)
+	}
+	script := fmt.Sprintf(nuTemplate, strings.Join(quoted, " "))
+	return strings.Split(script, "\n")
+}
+
 // MultiShellCodeGenerator generates completion code for multiple shells
 type MultiShellCodeGenerator struct {
 	generators []CodeGenerator
@@ -113,6 +133,8 @@ func NewCompletionGenerator(shell string) CodeGenerator {
 		return &ZshCodeGenerator{}
 	case shellFish:
 		return &FishCodeGenerator{}
+	case shellNu:
+		return &NuCodeGenerator{}
 	default:
 		// All shells
 		return &MultiShellCodeGenerator{
@@ -135,6 +157,8 @@ func GenerateHookCode(shell, binaryPath string) (string, error) {
 		tmpl = zshHookTemplate
 	case shellFish:
 		tmpl = fishHookTemplate
+	case shellNu:
+		tmpl = nuHookTemplate
 	default:
 		tmpl = bashHookTemplate // Default to bash
 	}
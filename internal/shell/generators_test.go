@@ -343,6 +343,34 @@ func TestFishCodeGenerator_GenerateCompletionFunction(t *testing.T) {
 	assert.Contains(t, script, "complete -c docker")
 }
 
+func TestNuCodeGenerator_GenerateCompletionFunction(t *testing.T) {
+	gen := NewCompletionGenerator("nu")
+	assert.Equal(t, "nu", gen.Name())
+
+	script := strings.Join(gen.GenerateCompletionFunction([]string{"k", "it's"}), "\n")
+	assert.Contains(t, script, "$env.DIRVANA_COMPLETE = ['k' r#'it's'#]")
+}
+
+func TestGenerateHookCode_Nu(t *testing.T) {
+	code, err := GenerateHookCode("nu", "/usr/local/bin/dirvana")
+	assert.NoError(t, err)
+
+	// env_change hook writing the code of export, then a string hook sourcing it
+	assert.Contains(t, code, "$env.config.hooks.env_change.PWD")
+	assert.Contains(t, code, "^/usr/local/bin/dirvana export --prev")
+	assert.Contains(t, code, `append $"source '($__dirvana_code)'"`)
+	assert.Contains(t, code, "$env.DIRVANA_SHELL = 'nu'")
+
+	// External completer chained with the one already set
+	assert.Contains(t, code, "$env.config.completions.external.completer = {|spans|")
+	assert.Contains(t, code, "^/usr/local/bin/dirvana completion -- ...$spans")
+	assert.Contains(t, code, "do $__dirvana_fallback $spans")
+
+	// No bash syntax
+	assert.NotContains(t, code, `eval "$shell_code"`)
+	assert.NotContains(t, code, "PROMPT_COMMAND")
+}
+
 func TestGenerateHookCode_Fish(t *testing.T) {
 	code, err := GenerateHookCode("fish", "dirvana")
 	assert.NoError(t, err)
@@ -11,7 +11,7 @@ import (
 
 // Generator generates shell code from configuration
 type Generator struct {
-	Shell string // Target shell: "bash", "zsh", or "" for both
+	Shell string // Target shell: "bash", "zsh", "fish", "nu", or "" for bash, zsh and fish
 }
 
 // NewGenerator creates a new shell code generator
@@ -39,6 +39,19 @@ func escapeValue(s string) string {
 	return strings.ReplaceAll(s, "'", "'\\''")
 }
 
+// nuQuote quotes a value for Nushell: a single-quoted string when possible, a raw string
+// (r#'...'#) otherwise, as single-quoted strings have no escapes
+func nuQuote(s string) string {
+	if !strings.Contains(s, "'") {
+		return "'" + s + "'"
+	}
+	hashes := "#"
+	for strings.Contains(s, "'"+hashes) {
+		hashes += "#"
+	}
+	return "r" + hashes + "'" + s + "'" + hashes
+}
+
 // indent adds indentation to multiline strings
 func indent(s string) string {
 	lines := strings.Split(s, "\n")
@@ -87,7 +100,15 @@ func (g *Generator) generateWithWrappers(aliases map[string]config.AliasConfig,
 
 	// Generate simple alias/function wrappers
 	if len(aliases) > 0 {
-		if useShellFunctions {
+		if g.Shell == shellNu {
+			// Nushell aliases of external commands are completed by the external completer
+			parts = append(parts, "\n# Aliases (using dirvana exec wrapper)")
+			keys := sortedKeysFromAliases(aliases)
+			for _, key := range keys {
+				parts = append(parts, fmt.Sprintf("alias %s = ^dirvana exec %s", key, key))
+				allNames = append(allNames, key)
+			}
+		} else if useShellFunctions {
 			if g.Shell == shellFish {
 				parts = append(parts, "\n# Aliases (using dirvana exec wrapper as functions)")
 				keys := sortedKeysFromAliases(aliases)
@@ -121,16 +142,26 @@ func (g *Generator) generateWithWrappers(aliases map[string]config.AliasConfig,
 	}
 
 	// Generate real shell functions
-	// Functions contain actual shell code and must be defined directly (not via dirvana exec)
+	// Functions contain actual shell code and must be defined directly (not via dirvana exec),
+	// except in Nushell which cannot run their POSIX shell body
 	if len(functions) > 0 {
 		parts = append(parts, "\n# Functions")
 		keys := sortedKeys(functions)
 		for _, key := range keys {
 			body := functions[key]
-			if g.Shell == shellFish {
+			// Functions are completed like aliases (through their completion setting),
+			// except in Nushell which only asks the external completer for external commands
+			if _, isAlias := aliases[key]; !isAlias && g.Shell != shellNu {
+				allNames = append(allNames, key)
+			}
+			switch g.Shell {
+			case shellFish:
 				// Fish syntax: function name; ...; end
 				parts = append(parts, fmt.Sprintf("function %s\n%s\nend", key, indent(body)))
-			} else {
+			case shellNu:
+				// Nushell runs the body through 'dirvana exec', with bash like alias commands
+				parts = append(parts, fmt.Sprintf("def --wrapped %s [...args] { ^dirvana exec %s ...$args }", key, key))
+			default:
 				// Bash/Zsh syntax: name() { ...; }
 				parts = append(parts, fmt.Sprintf("%s() {\n%s\n}", key, indent(body)))
 			}
@@ -143,10 +174,14 @@ func (g *Generator) generateWithWrappers(aliases map[string]config.AliasConfig,
 		keys := sortedKeys(staticEnv)
 		for _, key := range keys {
 			value := staticEnv[key]
-			if g.Shell == shellFish {
+			switch g.Shell {
+			case shellFish:
 				// Fish syntax: set -gx VAR value
 				parts = append(parts, fmt.Sprintf("set -gx %s '%s'", key, escapeValue(value)))
-			} else {
+			case shellNu:
+				// Nushell syntax: $env.VAR = 'value'
+				parts = append(parts, fmt.Sprintf("$env.%s = %s", key, nuQuote(value)))
+			default:
 				// Bash/Zsh syntax: export VAR=value
 				parts = append(parts, fmt.Sprintf("export %s='%s'", key, escapeValue(value)))
 			}
@@ -159,10 +194,14 @@ func (g *Generator) generateWithWrappers(aliases map[string]config.AliasConfig,
 		keys := sortedKeys(shellEnv)
 		for _, key := range keys {
 			shellCmd := shellEnv[key]
-			if g.Shell == shellFish {
+			switch g.Shell {
+			case shellFish:
 				// Fish syntax: set -gx VAR (command)
 				parts = append(parts, fmt.Sprintf("set -gx %s (%s)", key, shellCmd))
-			} else {
+			case shellNu:
+				// Commands are POSIX shell code: bash runs them, trailing newlines are trimmed like $(...)
+				parts = append(parts, fmt.Sprintf("$env.%s = (^bash -c %s | str trim --right --char (char nl))", key, nuQuote(shellCmd)))
+			default:
 				// Bash/Zsh syntax: export VAR="$(command)"
 				parts = append(parts, fmt.Sprintf("export %s=\"$(%s)\"", key, shellCmd))
 			}
@@ -178,3 +217,79 @@ func (g *Generator) generateWithWrappers(aliases map[string]config.AliasConfig,
 
 	return strings.Join(parts, "\n") + "\n"
 }
+
+// GenerateExportedFunctions creates bash code that defines aliases and functions as
+// exported shell functions. Unlike aliases, exported functions are inherited by child
+// bash processes (scripts, Makefile recipes), which is what non-interactive callers need.
+// Aliases become thin wrappers around "<binaryPath> exec <alias>".
+func GenerateExportedFunctions(aliases map[string]config.AliasConfig, functions map[string]string, binaryPath string) string {
+	var parts []string
+
+	for _, key := range sortedKeysFromAliases(aliases) {
+		// A function with the same name would redefine the wrapper anyway
+		if _, isFunction := functions[key]; isFunction {
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("%s() { '%s' exec %s \"$@\"; }", key, escapeValue(binaryPath), key))
+		parts = append(parts, "export -f "+key)
+	}
+
+	for _, key := range sortedKeys(functions) {
+		parts = append(parts, fmt.Sprintf("%s() {\n%s\n}", key, indent(functions[key])))
+		parts = append(parts, "export -f "+key)
+	}
+
+	if len(parts) == 0 {
+		return ""
+	}
+
+	return strings.Join(parts, "\n") + "\n"
+}
+
+// GenerateShimPathCode generates shell code that swaps the shim directory on PATH.
+// prevDir is removed from PATH (if set) and newDir is prepended (if set).
+// The active shim directory is tracked in the DIRVANA_SHIM_DIR variable.
+func GenerateShimPathCode(prevDir, newDir, shell string) string {
+	if prevDir == "" && newDir == "" {
+		return ""
+	}
+
+	var lines []string
+
+	switch shell {
+	case shellNu:
+		// PATH is a list in Nushell
+		if prevDir != "" {
+			lines = append(lines, fmt.Sprintf("$env.PATH = ($env.PATH | where $it != %s)", nuQuote(prevDir)))
+		}
+		if newDir != "" {
+			lines = append(lines, fmt.Sprintf("$env.PATH = ($env.PATH | prepend %s)", nuQuote(newDir)))
+			lines = append(lines, fmt.Sprintf("$env.DIRVANA_SHIM_DIR = %s", nuQuote(newDir)))
+		} else {
+			lines = append(lines, "hide-env -i DIRVANA_SHIM_DIR")
+		}
+	case shellFish:
+		if prevDir != "" {
+			lines = append(lines, fmt.Sprintf("if set -l idx (contains -i -- '%s' $PATH); set -e PATH[$idx]; end", escapeValue(prevDir)))
+		}
+		if newDir != "" {
+			lines = append(lines, fmt.Sprintf("set -gx PATH '%s' $PATH", escapeValue(newDir)))
+			lines = append(lines, fmt.Sprintf("set -gx DIRVANA_SHIM_DIR '%s'", escapeValue(newDir)))
+		} else {
+			lines = append(lines, "set -e DIRVANA_SHIM_DIR")
+		}
+	default:
+		if prevDir != "" {
+			// Quoted patterns are matched literally by both bash and zsh
+			lines = append(lines, fmt.Sprintf(`PATH=":${PATH}:"; PATH="${PATH//':%s:'/:}"; PATH="${PATH#:}"; PATH="${PATH%%:}"`, escapeValue(prevDir)))
+		}
+		if newDir != "" {
+			lines = append(lines, fmt.Sprintf(`export PATH='%s':"$PATH"`, escapeValue(newDir)))
+			lines = append(lines, fmt.Sprintf("export DIRVANA_SHIM_DIR='%s'", escapeValue(newDir)))
+		} else {
+			lines = append(lines, "unset DIRVANA_SHIM_DIR")
+		}
+	}
+
+	return "# Dirvana shims\n" + strings.Join(lines, "\n") + "\n"
+}
@@ -108,6 +108,20 @@ func TestGenerator_GenerateFunctionsOnly(t *testing.T) {
 	assert.Contains(t, code, "echo \"Hello\"")
 }
 
+func TestGenerator_RegistersFunctionCompletion(t *testing.T) {
+	aliases := map[string]config.AliasConfig{"gs": {Command: "git status"}}
+	functions := map[string]string{"greet": "echo \"Hello\""}
+
+	bash := NewGenerator().WithShell("bash").Generate(aliases, functions, nil, nil)
+	assert.Contains(t, bash, "complete -o nosort -F __dirvana_complete gs greet")
+
+	zsh := NewGenerator().WithShell("zsh").Generate(aliases, functions, nil, nil)
+	assert.Contains(t, zsh, "compdef __dirvana_complete_zsh greet")
+
+	fish := NewGenerator().WithShell("fish").Generate(aliases, functions, nil, nil)
+	assert.Contains(t, fish, "complete -c greet -f -a '(__dirvana_complete_fish)'")
+}
+
 func TestGenerator_GenerateEnvOnly(t *testing.T) {
 	g := NewGenerator()
 
@@ -257,3 +271,115 @@ func indexOf(s, substr string) int {
 	}
 	return -1
 }
+
+func TestGenerateExportedFunctions(t *testing.T) {
+	aliases := map[string]config.AliasConfig{
+		"ll":    {Command: "ls -la"},
+		"greet": {Command: "echo overridden"},
+	}
+	functions := map[string]string{
+		"greet": "echo \"Hello, $1!\"",
+	}
+
+	code := GenerateExportedFunctions(aliases, functions, "/usr/local/bin/dirvana")
+
+	assert.Contains(t, code, "ll() { '/usr/local/bin/dirvana' exec ll \"$@\"; }")
+	assert.Contains(t, code, "export -f ll")
+	assert.Contains(t, code, "greet() {\n  echo \"Hello, $1!\"\n}")
+	assert.Contains(t, code, "export -f greet")
+	assert.NotContains(t, code, "exec greet")
+}
+
+func TestGenerateExportedFunctions_Empty(t *testing.T) {
+	assert.Empty(t, GenerateExportedFunctions(nil, nil, "dirvana"))
+}
+
+func TestGenerateShimPathCode_Bash(t *testing.T) {
+	code := GenerateShimPathCode("/old/shims", "/new/shims", "bash")
+
+	assert.Contains(t, code, `PATH="${PATH//':/old/shims:'/:}"`)
+	assert.Contains(t, code, `export PATH='/new/shims':"$PATH"`)
+	assert.Contains(t, code, `export DIRVANA_SHIM_DIR='/new/shims'`)
+
+	// Removal only
+	code = GenerateShimPathCode("/old/shims", "", "zsh")
+	assert.Contains(t, code, "/old/shims")
+	assert.Contains(t, code, "unset DIRVANA_SHIM_DIR")
+	assert.NotContains(t, code, "export PATH")
+}
+
+func TestGenerateShimPathCode_Fish(t *testing.T) {
+	code := GenerateShimPathCode("/old/shims", "/new/shims", "fish")
+
+	assert.Contains(t, code, "contains -i -- '/old/shims' $PATH")
+	assert.Contains(t, code, "set -gx PATH '/new/shims' $PATH")
+	assert.Contains(t, code, "set -gx DIRVANA_SHIM_DIR '/new/shims'")
+
+	code = GenerateShimPathCode("/old/shims", "", "fish")
+	assert.Contains(t, code, "set -e DIRVANA_SHIM_DIR")
+}
+
+func TestGenerateShimPathCode_Nu(t *testing.T) {
+	code := GenerateShimPathCode("/old/shims", "/new/shims", "nu")
+
+	assert.Contains(t, code, "$env.PATH = ($env.PATH | where $it != '/old/shims')")
+	assert.Contains(t, code, "$env.PATH = ($env.PATH | prepend '/new/shims')")
+	assert.Contains(t, code, "$env.DIRVANA_SHIM_DIR = '/new/shims'")
+
+	code = GenerateShimPathCode("/old/shims", "", "nu")
+	assert.Contains(t, code, "hide-env -i DIRVANA_SHIM_DIR")
+	assert.NotContains(t, code, "prepend")
+}
+
+func TestGenerator_Nu(t *testing.T) {
+	gen := NewGenerator().WithShell("nu")
+	aliases := map[string]config.AliasConfig{
+		"k": {Command: "kubectl"},
+	}
+	functions := map[string]string{
+		"mkcd": `mkdir -p "$1" && cd "$1"`,
+	}
+	staticEnv := map[string]string{"GREETING": "it's fine", "PROJECT": "demo"}
+	shellEnv := map[string]string{"BRANCH": "git branch --show-current"}
+
+	code := gen.Generate(aliases, functions, staticEnv, shellEnv)
+
+	assert.Contains(t, code, "alias k = ^dirvana exec k")
+	// Function bodies are POSIX shell code, run by dirvana exec
+	assert.Contains(t, code, "def --wrapped mkcd [...args] { ^dirvana exec mkcd ...$args }")
+	assert.NotContains(t, code, "mkdir")
+	assert.Contains(t, code, "$env.PROJECT = 'demo'")
+	assert.Contains(t, code, "$env.GREETING = r#'it's fine'#")
+	assert.Contains(t, code, "$env.BRANCH = (^bash -c 'git branch --show-current' | str trim --right --char (char nl))")
+
+	// Only aliases are completed by the external completer
+	assert.Contains(t, code, "$env.DIRVANA_COMPLETE = ['k']")
+
+	// No bash, zsh or fish syntax
+	assert.NotContains(t, code, "export ")
+	assert.NotContains(t, code, "complete ")
+	assert.NotContains(t, code, "set -gx")
+}
+
+func TestNuQuote(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{value: "plain", want: "'plain'"},
+		{value: `C:\path "quoted"`, want: `'C:\path "quoted"'`},
+		{value: "it's", want: "r#'it's'#"},
+		{value: "a'#b", want: "r##'a'#b'##"},
+		{value: "", want: "''"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			assert.Equal(t, tt.want, nuQuote(tt.value))
+		})
+	}
+}
+
+func TestGenerateShimPathCode_Noop(t *testing.T) {
+	assert.Empty(t, GenerateShimPathCode("", "", "bash"))
+}
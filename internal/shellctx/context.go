@@ -8,6 +8,7 @@ import (
 
 const (
 	shellFish = "fish"
+	shellNu   = "nu"
 )
 
 // AuthChecker defines the interface for checking directory authorization
@@ -46,7 +47,7 @@ func ShouldCleanup(previousDir, currentDir string) bool {
 }
 
 // GenerateCleanupCode generates shell code to unset variables
-// shell parameter can be "bash", "zsh", "fish", or "" (generates for all shells)
+// shell parameter can be "bash", "zsh", "fish", "nu", or "" (generates for all shells)
 func GenerateCleanupCode(aliases []string, functions []string, envVars []string, shell string) string {
 	var lines []string
 
@@ -68,6 +69,13 @@ func generateAliasCleanup(aliases []string, shell string) []string {
 		return nil
 	}
 
+	// Nushell cannot remove a definition from a hook: the aliases stay defined, but are no
+	// longer completed, and 'dirvana exec' runs the command of the same name outside of
+	// their directory
+	if shell == shellNu {
+		return []string{"hide-env -i DIRVANA_COMPLETE"}
+	}
+
 	var lines []string
 	for _, alias := range aliases {
 		if shell == shellFish {
@@ -84,6 +92,12 @@ func generateAliasCleanup(aliases []string, shell string) []string {
 
 // generateFunctionCleanup generates shell commands to unset functions
 func generateFunctionCleanup(functions []string, shell string) []string {
+	// Nushell cannot remove a definition from a hook (see generateAliasCleanup): functions
+	// call 'dirvana exec', which runs the command of the same name outside of their directory
+	if shell == shellNu {
+		return nil
+	}
+
 	var lines []string
 	for _, fn := range functions {
 		if shell == shellFish {
@@ -101,10 +115,14 @@ func generateFunctionCleanup(functions []string, shell string) []string {
 func generateEnvCleanup(envVars []string, shell string) []string {
 	var lines []string
 	for _, env := range envVars {
-		if shell == shellFish {
+		switch shell {
+		case shellFish:
 			// Fish uses 'set -e' to unset variables
 			lines = append(lines, "set -e "+env)
-		} else {
+		case shellNu:
+			// Nushell uses 'hide-env', -i ignores variables already removed
+			lines = append(lines, "hide-env -i "+env)
+		default:
 			// Bash/Zsh use 'unset'
 			lines = append(lines, "unset "+env)
 		}
@@ -123,6 +123,22 @@ func TestGenerateCleanupCode(t *testing.T) {
 		// Should have fish error handling
 		assert.Contains(t, code, "2>/dev/null; or true")
 	})
+
+	t.Run("nu", func(t *testing.T) {
+		code := GenerateCleanupCode(aliases, functions, envVars, "nu")
+
+		// Definitions cannot be removed: aliases are no longer completed, and dirvana exec
+		// runs the command of the same name outside of their directory
+		assert.Contains(t, code, "hide-env -i DIRVANA_COMPLETE")
+		assert.NotContains(t, code, "unalias")
+		assert.NotContains(t, code, "unset -f")
+
+		// Check env vars are hidden with hide-env
+		assert.Contains(t, code, "hide-env -i PROJECT_NAME")
+		assert.Contains(t, code, "hide-env -i DEBUG")
+		assert.Contains(t, code, "hide-env -i GIT_BRANCH")
+		assert.NotContains(t, code, "unset")
+	})
 }
 
 func TestGenerateCleanupCode_Empty(t *testing.T) {
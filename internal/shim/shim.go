@@ -0,0 +1,134 @@
+// Package shim manages per-project directories of executable alias shims.
+// Shims are tiny scripts dispatching to 'dirvana exec', which makes aliases
+// usable from non-interactive shells, xargs, Makefiles and IDE terminals.
+package shim
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	// DirEnvVar holds the shim directory currently on PATH (set by the generated shell code)
+	DirEnvVar = "DIRVANA_SHIM_DIR"
+	// ContextDirEnvVar tells 'dirvana exec' which directory to resolve aliases from
+	ContextDirEnvVar = "DIRVANA_CONTEXT_DIR"
+	// BypassEnvVar lists the aliases (separated by ':') whose shims run the real command:
+	// set by 'dirvana exec' while the command of an alias runs
+	BypassEnvVar = "DIRVANA_SHIM_BYPASS"
+
+	// stampFile records what the shims were generated from, to detect when to regenerate
+	stampFile = ".dirvana-shims"
+	// shimVersion changes with the content of the shims, to regenerate them
+	shimVersion = "2"
+)
+
+// validName matches alias names that are safe to use as file names
+var validName = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_.-]*$`)
+
+// RootDir returns the directory containing all shim directories
+func RootDir(cacheDir string) string {
+	return filepath.Join(cacheDir, "shims")
+}
+
+// Dir returns the shim directory for a project directory
+func Dir(cacheDir, projectDir string) string {
+	hash := sha256.Sum256([]byte(filepath.Clean(projectDir)))
+	return filepath.Join(RootDir(cacheDir), hex.EncodeToString(hash[:])[:16])
+}
+
+// Ensure makes sure the shim directory contains exactly one shim per alias.
+// Shims are regenerated only when the hierarchy hash or the dirvana binary changed.
+// Returns true if the shims were (re)generated.
+func Ensure(shimDir, projectDir, hierarchyHash, binaryPath string, aliases []string) (bool, error) {
+	stamp := buildStamp(hierarchyHash, binaryPath, aliases)
+
+	if data, err := os.ReadFile(filepath.Join(shimDir, stampFile)); err == nil && string(data) == stamp {
+		return false, nil
+	}
+
+	// Start from an empty directory so removed aliases don't leave stale shims
+	if err := os.RemoveAll(shimDir); err != nil {
+		return false, fmt.Errorf("failed to remove old shims: %w", err)
+	}
+	if err := os.MkdirAll(shimDir, 0755); err != nil {
+		return false, fmt.Errorf("failed to create shim directory: %w", err)
+	}
+
+	for _, alias := range aliases {
+		if !validName.MatchString(alias) {
+			continue
+		}
+		content := generateShim(shimDir, projectDir, binaryPath, alias)
+		if err := os.WriteFile(filepath.Join(shimDir, alias), []byte(content), 0755); err != nil {
+			return false, fmt.Errorf("failed to write shim for %s: %w", alias, err)
+		}
+	}
+
+	if err := os.WriteFile(filepath.Join(shimDir, stampFile), []byte(stamp), 0644); err != nil {
+		return false, fmt.Errorf("failed to write shim stamp: %w", err)
+	}
+
+	return true, nil
+}
+
+// RemoveAll deletes every shim directory
+func RemoveAll(cacheDir string) error {
+	return os.RemoveAll(RootDir(cacheDir))
+}
+
+// AddBypass adds an alias to a list of bypassed aliases (the value of BypassEnvVar)
+func AddBypass(list, alias string) string {
+	for _, name := range strings.Split(list, ":") {
+		if name == alias {
+			return list
+		}
+	}
+	if list == "" {
+		return alias
+	}
+	return list + ":" + alias
+}
+
+// generateShim returns the script content for an alias shim.
+// The project directory is passed along so the alias resolves the same way
+// regardless of the directory the shim is invoked from. While the command of the alias
+// runs, the shim runs the next executable of the same name in PATH instead: an alias
+// with the name of its own command (ls: ls -1) would otherwise call itself forever.
+func generateShim(shimDir, projectDir, binaryPath, alias string) string {
+	return fmt.Sprintf(`#!/bin/sh
+# Generated by Dirvana - Do not edit manually
+case ":$%[1]s:" in
+*:%[2]s:*)
+  set -f; IFS=:
+  for dir in $PATH; do
+    if [ "$dir" != %[3]s ] && [ -f "${dir:-.}/"%[2]s ] && [ -x "${dir:-.}/"%[2]s ]; then
+      unset IFS; set +f
+      exec "${dir:-.}/"%[2]s "$@"
+    fi
+  done
+  echo %[2]s": command not found" >&2
+  exit 127
+  ;;
+esac
+%[4]s=%[5]s exec %[6]s exec %[2]s "$@"
+`, BypassEnvVar, quote(alias), quote(shimDir), ContextDirEnvVar, quote(projectDir), quote(binaryPath))
+}
+
+// buildStamp builds the content of the stamp file
+func buildStamp(hierarchyHash, binaryPath string, aliases []string) string {
+	sorted := append([]string(nil), aliases...)
+	sort.Strings(sorted)
+	return strings.Join([]string{shimVersion, hierarchyHash, binaryPath, strings.Join(sorted, " ")}, "\n")
+}
+
+// quote single-quotes a string for POSIX sh
+func quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "'\\''") + "'"
+}
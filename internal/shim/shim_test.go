@@ -0,0 +1,144 @@
+package shim
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDir(t *testing.T) {
+	cacheDir := "/tmp/cache"
+
+	dirA := Dir(cacheDir, "/projects/a")
+	dirB := Dir(cacheDir, "/projects/b")
+
+	assert.Equal(t, dirA, Dir(cacheDir, "/projects/a/"), "Dir should be stable for the same project")
+	assert.NotEqual(t, dirA, dirB)
+	assert.Equal(t, RootDir(cacheDir), filepath.Dir(dirA))
+}
+
+func TestEnsure(t *testing.T) {
+	shimDir := filepath.Join(t.TempDir(), "shims", "project")
+
+	generated, err := Ensure(shimDir, "/projects/a", "hash1", "/usr/bin/dirvana", []string{"k", "tf"})
+	require.NoError(t, err)
+	assert.True(t, generated)
+
+	content, err := os.ReadFile(filepath.Join(shimDir, "k"))
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "#!/bin/sh")
+	assert.Contains(t, string(content), `DIRVANA_CONTEXT_DIR='/projects/a' exec '/usr/bin/dirvana' exec 'k' "$@"`)
+
+	info, err := os.Stat(filepath.Join(shimDir, "tf"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0755), info.Mode().Perm())
+
+	// Same inputs: nothing to do
+	generated, err = Ensure(shimDir, "/projects/a", "hash1", "/usr/bin/dirvana", []string{"tf", "k"})
+	require.NoError(t, err)
+	assert.False(t, generated)
+}
+
+func TestEnsure_RegeneratesOnHashChange(t *testing.T) {
+	shimDir := filepath.Join(t.TempDir(), "project")
+
+	_, err := Ensure(shimDir, "/projects/a", "hash1", "dirvana", []string{"k", "old"})
+	require.NoError(t, err)
+
+	generated, err := Ensure(shimDir, "/projects/a", "hash2", "dirvana", []string{"k"})
+	require.NoError(t, err)
+	assert.True(t, generated)
+
+	assert.FileExists(t, filepath.Join(shimDir, "k"))
+	assert.NoFileExists(t, filepath.Join(shimDir, "old"), "removed aliases should not leave stale shims")
+}
+
+func TestEnsure_SkipsInvalidNames(t *testing.T) {
+	shimDir := filepath.Join(t.TempDir(), "project")
+
+	_, err := Ensure(shimDir, "/projects/a", "hash", "dirvana", []string{"ok", "../escape", "with space"})
+	require.NoError(t, err)
+
+	entries, err := os.ReadDir(shimDir)
+	require.NoError(t, err)
+
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	assert.ElementsMatch(t, []string{"ok", stampFile}, names)
+}
+
+func TestEnsure_QuotesPaths(t *testing.T) {
+	shimDir := filepath.Join(t.TempDir(), "project")
+
+	_, err := Ensure(shimDir, "/projects/it's", "hash", "dirvana", []string{"k"})
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(filepath.Join(shimDir, "k"))
+	require.NoError(t, err)
+	assert.Contains(t, string(content), `'/projects/it'\''s'`)
+}
+
+func TestRemoveAll(t *testing.T) {
+	cacheDir := t.TempDir()
+	shimDir := Dir(cacheDir, "/projects/a")
+
+	_, err := Ensure(shimDir, "/projects/a", "hash", "dirvana", []string{"k"})
+	require.NoError(t, err)
+
+	require.NoError(t, RemoveAll(cacheDir))
+	assert.NoDirExists(t, RootDir(cacheDir))
+}
+
+func TestAddBypass(t *testing.T) {
+	assert.Equal(t, "ls", AddBypass("", "ls"))
+	assert.Equal(t, "ls:make", AddBypass("ls", "make"))
+	assert.Equal(t, "ls:make", AddBypass("ls:make", "ls"))
+}
+
+func TestShim_Bypass(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not installed")
+	}
+
+	tmpDir := t.TempDir()
+	shimDir := filepath.Join(tmpDir, "shims")
+	realDir := filepath.Join(tmpDir, "bin")
+	require.NoError(t, os.MkdirAll(realDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(realDir, "mytool"), []byte("#!/bin/sh\necho real \"$@\"\n"), 0755))
+	dirvana := filepath.Join(tmpDir, "dirvana")
+	require.NoError(t, os.WriteFile(dirvana, []byte("#!/bin/sh\necho dirvana \"$@\"\n"), 0755))
+
+	_, err := Ensure(shimDir, "/projects/a", "hash", dirvana, []string{"mytool", "other", "missing"})
+	require.NoError(t, err)
+	t.Setenv("PATH", shimDir+string(os.PathListSeparator)+realDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	run := func(command string) (string, error) {
+		out, err := exec.Command("sh", "-c", command).Output()
+		return strings.TrimSpace(string(out)), err
+	}
+
+	out, err := run("mytool a")
+	require.NoError(t, err)
+	assert.Equal(t, "dirvana exec mytool a", out)
+
+	// While the command of the alias runs, its shim runs the real command; the others don't
+	t.Setenv(BypassEnvVar, "missing:mytool")
+	out, err = run("mytool a")
+	require.NoError(t, err)
+	assert.Equal(t, "real a", out)
+	out, err = run("other b")
+	require.NoError(t, err)
+	assert.Equal(t, "dirvana exec other b", out)
+
+	_, err = run("missing")
+	var exitErr *exec.ExitError
+	require.ErrorAs(t, err, &exitErr)
+	assert.Equal(t, 127, exitErr.ExitCode())
+}
@@ -219,13 +219,27 @@ func collectCompletionInfo(data *Data) {
 		}
 	}
 
+	// Get result cache info and statistics from completion module
+	if resultsInfo, err := completion.GetResultCacheInfo(cacheDir); err == nil && resultsInfo != nil {
+		data.CompletionResults = &CompletionResultsInfo{
+			Path:      resultsInfo.Path,
+			Size:      resultsInfo.Size,
+			Entries:   resultsInfo.Entries,
+			Hits:      resultsInfo.Stats.Hits,
+			StaleHits: resultsInfo.Stats.StaleHits,
+			Misses:    resultsInfo.Stats.Misses,
+		}
+	}
+
 	// Get downloaded scripts from completion module
 	if scripts, err := completion.GetDownloadedScripts(cacheDir); err == nil && scripts != nil {
 		for _, script := range scripts {
 			data.CompletionScripts = append(data.CompletionScripts, CompletionScriptInfo{
-				Tool: script.Tool,
-				Path: script.Path,
-				Size: script.Size,
+				Tool:   script.Tool,
+				Shell:  script.Shell,
+				Path:   script.Path,
+				Size:   script.Size,
+				Status: string(script.Status),
 			})
 		}
 	}
@@ -277,6 +277,11 @@ func TestCollectAll_WithCompletion(t *testing.T) {
 	err = os.WriteFile(registryPath, []byte(registryContent), 0644)
 	require.NoError(t, err)
 
+	// Create result cache with statistics
+	resultsPath := filepath.Join(cacheDir, "completion-results.json")
+	err = os.WriteFile(resultsPath, []byte(`{"entries":{"helm\u001f/":{"suggestions":[{"value":"install"}],"source":"Cobra","timestamp":"2030-01-01T00:00:00Z"}},"stats":{"hits":4,"stale_hits":1,"misses":2}}`), 0644)
+	require.NoError(t, err)
+
 	// Create downloaded scripts in proper directory structure
 	scriptsDir := filepath.Join(cacheDir, "completion-scripts", "bash")
 	err = os.MkdirAll(scriptsDir, 0755)
@@ -313,6 +318,14 @@ func TestCollectAll_WithCompletion(t *testing.T) {
 	assert.Greater(t, data.CompletionRegistry.Size, int64(0))
 	assert.Equal(t, 2, data.CompletionRegistry.ToolsCount)
 
+	// Completion result cache
+	require.NotNil(t, data.CompletionResults)
+	assert.Equal(t, resultsPath, data.CompletionResults.Path)
+	assert.Equal(t, 1, data.CompletionResults.Entries)
+	assert.Equal(t, int64(4), data.CompletionResults.Hits)
+	assert.Equal(t, int64(1), data.CompletionResults.StaleHits)
+	assert.Equal(t, int64(2), data.CompletionResults.Misses)
+
 	// Downloaded scripts
 	assert.Len(t, data.CompletionScripts, 2)
 	scriptNames := []string{data.CompletionScripts[0].Tool, data.CompletionScripts[1].Tool}
@@ -44,6 +44,7 @@ type Data struct {
 	// Completion
 	CompletionDetection *CompletionDetectionInfo
 	CompletionRegistry  *CompletionRegistryInfo
+	CompletionResults   *CompletionResultsInfo
 	CompletionScripts   []CompletionScriptInfo
 	CompletionOverrides map[string]string // alias -> command
 }
@@ -62,10 +63,22 @@ type CompletionRegistryInfo struct {
 	ToolsCount int
 }
 
+// CompletionResultsInfo contains result cache information and statistics
+type CompletionResultsInfo struct {
+	Path      string
+	Size      int64
+	Entries   int
+	Hits      int64
+	StaleHits int64
+	Misses    int64
+}
+
 // CompletionScriptInfo contains information about a downloaded script
 type CompletionScriptInfo struct {
-	Tool string
-	Path string
-	Size int64
+	Tool   string
+	Shell  string // bash, or zsh and fish for native scripts
+	Path   string
+	Size   int64
+	Status string // Integrity compared to the script lockfile: intact, modified or unrecorded
 }
 
@@ -2,6 +2,7 @@ package status
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/charmbracelet/lipgloss"
@@ -314,8 +315,19 @@ func renderCompletionInfo(data *Data) string {
 				sourceGroups[source] = append(sourceGroups[source], cmd)
 			}
 
+			// Built-in completers first, then external completers (Plugin:<name>)
+			sources := []string{"Cobra", "Flag", "Clap", "Env", "Argcomplete", "Click", "Yargs", "Tabtab", "Oclif", "Script", "Help"}
+			var plugins []string
+			for source := range sourceGroups {
+				if strings.HasPrefix(source, "Plugin:") {
+					plugins = append(plugins, source)
+				}
+			}
+			sort.Strings(plugins)
+			sources = append(sources, plugins...)
+
 			b.WriteString("   " + keyStyle.Render("Sources:") + "\n")
-			for _, source := range []string{"Cobra", "Flag", "Env", "Script"} {
+			for _, source := range sources {
 				if cmds, ok := sourceGroups[source]; ok {
 					b.WriteString(fmt.Sprintf("      %s (%s): %s\n",
 						keyStyle.Render(source),
@@ -338,13 +350,39 @@ func renderCompletionInfo(data *Data) string {
 		}
 	}
 
+	// Result cache statistics
+	if data.CompletionResults != nil {
+		results := data.CompletionResults
+		b.WriteString("\n   " + keyStyle.Render("Result cache:") + "\n")
+		b.WriteString("      " + keyStyle.Render("Path: ") + subtleStyle.Render(results.Path) + "\n")
+		b.WriteString("      " + keyStyle.Render("Size: ") + valueStyle.Render(formatBytes(results.Size)) + "\n")
+		b.WriteString("      " + keyStyle.Render("Entries: ") + valueStyle.Render(fmt.Sprintf("%d", results.Entries)) + "\n")
+		lookups := results.Hits + results.StaleHits + results.Misses
+		if lookups > 0 {
+			b.WriteString("      " + keyStyle.Render("Lookups: ") + valueStyle.Render(fmt.Sprintf("%d", lookups)) +
+				subtleStyle.Render(fmt.Sprintf(" (%d hits, %d stale, %d misses, %.0f%% served from cache)",
+					results.Hits, results.StaleHits, results.Misses,
+					float64(results.Hits+results.StaleHits)*100/float64(lookups))) + "\n")
+		}
+	}
+
 	// Downloaded scripts
 	if len(data.CompletionScripts) > 0 {
 		b.WriteString("\n   " + keyStyle.Render("Downloaded scripts:") + "\n")
 		for _, script := range data.CompletionScripts {
-			b.WriteString(fmt.Sprintf("      %s (%s)\n",
+			line := fmt.Sprintf("      %s (%s)",
 				valueStyle.Render(script.Tool),
-				subtleStyle.Render(formatBytes(script.Size))))
+				subtleStyle.Render(formatBytes(script.Size)))
+			if script.Shell != "" && script.Shell != "bash" {
+				line += " " + subtleStyle.Render(script.Shell)
+			}
+			switch script.Status {
+			case "modified":
+				line += " " + warningStyle.Render("⚠ modified since installed")
+			case "unrecorded":
+				line += " " + subtleStyle.Render("not in lockfile")
+			}
+			b.WriteString(line + "\n")
 		}
 	}
 
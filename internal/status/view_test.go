@@ -381,10 +381,11 @@ func TestRender_WithCompletion(t *testing.T) {
 			Path: "/test/detection.json",
 			Size: 1024,
 			Commands: map[string]string{
-				"kubectl": "Cobra",
-				"helm":    "Cobra",
-				"go":      "Flag",
-				"custom":  "Script",
+				"kubectl":   "Cobra",
+				"helm":      "Cobra",
+				"go":        "Flag",
+				"custom":    "Script",
+				"deployctl": "Plugin:corp",
 			},
 		},
 		CompletionRegistry: &CompletionRegistryInfo{
@@ -392,9 +393,19 @@ func TestRender_WithCompletion(t *testing.T) {
 			Size:       2048,
 			ToolsCount: 10,
 		},
+		CompletionResults: &CompletionResultsInfo{
+			Path:      "/test/results.json",
+			Size:      512,
+			Entries:   3,
+			Hits:      6,
+			StaleHits: 2,
+			Misses:    2,
+		},
 		CompletionScripts: []CompletionScriptInfo{
-			{Tool: "kubectl", Path: "/test/scripts/kubectl.sh", Size: 4096},
-			{Tool: "helm", Path: "/test/scripts/helm.sh", Size: 3072},
+			{Tool: "kubectl", Path: "/test/scripts/kubectl.sh", Size: 4096, Status: "intact"},
+			{Tool: "helm", Path: "/test/scripts/helm.sh", Size: 3072, Status: "modified"},
+			{Tool: "govc", Path: "/test/scripts/govc.sh", Size: 1024, Status: "unrecorded"},
+			{Tool: "terraform", Shell: "fish", Path: "/test/scripts/fish/terraform", Size: 2048, Status: "intact"},
 		},
 		CompletionOverrides: map[string]string{
 			"k": "kubectl",
@@ -428,6 +439,8 @@ func TestRender_WithCompletion(t *testing.T) {
 	assert.Contains(t, output, "go")
 	assert.Contains(t, output, "Script")
 	assert.Contains(t, output, "custom")
+	assert.Contains(t, output, "Plugin:corp")
+	assert.Contains(t, output, "deployctl")
 
 	// Registry
 	assert.Contains(t, output, "Registry:")
@@ -436,12 +449,23 @@ func TestRender_WithCompletion(t *testing.T) {
 	assert.Contains(t, output, "Tools available:")
 	assert.Contains(t, output, "10")
 
+	// Result cache
+	assert.Contains(t, output, "Result cache:")
+	assert.Contains(t, output, "/test/results.json")
+	assert.Contains(t, output, "Entries:")
+	assert.Contains(t, output, "6 hits, 2 stale, 2 misses, 80% served from cache")
+
 	// Downloaded scripts
 	assert.Contains(t, output, "Downloaded scripts:")
 	assert.Contains(t, output, "kubectl")
 	assert.Contains(t, output, "4.0 KB")
 	assert.Contains(t, output, "helm")
 	assert.Contains(t, output, "3.0 KB")
+	assert.Contains(t, output, "⚠ modified since installed")
+	assert.Contains(t, output, "not in lockfile")
+	assert.Equal(t, 1, strings.Count(output, "modified since installed"))
+	assert.Contains(t, output, "terraform")
+	assert.Contains(t, output, "fish")
 
 	// Completion overrides
 	assert.Contains(t, output, "Completion overrides:")